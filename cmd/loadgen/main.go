@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/loadgen"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	_ = godotenv.Load()
+}
+
+func main() {
+	var (
+		url       = flag.String("url", "http://localhost:8080", "API base URL")
+		token     = flag.String("token", "", "bearer token (env LOADGEN_TOKEN)")
+		start     = flag.Int("start", 1, "devices at the beginning of the ramp")
+		devices   = flag.Int("devices", 100, "devices at the end of the ramp")
+		ramp      = flag.Duration("ramp", 30*time.Second, "time to ramp from -start to -devices")
+		duration  = flag.Duration("duration", time.Minute, "total run time, ramp included")
+		interval  = flag.Duration("interval", time.Second, "time between fixes of one device")
+		queriers  = flag.Int("queriers", 4, "concurrent status/trips query workers")
+		qInterval = flag.Duration("query-interval", 100*time.Millisecond, "time between queries of one worker")
+		seed      = flag.Int64("seed", 1, "random seed for vehicle IDs and positions")
+		out       = flag.String("out", "", "write the JSON report here instead of stdout")
+		version   = flag.String("version", "", "release label recorded in the report")
+	)
+	flag.Parse()
+	if *token == "" {
+		*token = os.Getenv("LOADGEN_TOKEN")
+	}
+	if *token == "" {
+		log.Fatal("need -token or LOADGEN_TOKEN")
+	}
+
+	runner, err := loadgen.New(loadgen.Config{
+		BaseURL:       *url,
+		Token:         *token,
+		StartDevices:  *start,
+		Devices:       *devices,
+		Ramp:          *ramp,
+		Duration:      *duration,
+		FixInterval:   *interval,
+		Queriers:      *queriers,
+		QueryInterval: *qInterval,
+		Seed:          *seed,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Ctrl-C ends the run early but still writes the report
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	report := runner.Run(ctx)
+	report.Version = *version
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
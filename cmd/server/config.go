@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/oidc"
+	"github.com/google/uuid"
+)
+
+// envOr returns the variable or def when it is unset/empty.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("%s: %v", key, err)
+	}
+	return n
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("%s: %v", key, err)
+	}
+	return d
+}
+
+// jwtConfig reads the token verification settings. JWT_PUBLIC_KEYS
+// ("kid=path.pem,...") and JWT_JWKS_URL enable RS256/ES256; JWT_SIGN_KEY
+// keeps accepting HS256 tokens during the migration and can then be unset.
+// The signer is nil when the API has no key to issue session tokens with.
+func jwtConfig() (middleware.JWTConfig, *auth.Signer) {
+	cfg := middleware.JWTConfig{
+		Secret:   []byte(os.Getenv("JWT_SIGN_KEY")),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+	}
+	var sets keys.Chain
+	if spec := os.Getenv("JWT_PUBLIC_KEYS"); spec != "" {
+		static, err := keys.LoadFiles(spec)
+		if err != nil {
+			log.Fatalf("JWT_PUBLIC_KEYS: %v", err)
+		}
+		sets = append(sets, static)
+	}
+	if url := os.Getenv("JWT_JWKS_URL"); url != "" {
+		sets = append(sets, keys.NewJWKS(url, envDuration("JWT_JWKS_TTL", 10*time.Minute)))
+	}
+	if len(sets) > 0 {
+		cfg.Keys = sets
+	}
+	signer := jwtSigner(cfg)
+	if signer != nil && signer.Key != nil {
+		// the API must accept the tokens it signs itself
+		sets = append(sets, keys.Static{signer.Kid: signer.Key.Public()})
+		cfg.Keys = sets
+	}
+	if p := oidcProvider(); p != nil {
+		cfg.Trusted = append(cfg.Trusted, p)
+	}
+	if len(cfg.Secret) == 0 && cfg.Keys == nil && len(cfg.Trusted) == 0 {
+		log.Fatal("set JWT_PUBLIC_KEYS, JWT_JWKS_URL, JWT_PRIVATE_KEY, JWT_SIGN_KEY or OIDC_ISSUER")
+	}
+	return cfg, signer
+}
+
+// jwtSigner reads the key the API signs session tokens with: JWT_PRIVATE_KEY
+// (PEM) named JWT_KID, else the HS256 JWT_SIGN_KEY; nil when neither is set.
+func jwtSigner(cfg middleware.JWTConfig) *auth.Signer {
+	signer := &auth.Signer{Secret: cfg.Secret, Issuer: cfg.Issuer, Audience: cfg.Audience}
+	path := os.Getenv("JWT_PRIVATE_KEY")
+	if path == "" {
+		if len(cfg.Secret) == 0 {
+			return nil
+		}
+		return signer
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("JWT_PRIVATE_KEY: %v", err)
+	}
+	if signer.Key, err = keys.ParsePrivatePEM(data); err != nil {
+		log.Fatalf("JWT_PRIVATE_KEY: %v", err)
+	}
+	if signer.Kid = os.Getenv("JWT_KID"); signer.Kid == "" {
+		log.Fatal("JWT_PRIVATE_KEY needs JWT_KID")
+	}
+	return signer
+}
+
+// oidcProvider discovers the OIDC_ISSUER identity provider; nil when unset.
+// OIDC_ROLE_MAP ("group=role,...") and OIDC_TENANT_MAP ("name=uuid,...")
+// translate its claims; OIDC_TENANT is the tenant for tokens without one.
+// Groups only grant roles through OIDC_ROLE_MAP, so OIDC_ROLES_CLAIM
+// without it is refused.
+func oidcProvider() *oidc.Provider {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+	cfg := oidc.Config{
+		Issuer:      issuer,
+		ClientID:    os.Getenv("OIDC_CLIENT_ID"),
+		RolesClaim:  os.Getenv("OIDC_ROLES_CLAIM"),
+		TenantClaim: os.Getenv("OIDC_TENANT_CLAIM"),
+		JWKSTTL:     envDuration("OIDC_JWKS_TTL", 10*time.Minute),
+	}
+	if cfg.ClientID == "" {
+		log.Fatal("OIDC_ISSUER needs OIDC_CLIENT_ID")
+	}
+	var err error
+	if spec := os.Getenv("OIDC_ROLE_MAP"); spec != "" {
+		if cfg.RoleMap, err = oidc.ParseMap(spec); err != nil {
+			log.Fatalf("OIDC_ROLE_MAP: %v", err)
+		}
+		if cfg.RolesClaim == "" {
+			cfg.RolesClaim = "groups"
+		}
+	} else if cfg.RolesClaim != "" {
+		log.Fatal("OIDC_ROLES_CLAIM needs OIDC_ROLE_MAP: group names are not taken as roles")
+	}
+	if spec := os.Getenv("OIDC_TENANT_MAP"); spec != "" {
+		m, err := oidc.ParseMap(spec)
+		if err != nil {
+			log.Fatalf("OIDC_TENANT_MAP: %v", err)
+		}
+		cfg.TenantMap = map[string]uuid.UUID{}
+		for name, ids := range m {
+			if cfg.TenantMap[name], err = uuid.Parse(ids[0]); err != nil {
+				log.Fatalf("OIDC_TENANT_MAP %s: %v", name, err)
+			}
+		}
+	}
+	if v := os.Getenv("OIDC_TENANT"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			log.Fatalf("OIDC_TENANT: %v", err)
+		}
+		cfg.Tenant = &id
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	p, err := oidc.Discover(ctx, cfg)
+	if err != nil {
+		log.Fatalf("OIDC_ISSUER: %v", err)
+	}
+	return p
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"errors"
+	"expvar"
+	"path/filepath"
+
 	"github.com/aditi2420/fleet-tracker/internal/middleware"
 	"github.com/aditi2420/fleet-tracker/internal/stream"
 	"github.com/google/uuid"
@@ -18,10 +22,12 @@ import (
 	//"github.com/google/uuid"
 
 	"context"
+	"github.com/aditi2420/fleet-tracker/internal/auth"
 	"github.com/aditi2420/fleet-tracker/internal/cache"
 	"github.com/aditi2420/fleet-tracker/internal/controller"
 	"github.com/aditi2420/fleet-tracker/internal/repository"
 	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/sim"
 	"github.com/joho/godotenv"
 )
 
@@ -48,12 +54,45 @@ func main() {
 	if err != nil {
 		log.Fatalf("gorm: %v", err)
 	}
-	redisCache, err := cache.NewRedis(redisAddr, "", 0, 5*time.Minute)
+	cacheCfg := cache.Config{
+		Backend:   envOr("CACHE_BACKEND", cache.BackendRedis),
+		RedisAddr: redisAddr,
+		Size:      envInt("CACHE_SIZE", 10_000),
+		TTL:       envDuration("CACHE_TTL", 5*time.Minute),
+		L1Size:    envInt("CACHE_L1_SIZE", 10_000),
+		L1TTL:     envDuration("CACHE_L1_TTL", 2*time.Second),
+	}
+	vehicleCache, err := cache.New(cacheCfg)
+	if err != nil {
+		log.Fatalf("cache: %v", err)
+	}
+	// the memory backend keeps these in process too, so it runs without Redis
+	nonces, err := cache.NewNonceStore(cacheCfg)
+	if err != nil {
+		log.Fatalf("redis: %v", err)
+	}
+	limiter, err := cache.NewRateLimiter(cacheCfg)
 	if err != nil {
 		log.Fatalf("redis: %v", err)
 	}
+	revocations, err := cache.NewRevocationList(cacheCfg)
+	if err != nil {
+		log.Fatalf("redis: %v", err)
+	}
+	vehiclePlans, err := middleware.ParsePlans(envOr("RATE_LIMIT_VEHICLE_PLANS", "default=5:20"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientPlans, err := middleware.ParsePlans(envOr("RATE_LIMIT_CLIENT_PLANS", "default=50:200"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	planAssignments := middleware.ParseAssignments(os.Getenv("RATE_LIMIT_ASSIGNMENTS"))
 
 	r := gin.New()
+	// handlers pass *gin.Context to services; fallback lets the tenant scope
+	// set on the request context by the auth middleware reach the repositories
+	r.ContextWithFallback = true
 	r.Use(gin.Logger(), gin.Recovery())
 
 	r.GET("/hello", func(c *gin.Context) {
@@ -61,27 +100,228 @@ func main() {
 	})
 
 	// JWT middleware
-	secret := []byte(os.Getenv("JWT_SIGN_KEY"))
 	r.Use(gin.Logger(), gin.Recovery())
-	r.Use(middleware.NewJWT(secret))
-	r.Use(middleware.HTTPLogger())
+	r.Use(middleware.RequestID(), middleware.HTTPLogger())
+	jwtCfg, signer := jwtConfig()
+	jwtCfg.Revocations = revocations
+	jwtAuth := middleware.NewJWT(jwtCfg)
 
 	//add routes
 	tripRepo := repository.NewTripRepo(db)
 	vehicleRepo := repository.NewVehicleRepo(db, tripRepo)
+	positionRepo := repository.NewPositionRepo(db)
+	diagRepo := repository.NewDiagnosticsRepo(db)
+	credRepo := repository.NewCredentialRepo(db)
+	deviceRepo := repository.NewDeviceRepo(db)
+	driverRepo := repository.NewDriverRepo(db)
+	groupRepo := repository.NewGroupRepo(db)
+	accessRepo := repository.NewAccessRepo(db)
+	sessionRepo := repository.NewSessionRepo(db)
+	userRepo := repository.NewUserRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	shareRepo := repository.NewShareRepo(db)
+
+	svc := service.New(vehicleRepo, tripRepo, positionRepo, diagRepo, deviceRepo, driverRepo, groupRepo, accessRepo, vehicleCache)
+	// hmac device credentials keep their signing key sealed with this key
+	deviceKey := []byte(os.Getenv("DEVICE_KEY_SECRET"))
+	credSvc := service.NewCredentials(credRepo, deviceKey)
+	if len(deviceKey) == 0 {
+		slog.Warn("no DEVICE_KEY_SECRET: hmac device credentials disabled")
+	} else if n, err := credSvc.SealLegacy(context.Background()); err != nil {
+		log.Fatalf("sealing device keys: %v", err)
+	} else if n > 0 {
+		slog.Info("sealed hmac device keys stored in the clear", "count", n)
+	}
+	registry := service.NewRegistry(vehicleRepo, accessRepo)
+	devices := service.NewDevices(deviceRepo, vehicleRepo)
+	groups := service.NewGroups(groupRepo, vehicleRepo)
+	drivers := service.NewDrivers(driverRepo, vehicleRepo, tripRepo)
+	access := service.NewAccess(accessRepo, vehicleRepo, groupRepo)
+	audits := service.NewAudit(auditRepo)
+
+	// every change plus the reads that reveal where vehicles are or were;
+	// ingest is telemetry, not a user action, and isn't audited
+	auditCfg := middleware.AuditConfig{
+		Log: audits,
+		Reads: []string{
+			"/api/vehicle/status", "/api/vehicle/history", "/api/vehicle/trips",
+			"/api/drivers/:id/trips", "/api/audit",
+		},
+		Loaders: map[string]middleware.AuditLoader{
+			"/api/vehicles/:id": middleware.AuditLoaderOf(registry.Get),
+			"/api/groups/:id":   middleware.AuditLoaderOf(groups.Get),
+			"/api/devices/:id":  middleware.AuditLoaderOf(devices.Get),
+			"/api/drivers/:id":  middleware.AuditLoaderOf(drivers.Get),
+		},
+	}
+	// the users loader is added below once the service exists; the map is
+	// shared with the middleware
+	audit := middleware.NewAudit(auditCfg)
+
+	// sessions (short-lived access tokens plus rotating refresh tokens) and
+	// password login, only when the API has a key to sign tokens with
+	if signer != nil {
+		sessions := service.NewSessions(sessionRepo, revocations, service.SessionConfig{
+			Signer:     *signer,
+			AccessTTL:  envDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTTL: envDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		})
+		users := service.NewUsers(userRepo, sessions, service.UserConfig{
+			MaxFailures: envInt("LOGIN_MAX_FAILURES", 5),
+			LockFor:     envDuration("LOGIN_LOCKOUT", 15*time.Minute),
+			ResetTTL:    envDuration("PASSWORD_RESET_TTL", time.Hour),
+			TOTPIssuer:  envOr("TOTP_ISSUER", "Fleet Tracker"),
+		})
+		auditCfg.Loaders["/api/users/:id"] = middleware.AuditLoaderOf(users.Get)
+
+		authGrp := r.Group("/auth", audit)
+		authGrp.POST("/login", controller.LoginHandler(users))
+		authGrp.POST("/password/reset", controller.ResetPasswordHandler(users))
+		authGrp.POST("/session", jwtAuth, controller.StartSessionHandler(sessions))
+		authGrp.POST("/refresh", controller.RefreshSessionHandler(sessions))
+		authGrp.POST("/logout", jwtAuth, controller.LogoutHandler(sessions))
+		authGrp.POST("/revoke-all", jwtAuth, controller.RevokeAllHandler(sessions))
+		authGrp.POST("/totp", jwtAuth, controller.SetupTOTPHandler(users))
+		authGrp.POST("/totp/enable", jwtAuth, controller.EnableTOTPHandler(users))
+		authGrp.POST("/totp/disable", jwtAuth, controller.DisableTOTPHandler(users))
+
+		usrs := r.Group("/api/users", jwtAuth, audit, middleware.Unrestricted(), middleware.RequireScope(auth.ScopeAccessManage))
+		usrs.POST("", controller.CreateUserHandler(users))
+		usrs.GET("", controller.ListUsersHandler(users))
+		usrs.GET("/:id", controller.GetUserHandler(users))
+		usrs.PATCH("/:id", controller.UpdateUserHandler(users))
+		usrs.POST("/:id/password-reset", controller.IssuePasswordResetHandler(users))
+	} else {
+		slog.Warn("no JWT_PRIVATE_KEY or JWT_SIGN_KEY: /auth and /api/users routes disabled")
+	}
+
+	// API routes; each one needs a scope (package auth) on top of a valid token
+	read := middleware.RequireScope(auth.ScopeVehicleRead)
+	write := middleware.RequireScope(auth.ScopeVehicleWrite)
+	manageDevices := middleware.RequireScope(auth.ScopeDeviceManage)
+	// restricted users only reach the vehicle reads that filter by their
+	// access grants; everything else is closed to them
+	unrestricted := middleware.Unrestricted()
+
+	api := r.Group("/api/vehicle", jwtAuth, audit)
+	{
+		api.GET("/status", read, controller.GetStatusHandler(svc))
+		api.GET("/trips", read, controller.GetTripsHandler(svc))
+		api.GET("/history", read, controller.GetHistoryHandler(svc))
+		api.GET("/signals", read, controller.GetSignalsHandler(svc))
+		api.GET("/dtcs", read, controller.GetDTCsHandler(svc))
+		api.GET("/events", read, controller.GetEventsHandler(svc))
+
+		api.POST("/credentials", unrestricted, manageDevices, controller.IssueCredentialHandler(credSvc))
+		api.GET("/credentials", unrestricted, manageDevices, controller.ListCredentialsHandler(credSvc))
+		api.DELETE("/credentials/:key_id", unrestricted, manageDevices, controller.RevokeCredentialHandler(credSvc))
+	}
+
+	vehicles := r.Group("/api/vehicles", jwtAuth, audit)
+	{
+		vehicles.POST("", unrestricted, write, controller.CreateVehicleHandler(registry))
+		vehicles.GET("", read, controller.ListVehiclesHandler(registry))
+		vehicles.GET("/vin/:vin", read, controller.DecodeVINHandler())
+		vehicles.GET("/:id", read, controller.GetVehicleHandler(registry))
+		vehicles.PATCH("/:id", unrestricted, write, controller.UpdateVehicleHandler(registry))
+		vehicles.POST("/:id/archive", unrestricted, write, controller.ArchiveVehicleHandler(registry))
+		vehicles.POST("/:id/restore", unrestricted, write, controller.RestoreVehicleHandler(registry))
+		vehicles.GET("/:id/devices", unrestricted, read, controller.VehicleDevicesHandler(devices))
+		vehicles.GET("/:id/drivers", unrestricted, read, controller.VehicleDriversHandler(drivers))
+	}
+
+	// share links: signed tokens anybody can open under /share, outside the
+	// JWT middleware
+	if secret := os.Getenv("SHARE_LINK_SECRET"); secret != "" {
+		shares := service.NewShares(shareRepo, vehicleRepo, svc, service.ShareConfig{
+			Secret:          []byte(secret),
+			Breadcrumb:      envDuration("SHARE_BREADCRUMB", 2*time.Hour),
+			BreadcrumbLimit: envInt("SHARE_BREADCRUMB_LIMIT", 500),
+		})
+		vehicles.POST("/:id/share-links", unrestricted, write, controller.CreateShareLinkHandler(shares))
+		vehicles.GET("/:id/share-links", unrestricted, read, controller.ListShareLinksHandler(shares))
+		vehicles.DELETE("/:id/share-links/:link_id", unrestricted, write, controller.RevokeShareLinkHandler(shares))
+		r.GET("/share/:token", controller.ViewShareHandler(shares))
+	} else {
+		slog.Warn("no SHARE_LINK_SECRET: share links disabled")
+	}
+
+	grps := r.Group("/api/groups", jwtAuth, audit, unrestricted)
+	{
+		grps.POST("", write, controller.CreateGroupHandler(groups))
+		grps.GET("", read, controller.ListGroupsHandler(groups))
+		grps.GET("/:id", read, controller.GetGroupHandler(groups))
+		grps.PATCH("/:id", write, controller.RenameGroupHandler(groups))
+		grps.POST("/:id/move", write, controller.MoveGroupHandler(groups))
+		grps.DELETE("/:id", write, controller.DeleteGroupHandler(groups))
+		grps.GET("/:id/vehicles", read, controller.GroupVehiclesHandler(groups))
+		grps.POST("/:id/vehicles", write, controller.AddGroupVehiclesHandler(groups))
+		grps.DELETE("/:id/vehicles/:vehicle_id", write, controller.RemoveGroupVehicleHandler(groups))
+	}
+
+	devs := r.Group("/api/devices", jwtAuth, audit, unrestricted)
+	{
+		devs.POST("", manageDevices, controller.CreateDeviceHandler(devices))
+		devs.GET("", read, controller.ListDevicesHandler(devices))
+		devs.GET("/:id", read, controller.GetDeviceHandler(devices))
+		devs.PATCH("/:id", manageDevices, controller.UpdateDeviceHandler(devices))
+		devs.POST("/:id/assign", manageDevices, controller.AssignDeviceHandler(devices))
+		devs.POST("/:id/unassign", manageDevices, controller.UnassignDeviceHandler(devices))
+		devs.GET("/:id/assignments", read, controller.DeviceAssignmentsHandler(devices))
+	}
+
+	drvs := r.Group("/api/drivers", jwtAuth, audit, unrestricted)
+	{
+		drvs.POST("", write, controller.CreateDriverHandler(drivers))
+		drvs.GET("", read, controller.ListDriversHandler(drivers))
+		drvs.GET("/:id", read, controller.GetDriverHandler(drivers))
+		drvs.PATCH("/:id", write, controller.UpdateDriverHandler(drivers))
+		drvs.POST("/:id/assign", write, controller.AssignDriverHandler(drivers))
+		drvs.POST("/:id/unassign", write, controller.UnassignDriverHandler(drivers))
+		drvs.GET("/:id/assignments", read, controller.DriverAssignmentsHandler(drivers))
+		drvs.GET("/:id/trips", read, controller.DriverTripsHandler(drivers))
+	}
 
-	svc := service.New(vehicleRepo, tripRepo, redisCache)
+	grants := r.Group("/api/access-grants", jwtAuth, audit, unrestricted, middleware.RequireScope(auth.ScopeAccessManage))
+	{
+		grants.POST("", controller.CreateAccessGrantHandler(access))
+		grants.GET("", controller.ListAccessGrantsHandler(access))
+		grants.DELETE("/:id", controller.DeleteAccessGrantHandler(access))
+	}
 
-	// API routes
-	api := r.Group("/api/vehicle")
+	// expvar also publishes the command line and memory stats
+	r.GET("/debug/vars", jwtAuth, unrestricted, middleware.RequireScope(auth.ScopeMetricsRead), gin.WrapH(expvar.Handler()))
+
+	audited := r.Group("/api/audit", jwtAuth, audit, unrestricted, middleware.RequireScope(auth.ScopeAuditRead))
 	{
-		api.GET("/status", controller.GetStatusHandler(svc))
-		api.GET("/trips", controller.GetTripsHandler(svc))
-		api.POST("/ingest", controller.IngestHandler(svc))
+		audited.GET("", controller.ListAuditHandler(audits))
+		audited.GET("/verify", controller.VerifyAuditHandler(audits))
+	}
+
+	// ingest accepts a device credential (API key / HMAC) or a JWT
+	deviceAuth := middleware.NewDeviceAuth(credRepo, nonces, deviceKey, jwtAuth)
+	planOf := func(c *gin.Context) string { return planAssignments[middleware.ClientKey(c)] }
+	clientLimit := middleware.NewRateLimit(middleware.RateLimitConfig{
+		Limiter: limiter, Name: "ingest-client", Plans: clientPlans, PlanOf: planOf, KeyOf: middleware.ClientKey,
+	})
+	vehicleLimit := middleware.NewRateLimit(middleware.RateLimitConfig{
+		Limiter: limiter, Name: "ingest-vehicle", Plans: vehiclePlans, PlanOf: planOf, KeyOf: middleware.VehicleKey,
+	})
+	r.POST("/api/vehicle/ingest", deviceAuth, middleware.RequireScope(auth.ScopeTelemetryWrite),
+		clientLimit, vehicleLimit, controller.IngestHandler(svc))
+
+	//start the producer and the ingest worker pool
+	pool, err := stream.NewPool(stream.PoolConfig{
+		Workers:   envInt("STREAM_WORKERS", 4),
+		QueueSize: envInt("STREAM_QUEUE_SIZE", 256),
+		Policy:    stream.Policy(envOr("STREAM_POLICY", string(stream.PolicyBlock))),
+		SpillDir:  envOr("STREAM_SPILL_DIR", filepath.Join(os.TempDir(), "fleet-tracker-spill")),
+	}, svc)
+	if err != nil {
+		log.Fatalf("stream: %v", err)
 	}
+	pool.Start()
 
-	//start the producer and consumer for every 2 min streaming
-	vehID := uuid.New()
 	ch := make(chan stream.Payload, 30)
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -93,16 +333,46 @@ func main() {
 		cancel()
 	}()
 
-	go stream.Produce(ctx, ch, vehID)
-	go stream.Consumer(ctx, ch, svc)
+	// SIMULATOR: "builtin" (default) jitters one random vehicle, "off" disables
+	// mock data entirely, anything else is a scenario file for internal/sim.
+	switch mode := envOr("SIMULATOR", "builtin"); mode {
+	case "off":
+	case "builtin":
+		go stream.Produce(ctx, ch, uuid.New())
+	default:
+		sc, err := sim.Load(mode)
+		if err != nil {
+			log.Fatalf("simulator: %v", err)
+		}
+		simulator, err := sim.New(sc)
+		if err != nil {
+			log.Fatalf("simulator: %v", err)
+		}
+		go func() {
+			if err := simulator.Run(ctx, sim.ChannelSink(ch)); err != nil {
+				slog.Error("simulator stopped", "err", err)
+			}
+		}()
+	}
+	go pool.Consume(ctx, ch)
 
+	srv := &http.Server{Addr: ":" + port, Handler: r}
 	log.Printf("⇢ listening on :%s …", port)
 	go func() {
-		if err := r.Run(":" + port); err != nil {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err)
 		}
 	}()
 
 	<-ctx.Done()
 
+	// stop taking requests, then let the workers finish what was queued
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), envDuration("STREAM_DRAIN_TIMEOUT", 30*time.Second))
+	defer drainCancel()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		slog.Error("http shutdown", "err", err)
+	}
+	if err := pool.Drain(drainCtx); err != nil {
+		slog.Error("stream drain", "err", err)
+	}
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/sim"
+	"github.com/aditi2420/fleet-tracker/internal/stream"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	_ = godotenv.Load()
+}
+
+func main() {
+	var (
+		scenario  = flag.String("scenario", "", "scenario file (YAML or JSON)")
+		sinkKind  = flag.String("sink", "http", "where fixes go: http | stream | stdout")
+		url       = flag.String("url", "http://localhost:8080/api/vehicle/ingest", "ingest endpoint (http sink)")
+		token     = flag.String("token", "", "bearer token (http sink; env SIM_TOKEN)")
+		deviceKey = flag.String("device-key", "", "X-Device-Key value instead of a bearer token (http sink)")
+		seed      = flag.Int64("seed", 0, "override the scenario seed (0 keeps it)")
+
+		replay  = flag.String("replay", "", "replay a recorded .gpx or .csv track instead of a scenario")
+		speed   = flag.String("speed", "1", "replay speed: 1 (real time), x10, x100 or max")
+		toNow   = flag.Bool("now", false, "replay: shift timestamps so the track starts now")
+		vehicle = flag.String("vehicle", "", "replay: vehicle_id to report as (required if the track has none)")
+		plate   = flag.String("plate", "", "replay: plate number to report")
+	)
+	flag.Parse()
+	if (*scenario == "") == (*replay == "") {
+		log.Fatal("provide exactly one of -scenario or -replay")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var sink sim.Sink
+	switch *sinkKind {
+	case "http":
+		header := http.Header{}
+		if *token == "" {
+			*token = os.Getenv("SIM_TOKEN")
+		}
+		switch {
+		case *deviceKey != "":
+			header.Set(middleware.HeaderDeviceKey, *deviceKey)
+		case *token != "":
+			header.Set("Authorization", "Bearer "+*token)
+		default:
+			log.Fatal("http sink needs -token, SIM_TOKEN or -device-key")
+		}
+		sink = sim.NewHTTPSink(*url, header)
+	case "stdout":
+		sink = &sim.WriterSink{W: os.Stdout}
+	case "stream":
+		ch, drain := startPipeline(ctx)
+		defer drain()
+		sink = sim.ChannelSink(ch)
+	default:
+		log.Fatalf("unknown sink %q", *sinkKind)
+	}
+
+	if *replay != "" {
+		runReplay(ctx, sink, *replay, *speed, *toNow, *vehicle, *plate)
+		return
+	}
+
+	sc, err := sim.Load(*scenario)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *seed != 0 {
+		sc.Seed = *seed
+	}
+	simulator, err := sim.New(sc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("simulator running", "scenario", *scenario, "seed", sc.Seed, "sink", *sinkKind)
+	if err := simulator.Run(ctx, sink); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runReplay(ctx context.Context, sink sim.Sink, path, speed string, toNow bool, vehicle, plate string) {
+	fixes, err := sim.LoadTrack(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := sim.ReplayOptions{RemapToNow: toNow, Plate: plate}
+	if opts.Speed, err = sim.ParseSpeed(speed); err != nil {
+		log.Fatal(err)
+	}
+	if vehicle != "" {
+		if opts.VehicleID, err = uuid.Parse(vehicle); err != nil {
+			log.Fatalf("-vehicle: %v", err)
+		}
+	}
+
+	slog.Info("replaying track", "file", path, "fixes", len(fixes),
+		"recorded", fixes[len(fixes)-1].Timestamp.Sub(fixes[0].Timestamp), "speed", speed)
+	if err := sim.Replay(ctx, fixes, sink, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// startPipeline wires the same repository/service/worker pool the server uses
+// and feeds it straight from the simulator, bypassing HTTP. drain ingests
+// every fix already sent unless ctx was cancelled (a signal) first.
+func startPipeline(ctx context.Context) (chan<- stream.Payload, func()) {
+	dsn := os.Getenv("PG_DSN")
+	if dsn == "" {
+		log.Fatal("PG_DSN env var is missing")
+	}
+	db, err := repository.New(dsn)
+	if err != nil {
+		log.Fatalf("gorm: %v", err)
+	}
+	vehicleCache, err := cache.New(cache.Config{
+		Backend:   os.Getenv("CACHE_BACKEND"),
+		RedisAddr: os.Getenv("REDIS_ADDR"),
+		TTL:       5 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("cache: %v", err)
+	}
+
+	tripRepo := repository.NewTripRepo(db)
+	svc := service.New(
+		repository.NewVehicleRepo(db, tripRepo),
+		tripRepo,
+		repository.NewPositionRepo(db),
+		repository.NewDiagnosticsRepo(db),
+		repository.NewDeviceRepo(db),
+		repository.NewDriverRepo(db),
+		repository.NewGroupRepo(db),
+		repository.NewAccessRepo(db),
+		vehicleCache,
+	)
+	pool, err := stream.NewPool(stream.PoolConfig{Workers: 4, QueueSize: 256, Policy: stream.PolicyBlock}, svc)
+	if err != nil {
+		log.Fatalf("stream: %v", err)
+	}
+	pool.Start()
+
+	ch := make(chan stream.Payload, 256)
+	done := make(chan struct{})
+	go func() {
+		pool.Consume(ctx, ch)
+		close(done)
+	}()
+
+	return ch, func() {
+		close(ch) // Consume returns once the buffer is empty
+		<-done
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer drainCancel()
+		if err := pool.Drain(drainCtx); err != nil {
+			slog.Error("stream drain", "err", err)
+		}
+		_ = vehicleCache.Close()
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	_ = godotenv.Load()
+}
+
+func main() {
+	var (
+		name = flag.String("name", "", "create a tenant with this name and print its id")
+		list = flag.Bool("list", false, "list tenants")
+	)
+	flag.Parse()
+	if (*name == "") == !*list {
+		log.Fatal("provide exactly one of -name or -list")
+	}
+
+	dsn := os.Getenv("PG_DSN")
+	if dsn == "" {
+		log.Fatal("PG_DSN env var is missing")
+	}
+	db, err := repository.New(dsn)
+	if err != nil {
+		log.Fatalf("gorm: %v", err)
+	}
+	repo := repository.NewTenantRepo(db)
+	ctx := context.Background()
+
+	if *list {
+		ts, err := repo.List(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, t := range ts {
+			fmt.Printf("%s\t%s\n", t.ID, t.Name)
+		}
+		return
+	}
+
+	t := model.Tenant{ID: uuid.New(), Name: *name}
+	if err := repo.Create(ctx, &t); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(t.ID)
+}
@@ -1,34 +1,166 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
-	mymw "github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func main() {
 	var (
 		sub    = flag.String("sub", "dev", "subject/user id")
 		ttl    = flag.Duration("exp", 24*time.Hour, "token validity")
-		secret = flag.String("secret", "", "signing secret (env JWT_SIGN_KEY overrides)")
+		secret = flag.String("secret", "", "HS256 signing secret (env JWT_SIGN_KEY overrides)")
+		key    = flag.String("key", "", "sign with this RSA/P-256 private key PEM instead of a secret")
+		kid    = flag.String("kid", "", "key id put in the token header (with -key) or given to -keygen")
+		iss    = flag.String("iss", "", "issuer claim")
+		aud    = flag.String("aud", "", "audience claim")
+		tenant = flag.String("tenant", "", "tenant UUID (empty: default tenant)")
+		roles  = flag.String("role", auth.RoleViewer, "comma-separated roles: admin, dispatcher, viewer, device")
+		scopes = flag.String("scopes", "", "comma-separated extra scopes, e.g. telemetry:write,alerts:manage")
+		limit  = flag.Bool("restricted", false, "limit the token to the vehicles granted to -sub")
+
+		keygen = flag.String("keygen", "", "generate an RS256 or ES256 keypair as <kid>.key.pem and <kid>.pub.pem, then exit")
+		jwks   = flag.String("jwks", "", "print a JWKS document for kid=public.pem,... and exit")
 	)
 	flag.Parse()
 
-	sec := []byte(*secret)
+	switch {
+	case *keygen != "":
+		if err := generate(*keygen, *kid); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case *jwks != "":
+		if err := printJWKS(*jwks); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if _, err := uuid.Parse(*tenant); *tenant != "" && err != nil {
+		log.Fatalf("-tenant: %v", err)
+	}
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: *sub, Issuer: *iss},
+		Tenant:           *tenant,
+		Roles:            splitList(*roles),
+		Scopes:           splitList(*scopes),
+		Restricted:       *limit,
+	}
+	if *aud != "" {
+		claims.Audience = jwt.ClaimStrings{*aud}
+	}
+	if err := auth.Validate(claims.Roles, claims.Scopes); err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		tok string
+		err error
+	)
+	if *key != "" {
+		tok, err = signWithKey(claims, *key, *kid, *ttl)
+	} else {
+		tok, err = signWithSecret(claims, *secret, *ttl)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(tok)
+}
+
+func signWithSecret(claims auth.Claims, secret string, ttl time.Duration) (string, error) {
+	sec := []byte(secret)
 	if len(sec) == 0 {
 		sec = []byte(os.Getenv("JWT_SIGN_KEY"))
 	}
 	if len(sec) == 0 {
-		log.Fatal("provide -secret or set JWT_SIGN_KEY")
+		return "", fmt.Errorf("provide -key, -secret or set JWT_SIGN_KEY")
 	}
+	return auth.GenerateDevToken(claims, sec, ttl)
+}
 
-	tok, err := mymw.GenerateDevToken(*sub, sec, *ttl)
+func signWithKey(claims auth.Claims, path, kid string, ttl time.Duration) (string, error) {
+	if kid == "" {
+		return "", fmt.Errorf("-key needs -kid")
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
-	fmt.Println(tok)
+	priv, err := keys.ParsePrivatePEM(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return auth.SignToken(claims, priv, kid, ttl)
+}
+
+func generate(alg, kid string) error {
+	if kid == "" {
+		return fmt.Errorf("-keygen needs -kid")
+	}
+	priv, err := keys.Generate(strings.ToUpper(alg))
+	if err != nil {
+		return err
+	}
+	privPEM, err := keys.EncodePrivatePEM(priv)
+	if err != nil {
+		return err
+	}
+	pubPEM, err := keys.EncodePublicPEM(priv.Public())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(kid+".key.pem", privPEM, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(kid+".pub.pem", pubPEM, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s.key.pem and %s.pub.pem\n", kid, kid)
+	return nil
+}
+
+func printJWKS(spec string) error {
+	set, err := keys.LoadFiles(spec)
+	if err != nil {
+		return err
+	}
+	kids := make([]string, 0, len(set))
+	for kid := range set {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	var doc keys.JWKSet
+	for _, kid := range kids {
+		k, err := keys.NewJWK(kid, set[kid])
+		if err != nil {
+			return err
+		}
+		doc.Keys = append(doc.Keys, k)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
 }
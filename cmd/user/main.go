@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	_ = godotenv.Load()
+}
+
+// Creates a user directly in the database, e.g. the first admin who then
+// manages everyone else through /api/users. The password is read from stdin.
+func main() {
+	var (
+		email = flag.String("email", "", "login email")
+		name  = flag.String("name", "", "display name")
+		tnt   = flag.String("tenant", "", "tenant UUID (empty: default tenant)")
+		roles = flag.String("role", auth.RoleAdmin, "comma-separated roles")
+	)
+	flag.Parse()
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	tid, err := tenant.Parse(*tnt)
+	if err != nil {
+		log.Fatalf("-tenant: %v", err)
+	}
+
+	dsn := os.Getenv("PG_DSN")
+	if dsn == "" {
+		log.Fatal("PG_DSN env var is missing")
+	}
+	db, err := repository.New(dsn)
+	if err != nil {
+		log.Fatalf("gorm: %v", err)
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatal(err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	// Create never touches sessions
+	users := service.NewUsers(repository.NewUserRepo(db), nil, service.UserConfig{})
+	roleList := strings.Split(*roles, ",")
+	u, err := users.Create(tenant.WithID(context.Background(), tid), model.UserPatch{
+		Email: email, Name: name, Roles: &roleList,
+	}, password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(u.ID)
+}
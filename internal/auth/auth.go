@@ -0,0 +1,111 @@
+// Package auth defines the roles and scopes carried in access tokens and
+// what each role is allowed to do.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Scopes, granted directly or through a role.
+const (
+	ScopeVehicleRead    = "vehicle:read"    // status, history, trips, diagnostics and every registry listing
+	ScopeVehicleWrite   = "vehicle:write"   // vehicles, groups, drivers and shifts
+	ScopeDeviceManage   = "device:manage"   // trackers, their assignments and credentials
+	ScopeTelemetryWrite = "telemetry:write" // ingest
+	ScopeAlertsManage   = "alerts:manage"   // alert rules (reserved; no routes yet)
+	ScopeAccessManage   = "access:manage"   // per-vehicle access grants
+	ScopeAuditRead      = "audit:read"      // the audit log
+	ScopeMetricsRead    = "metrics:read"    // /debug/vars
+)
+
+// Roles.
+const (
+	RoleAdmin      = "admin"
+	RoleDispatcher = "dispatcher"
+	RoleViewer     = "viewer"
+	RoleDevice     = "device"
+)
+
+var (
+	ErrUnknownRole  = errors.New("unknown role")
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+var allScopes = []string{
+	ScopeVehicleRead, ScopeVehicleWrite, ScopeDeviceManage, ScopeTelemetryWrite, ScopeAlertsManage,
+	ScopeAccessManage, ScopeAuditRead, ScopeMetricsRead,
+}
+
+var roleScopes = map[string][]string{
+	RoleAdmin:      allScopes,
+	RoleDispatcher: {ScopeVehicleRead, ScopeVehicleWrite, ScopeAlertsManage},
+	RoleViewer:     {ScopeVehicleRead},
+	RoleDevice:     {ScopeTelemetryWrite},
+}
+
+// Grants is the set of scopes a caller holds.
+type Grants map[string]bool
+
+// Expand returns the scopes of roles plus the explicit scopes. Unknown names
+// grant nothing, so a token minted for a newer server degrades safely.
+func Expand(roles, scopes []string) Grants {
+	g := Grants{}
+	for _, r := range roles {
+		for _, s := range roleScopes[r] {
+			g[s] = true
+		}
+	}
+	for _, s := range scopes {
+		if isScope(s) {
+			g[s] = true
+		}
+	}
+	return g
+}
+
+// Has reports whether every scope is granted.
+func (g Grants) Has(scopes ...string) bool {
+	for _, s := range scopes {
+		if !g[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// List returns the granted scopes sorted.
+func (g Grants) List() []string {
+	res := make([]string, 0, len(g))
+	for s := range g {
+		res = append(res, s)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Validate rejects role and scope names the server doesn't know; token
+// issuers call it so typos fail loudly instead of minting a useless token.
+func Validate(roles, scopes []string) error {
+	for _, r := range roles {
+		if _, ok := roleScopes[r]; !ok {
+			return fmt.Errorf("%w %q", ErrUnknownRole, r)
+		}
+	}
+	for _, s := range scopes {
+		if !isScope(s) {
+			return fmt.Errorf("%w %q", ErrUnknownScope, s)
+		}
+	}
+	return nil
+}
+
+func isScope(s string) bool {
+	for _, known := range allScopes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []string
+		scopes []string
+		want   []string
+	}{
+		{name: "no roles, no scopes", want: []string{}},
+		{name: "viewer", roles: []string{RoleViewer}, want: []string{ScopeVehicleRead}},
+		{name: "device", roles: []string{RoleDevice}, want: []string{ScopeTelemetryWrite}},
+		{name: "viewer plus explicit scope", roles: []string{RoleViewer}, scopes: []string{ScopeTelemetryWrite},
+			want: []string{ScopeTelemetryWrite, ScopeVehicleRead}},
+		{name: "admin has everything", roles: []string{RoleAdmin},
+			want: []string{ScopeAccessManage, ScopeAlertsManage, ScopeAuditRead, ScopeDeviceManage, ScopeMetricsRead, ScopeTelemetryWrite, ScopeVehicleRead, ScopeVehicleWrite}},
+		{name: "unknown names grant nothing", roles: []string{"root"}, scopes: []string{"vehicle:*"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Expand(tt.roles, tt.scopes).List())
+		})
+	}
+}
+
+func TestGrants_Has(t *testing.T) {
+	g := Expand([]string{RoleDispatcher}, nil)
+	assert.True(t, g.Has(ScopeVehicleRead, ScopeVehicleWrite))
+	assert.False(t, g.Has(ScopeVehicleRead, ScopeDeviceManage))
+	assert.True(t, g.Has())
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate([]string{RoleAdmin, RoleViewer}, []string{ScopeAlertsManage}))
+	assert.ErrorIs(t, Validate([]string{"root"}, nil), ErrUnknownRole)
+	assert.ErrorIs(t, Validate(nil, []string{"vehicle:delete"}), ErrUnknownScope)
+}
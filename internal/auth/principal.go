@@ -0,0 +1,23 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller as seen below the HTTP layer.
+// Restricted principals only see the vehicles their access grants cover.
+type Principal struct {
+	Subject    string
+	Restricted bool
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the caller; ok is false for system contexts.
+func PrincipalFrom(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Claims are the token claims the API reads. Tenant is the tenant UUID; tokens
+// without one belong to tenant.Default. Roles and Scopes decide which routes
+// the token may call (see Expand); a token with neither may call none.
+// Restricted tokens only see the vehicles granted to their subject. Session
+// is the refresh token family an access token was issued from, if any.
+type Claims struct {
+	jwt.RegisteredClaims
+	Tenant     string   `json:"tenant,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	Restricted bool     `json:"restricted,omitempty"`
+	Session    string   `json:"sid,omitempty"`
+}
+
+// Signer mints the tokens the API issues itself. It signs with Key (RS256 or
+// ES256, kid in the header) when set, otherwise HS256 with Secret, and stamps
+// Issuer and Audience so middleware.NewJWT accepts the result.
+type Signer struct {
+	Key      crypto.Signer
+	Kid      string
+	Secret   []byte
+	Issuer   string
+	Audience string
+}
+
+// Sign returns claims as a token valid for ttl from now.
+func (s Signer) Sign(claims Claims, ttl time.Duration) (string, error) {
+	claims.Issuer = s.Issuer
+	if s.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.Audience}
+	}
+	if s.Key != nil {
+		return SignToken(claims, s.Key, s.Kid, ttl)
+	}
+	return GenerateDevToken(claims, s.Secret, ttl)
+}
+
+// GenerateDevToken signs claims with HS256, valid for ttl from now.
+func GenerateDevToken(claims Claims, secret []byte, ttl time.Duration) (string, error) {
+	stamp(&claims, ttl)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// SignToken signs claims with an RSA (RS256) or P-256 (ES256) private key and
+// puts kid in the header; the token is valid for ttl from now.
+func SignToken(claims Claims, key crypto.Signer, kid string, ttl time.Duration) (string, error) {
+	alg, err := keys.Alg(key)
+	if err != nil {
+		return "", err
+	}
+	stamp(&claims, ttl)
+	t := jwt.NewWithClaims(jwt.GetSigningMethod(alg), claims)
+	t.Header["kid"] = kid
+	return t.SignedString(key)
+}
+
+// stamp sets iat and exp, and a fresh jti unless the caller chose one, so
+// every token can be revoked on its own.
+func stamp(claims *Claims, ttl time.Duration) {
+	now := time.Now()
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aditi2420/fleet-tracker/internal/model"
@@ -12,7 +13,51 @@ import (
 // Add more methods later (e.g. trip aggregates) without leaking go‑redis.
 type VehicleCache interface {
 	GetStatus(ctx context.Context, id uuid.UUID) (*model.Status, error)
+	// GetStatusTTL is GetStatus plus how long the entry has left to live,
+	// for refreshing hot entries before they expire.
+	GetStatusTTL(ctx context.Context, id uuid.UUID) (*model.Status, time.Duration, error)
 	SetStatus(ctx context.Context, id uuid.UUID, s model.Status) error
 	TTL() time.Duration
 	Close() error
 }
+
+// VehicleCache backends.
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+	BackendTiered = "tiered" // memory L1 in front of Redis, see NewTiered
+)
+
+// Config selects and sizes a VehicleCache. Size applies to the memory
+// backend, L1Size and L1TTL to the tiered one's L1; zero values take the
+// defaults.
+type Config struct {
+	Backend   string
+	RedisAddr string
+	Size      int
+	TTL       time.Duration
+	L1Size    int
+	L1TTL     time.Duration
+}
+
+// New opens the configured backend; an empty Backend means Redis.
+func New(cfg Config) (VehicleCache, error) {
+	switch cfg.Backend {
+	case "", BackendRedis:
+		return NewRedis(cfg.RedisAddr, "", 0, cfg.TTL)
+	case BackendMemory:
+		return NewMemory(cfg.Size, cfg.TTL), nil
+	case BackendTiered:
+		l2, err := NewRedis(cfg.RedisAddr, "", 0, cfg.TTL)
+		if err != nil {
+			return nil, err
+		}
+		bus, err := NewRedisInvalidations(cfg.RedisAddr, "", 0)
+		if err != nil {
+			_ = l2.Close()
+			return nil, err
+		}
+		return NewTiered(l2, bus, cfg.L1Size, cfg.L1TTL), nil
+	}
+	return nil, fmt.Errorf("unknown cache backend %q (want %s, %s or %s)", cfg.Backend, BackendRedis, BackendMemory, BackendTiered)
+}
@@ -0,0 +1,304 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testVehicleCache is the behaviour every VehicleCache backend must share.
+// newCache returns a cache whose entries live for ttl.
+func testVehicleCache(t *testing.T, newCache func(t *testing.T, ttl time.Duration) VehicleCache) {
+	ctx := context.Background()
+	heading, odo, ignition := 90.0, 1234.5, true
+	full := model.Status{
+		Location:   [2]float64{55.2708, 25.2048},
+		Speed:      60.5,
+		Timestamp:  time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		Heading:    &heading,
+		Odometer:   &odo,
+		Ignition:   &ignition,
+		Attributes: map[string]any{"din1": true, "fuel": 41.5},
+	}
+
+	t.Run("miss is nil without error", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		st, err := c.GetStatus(ctx, uuid.New())
+		assert.NoError(t, err)
+		assert.Nil(t, st)
+	})
+
+	t.Run("round trip keeps every field", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		id := uuid.New()
+		require.NoError(t, c.SetStatus(ctx, id, full))
+		st, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, st)
+		assert.Equal(t, full, *st)
+	})
+
+	t.Run("set overwrites", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		id := uuid.New()
+		require.NoError(t, c.SetStatus(ctx, id, model.Status{Speed: 1, Timestamp: full.Timestamp}))
+		require.NoError(t, c.SetStatus(ctx, id, model.Status{Speed: 2, Timestamp: full.Timestamp}))
+		st, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, st)
+		assert.Equal(t, 2.0, st.Speed)
+	})
+
+	t.Run("callers get their own copy", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		id := uuid.New()
+		require.NoError(t, c.SetStatus(ctx, id, full))
+		st, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		*st.Heading = 180
+		st.Attributes["din1"] = false
+		again, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, full, *again)
+	})
+
+	t.Run("tenants don't see each other's entries", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		id := uuid.New()
+		acme := tenant.WithID(ctx, uuid.New())
+		globex := tenant.WithID(ctx, uuid.New())
+		require.NoError(t, c.SetStatus(acme, id, full))
+		st, err := c.GetStatus(globex, id)
+		require.NoError(t, err)
+		assert.Nil(t, st)
+		st, err = c.GetStatus(acme, id)
+		require.NoError(t, err)
+		assert.NotNil(t, st)
+	})
+
+	t.Run("ttl counts down from the configured one", func(t *testing.T) {
+		c := newCache(t, time.Minute)
+		st, left, err := c.GetStatusTTL(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Nil(t, st)
+		assert.Zero(t, left)
+
+		id := uuid.New()
+		require.NoError(t, c.SetStatus(ctx, id, full))
+		time.Sleep(20 * time.Millisecond)
+		st, left, err = c.GetStatusTTL(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, st)
+		assert.Equal(t, full, *st)
+		assert.Greater(t, left, 50*time.Second)
+		assert.Less(t, left, time.Minute)
+	})
+
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		c := newCache(t, 100*time.Millisecond)
+		assert.Equal(t, 100*time.Millisecond, c.TTL())
+		id := uuid.New()
+		require.NoError(t, c.SetStatus(ctx, id, full))
+		time.Sleep(200 * time.Millisecond)
+		st, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Nil(t, st)
+	})
+}
+
+func TestMemoryCache_Contract(t *testing.T) {
+	testVehicleCache(t, func(t *testing.T, ttl time.Duration) VehicleCache {
+		c := NewMemory(100, ttl)
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	})
+}
+
+func TestRedisCache_Contract(t *testing.T) {
+	testVehicleCache(t, func(t *testing.T, ttl time.Duration) VehicleCache {
+		c, err := NewRedis("localhost:6379", "", 0, ttl)
+		if err != nil {
+			t.Skipf("Redis not available: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	})
+}
+
+func TestMemoryCache_Evicts(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory(2, time.Minute).(*memoryCache)
+	a, b, d := uuid.New(), uuid.New(), uuid.New()
+	st := model.Status{Speed: 1}
+
+	require.NoError(t, c.SetStatus(ctx, a, st))
+	require.NoError(t, c.SetStatus(ctx, b, st))
+	got, _ := c.GetStatus(ctx, a) // a is now the most recently used
+	require.NotNil(t, got)
+	require.NoError(t, c.SetStatus(ctx, d, st))
+
+	assert.Equal(t, 2, c.Len())
+	for id, want := range map[uuid.UUID]bool{a: true, b: false, d: true} {
+		got, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got != nil)
+	}
+}
+
+func TestMisses(t *testing.T) {
+	ctx := context.Background()
+	m := NewMisses(10, 100*time.Millisecond)
+	id := uuid.New()
+	acme := tenant.WithID(ctx, uuid.New())
+
+	assert.False(t, m.Has(ctx, id))
+	m.Add(ctx, id)
+	assert.True(t, m.Has(ctx, id))
+	assert.False(t, m.Has(acme, id), "misses are per tenant")
+
+	m.Forget(ctx, id)
+	assert.False(t, m.Has(ctx, id))
+
+	m.Add(ctx, id)
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, m.Has(ctx, id), "misses expire")
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(Config{Backend: BackendMemory, Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, defaultTTL, c.TTL())
+
+	_, err = New(Config{Backend: "memcached"})
+	assert.Error(t, err)
+}
+
+// testNonceStore, testRateLimiter and testRevocationList are the behaviour
+// the Redis and in-memory stores behind the memory backend share.
+func testNonceStore(t *testing.T, nonces NonceStore) {
+	ctx := context.Background()
+	nonce := "test-" + uuid.NewString()
+
+	fresh, err := nonces.Remember(ctx, nonce, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, fresh, "first use")
+	fresh, err = nonces.Remember(ctx, nonce, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, fresh, "replay")
+	fresh, err = nonces.Remember(ctx, nonce+"x", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, fresh, "other nonce")
+}
+
+func testRateLimiter(t *testing.T, limiter RateLimiter) {
+	ctx := context.Background()
+	key := "test:" + uuid.NewString()
+	lim := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < lim.Burst; i++ {
+		allowed, _, err := limiter.Allow(ctx, key, lim)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retry, err := limiter.Allow(ctx, key, lim)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, retry > 0 && retry <= time.Second)
+
+	allowed, _, err = limiter.Allow(ctx, key, Limit{})
+	assert.NoError(t, err)
+	assert.True(t, allowed, "zero limit is unlimited")
+}
+
+func testRevocationList(t *testing.T, revoked RevocationList) {
+	ctx := context.Background()
+	subject, jti := "test-"+uuid.NewString(), uuid.NewString()
+	issued := time.Now().Add(-time.Minute)
+
+	require.NoError(t, revoked.Revoke(ctx, jti, time.Now().Add(time.Minute)))
+	ok, err := revoked.IsRevoked(ctx, jti, subject, issued)
+	require.NoError(t, err)
+	assert.True(t, ok, "revoked jti")
+	ok, err = revoked.IsRevoked(ctx, uuid.NewString(), subject, issued)
+	require.NoError(t, err)
+	assert.False(t, ok, "other jti")
+
+	require.NoError(t, revoked.RevokeSubject(ctx, subject, time.Now()))
+	ok, err = revoked.IsRevoked(ctx, "", subject, issued)
+	require.NoError(t, err)
+	assert.True(t, ok, "issued before the cutoff")
+	ok, err = revoked.IsRevoked(ctx, "", subject, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok, "issued after the cutoff")
+	other := tenant.WithID(ctx, uuid.New())
+	ok, err = revoked.IsRevoked(other, "", subject, issued)
+	require.NoError(t, err)
+	assert.False(t, ok, "same subject in another tenant")
+}
+
+func TestMemoryNonceStore_Contract(t *testing.T) {
+	testNonceStore(t, NewMemoryNonceStore())
+}
+
+func TestMemoryRateLimiter_Contract(t *testing.T) {
+	testRateLimiter(t, NewMemoryRateLimiter())
+}
+
+func TestMemoryRevocationList_Contract(t *testing.T) {
+	testRevocationList(t, NewMemoryRevocationList())
+}
+
+func TestMemoryStores_Expire(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	nonces := &memoryNonces{seen: newExpiringMap[struct{}](), now: clock}
+	fresh, _ := nonces.Remember(ctx, "n", time.Minute)
+	assert.True(t, fresh)
+	now = now.Add(time.Minute)
+	fresh, _ = nonces.Remember(ctx, "n", time.Minute)
+	assert.True(t, fresh, "nonce usable again after its ttl")
+
+	limiter := &memoryLimiter{buckets: newExpiringMap[bucket](), now: clock}
+	lim := Limit{Rate: 2, Burst: 1}
+	allowed, _, _ := limiter.Allow(ctx, "k", lim)
+	assert.True(t, allowed)
+	allowed, retry, _ := limiter.Allow(ctx, "k", lim)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retry)
+	now = now.Add(retry)
+	allowed, _, _ = limiter.Allow(ctx, "k", lim)
+	assert.True(t, allowed, "refilled")
+
+	revoked := &memoryRevocations{jtis: newExpiringMap[struct{}](), subjects: map[string]int64{}, now: clock}
+	require.NoError(t, revoked.Revoke(ctx, "j", now.Add(time.Minute)))
+	now = now.Add(time.Minute)
+	ok, _ := revoked.IsRevoked(ctx, "j", "s", now.Add(-time.Hour))
+	assert.False(t, ok, "jti forgotten once the token expired")
+
+	m := newExpiringMap[int]()
+	for i := 0; i < minSweep-1; i++ {
+		m.put(uuid.NewString(), i, now.Add(time.Second), now)
+	}
+	now = now.Add(time.Second)
+	m.put("live", 1, time.Time{}, now)
+	assert.Len(t, m.items, 1, "expired entries swept")
+}
+
+func TestNewStores(t *testing.T) {
+	cfg := Config{Backend: BackendMemory, RedisAddr: "localhost:1"} // nothing listens there
+	_, err := NewNonceStore(cfg)
+	assert.NoError(t, err)
+	_, err = NewRateLimiter(cfg)
+	assert.NoError(t, err)
+	_, err = NewRevocationList(cfg)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+)
+
+const defaultSize = 10_000
+
+type memoryEntry struct {
+	key      string
+	val      []byte
+	expires  time.Time
+	deadline time.Time // what GetStatusTTL counts down to; expires or later
+}
+
+// memoryCache is a process-local VehicleCache holding at most size statuses.
+// The least recently used entry makes room for a new one and entries expire
+// after ttl, like the Redis keys do. Statuses are kept JSON-encoded so
+// callers get their own copy, exactly as from Redis.
+type memoryCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	size  int
+	order *list.List // front is the most recently used
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+// NewMemory returns an in-memory cache; zero size or ttl take the defaults.
+func NewMemory(size int, ttl time.Duration) VehicleCache {
+	if size <= 0 {
+		size = defaultSize
+	}
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	return &memoryCache{ttl: ttl, size: size, order: list.New(), items: map[string]*list.Element{}, now: time.Now}
+}
+
+func (c *memoryCache) GetStatus(ctx context.Context, id uuid.UUID) (*model.Status, error) {
+	st, _, err := c.GetStatusTTL(ctx, id)
+	return st, err
+}
+
+func (c *memoryCache) GetStatusTTL(ctx context.Context, id uuid.UUID) (*model.Status, time.Duration, error) {
+	val, left, ok := c.lookup(keyStatus(ctx, id))
+	if !ok {
+		return nil, 0, nil
+	}
+	var st model.Status
+	if err := json.Unmarshal(val, &st); err != nil {
+		return nil, 0, nil
+	}
+	return &st, left, nil
+}
+
+func (c *memoryCache) SetStatus(ctx context.Context, id uuid.UUID, s model.Status) error {
+	return c.put(ctx, id, s, c.ttl)
+}
+
+// put stores s for at most the cache's ttl, reporting it as good for left:
+// L1 copies of a shared entry keep the shared entry's deadline.
+func (c *memoryCache) put(ctx context.Context, id uuid.UUID, s model.Status, left time.Duration) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	c.store(keyStatus(ctx, id), b, left)
+	return nil
+}
+
+// lookup returns a live entry and how long it is good for.
+func (c *memoryCache) lookup(key string) ([]byte, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, 0, false
+	}
+	e := el.Value.(*memoryEntry)
+	now := c.now()
+	if !now.Before(e.expires) {
+		c.remove(el)
+		return nil, 0, false
+	}
+	c.order.MoveToFront(el)
+	return e.val, e.deadline.Sub(now), true
+}
+
+func (c *memoryCache) store(key string, val []byte, left time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	expires, deadline := now.Add(min(c.ttl, left)), now.Add(left)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.val, e.expires, e.deadline = val, expires, deadline
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&memoryEntry{key: key, val: val, expires: expires, deadline: deadline})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *memoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryEntry).key)
+}
+
+// drop forgets one key, whichever tenant it belongs to.
+func (c *memoryCache) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len reports how many entries are held, expired ones included until they
+// are next touched.
+func (c *memoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *memoryCache) TTL() time.Duration { return c.ttl }
+
+// Close drops every entry.
+func (c *memoryCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = map[string]*list.Element{}
+	return nil
+}
+
+// minSweep is how large an expiringMap grows before its first sweep.
+const minSweep = 1024
+
+type expiringItem[V any] struct {
+	val     V
+	expires time.Time // zero: never
+}
+
+// expiringMap backs the in-memory nonce store, rate limiter and revocation
+// list. Expired entries are ignored on read and swept whenever the map has
+// doubled since the last sweep, so memory follows the live keys. Callers
+// hold their own lock.
+type expiringMap[V any] struct {
+	items   map[string]expiringItem[V]
+	sweepAt int
+}
+
+func newExpiringMap[V any]() expiringMap[V] {
+	return expiringMap[V]{items: map[string]expiringItem[V]{}, sweepAt: minSweep}
+}
+
+func (m *expiringMap[V]) get(key string, now time.Time) (V, bool) {
+	it, ok := m.items[key]
+	if !ok || !it.expires.IsZero() && !now.Before(it.expires) {
+		var zero V
+		return zero, false
+	}
+	return it.val, true
+}
+
+func (m *expiringMap[V]) put(key string, val V, expires, now time.Time) {
+	m.items[key] = expiringItem[V]{val: val, expires: expires}
+	if len(m.items) < m.sweepAt {
+		return
+	}
+	for k, it := range m.items {
+		if !it.expires.IsZero() && !now.Before(it.expires) {
+			delete(m.items, k)
+		}
+	}
+	m.sweepAt = max(minSweep, 2*len(m.items))
+}
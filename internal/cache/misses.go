@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultMissTTL = 5 * time.Second
+
+// Misses remembers for a few seconds which vehicles had no status, so
+// lookups of unknown ids don't each reach the database. It is per process:
+// a status ingested on another replica shows up here once the entry
+// expires.
+type Misses struct {
+	c *memoryCache
+}
+
+// NewMisses holds at most size ids for ttl; zero values take the defaults.
+func NewMisses(size int, ttl time.Duration) *Misses {
+	if ttl == 0 {
+		ttl = defaultMissTTL
+	}
+	return &Misses{c: NewMemory(size, ttl).(*memoryCache)}
+}
+
+func (m *Misses) Add(ctx context.Context, id uuid.UUID) {
+	m.c.store(keyStatus(ctx, id), nil, m.c.ttl)
+}
+
+func (m *Misses) Has(ctx context.Context, id uuid.UUID) bool {
+	_, _, ok := m.c.lookup(keyStatus(ctx, id))
+	return ok
+}
+
+// Forget is called once the vehicle has a status.
+func (m *Misses) Forget(ctx context.Context, id uuid.UUID) {
+	m.c.drop(keyStatus(ctx, id))
+}
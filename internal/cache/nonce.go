@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const nonceKeyFormat = "device:nonce:%s"
+
+// NonceStore remembers request nonces long enough to reject replays.
+type NonceStore interface {
+	// Remember records nonce for ttl and reports whether it was unseen.
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+	Close() error
+}
+
+// NewNonceStore opens the nonce store that goes with cfg.Backend: in
+// process for the memory backend, else Redis.
+func NewNonceStore(cfg Config) (NonceStore, error) {
+	if cfg.Backend == BackendMemory {
+		return NewMemoryNonceStore(), nil
+	}
+	return NewRedisNonceStore(cfg.RedisAddr, "", 0)
+}
+
+type redisNonces struct {
+	rdb *redis.Client
+}
+
+// NewRedisNonceStore initialize
+func NewRedisNonceStore(addr string, password string, db int) (NonceStore, error) {
+	rdb, err := dial(addr, password, db)
+	if err != nil {
+		return nil, err
+	}
+	return &redisNonces{rdb: rdb}, nil
+}
+
+func (n *redisNonces) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return n.rdb.SetNX(ctx, fmt.Sprintf(nonceKeyFormat, nonce), 1, ttl).Result()
+}
+
+func (n *redisNonces) Close() error { return n.rdb.Close() }
+
+// memoryNonces is a process-local NonceStore: a replay sent to another
+// replica is not caught, so use it with one replica only.
+type memoryNonces struct {
+	mu   sync.Mutex
+	seen expiringMap[struct{}]
+	now  func() time.Time
+}
+
+// NewMemoryNonceStore returns an in-process NonceStore.
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonces{seen: newExpiringMap[struct{}](), now: time.Now}
+}
+
+func (n *memoryNonces) Remember(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+	if _, ok := n.seen.get(nonce, now); ok {
+		return false, nil
+	}
+	n.seen.put(nonce, struct{}{}, now.Add(ttl), now)
+	return true, nil
+}
+
+func (n *memoryNonces) Close() error { return nil }
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateKeyFormat = "ratelimit:%s"
+
+// Limit is a token bucket: Rate tokens are added per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter takes one token from the bucket identified by key.
+type RateLimiter interface {
+	// Allow reports whether the request may proceed and, if not, how long
+	// until a token will be available.
+	Allow(ctx context.Context, key string, l Limit) (bool, time.Duration, error)
+	Close() error
+}
+
+// tokenBucket refills lazily on each call. Time comes from the Redis server so
+// replicas with skewed clocks share one consistent bucket.
+var tokenBucket = redis.NewScript(`
+local rate  = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t     = redis.call('TIME')
+local now   = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state  = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts     = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed, retry = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, retry}
+`)
+
+// NewRateLimiter opens the rate limiter that goes with cfg.Backend: in
+// process for the memory backend, else Redis.
+func NewRateLimiter(cfg Config) (RateLimiter, error) {
+	if cfg.Backend == BackendMemory {
+		return NewMemoryRateLimiter(), nil
+	}
+	return NewRedisRateLimiter(cfg.RedisAddr, "", 0)
+}
+
+type redisLimiter struct {
+	rdb *redis.Client
+}
+
+// NewRedisRateLimiter initialize
+func NewRedisRateLimiter(addr string, password string, db int) (RateLimiter, error) {
+	rdb, err := dial(addr, password, db)
+	if err != nil {
+		return nil, err
+	}
+	return &redisLimiter{rdb: rdb}, nil
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, lim Limit) (bool, time.Duration, error) {
+	if lim.Rate <= 0 || lim.Burst <= 0 {
+		return true, 0, nil // unlimited
+	}
+	res, err := tokenBucket.Run(ctx, l.rdb, []string{fmt.Sprintf(rateKeyFormat, key)}, lim.Rate, lim.Burst).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func (l *redisLimiter) Close() error { return l.rdb.Close() }
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+// memoryLimiter runs the same token bucket as tokenBucket in process. Each
+// replica has its own buckets, so use it with one replica only.
+type memoryLimiter struct {
+	mu      sync.Mutex
+	buckets expiringMap[bucket]
+	now     func() time.Time
+}
+
+// NewMemoryRateLimiter returns an in-process RateLimiter.
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryLimiter{buckets: newExpiringMap[bucket](), now: time.Now}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, lim Limit) (bool, time.Duration, error) {
+	if lim.Rate <= 0 || lim.Burst <= 0 {
+		return true, 0, nil // unlimited
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets.get(key, now)
+	if !ok {
+		b = bucket{tokens: float64(lim.Burst), ts: now}
+	}
+	b.tokens = min(float64(lim.Burst), b.tokens+max(0, now.Sub(b.ts).Seconds())*lim.Rate)
+	b.ts = now
+
+	allowed, retry := false, time.Duration(0)
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retry = time.Duration(math.Ceil((1-b.tokens)/lim.Rate*1000)) * time.Millisecond
+	}
+	// like the PEXPIRE: a bucket idle long enough to be full again is dropped
+	full := time.Duration(float64(lim.Burst)/lim.Rate*float64(time.Second)) + time.Second
+	l.buckets.put(key, b, now.Add(full), now)
+	return allowed, retry, nil
+}
+
+func (l *memoryLimiter) Close() error { return nil }
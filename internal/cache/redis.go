@@ -7,13 +7,14 @@ import (
 	"time"
 
 	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 const (
 	defaultTTL      = 5 * time.Minute
-	statusKeyFormat = "vehicle:status:%s"
+	statusKeyFormat = "vehicle:status:%s:%s" // tenant, vehicle
 )
 
 // --- concrete type ----------------------------------------------------------
@@ -28,6 +29,15 @@ func NewRedis(addr string, password string, db int, ttl time.Duration) (VehicleC
 	if ttl == 0 {
 		ttl = defaultTTL
 	}
+	rdb, err := dial(addr, password, db)
+	if err != nil {
+		return nil, err
+	}
+	return &redisCache{rdb: rdb, ttl: ttl}, nil
+}
+
+// dial opens a client and fails fast when Redis is unreachable.
+func dial(addr string, password string, db int) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
@@ -35,17 +45,24 @@ func NewRedis(addr string, password string, db int, ttl time.Duration) (VehicleC
 	})
 
 	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		_ = rdb.Close()
 		return nil, err
 	}
-	return &redisCache{rdb: rdb, ttl: ttl}, nil
+	return rdb, nil
 }
 
-func keyStatus(id uuid.UUID) string {
-	return fmt.Sprintf(statusKeyFormat, id.String())
+// keyStatus includes the tenant so a vehicle id from another tenant never
+// hits this tenant's entry; system contexts use the default tenant's keys.
+func keyStatus(ctx context.Context, id uuid.UUID) string {
+	tid, ok := tenant.FromContext(ctx)
+	if !ok {
+		tid = tenant.Default
+	}
+	return fmt.Sprintf(statusKeyFormat, tid, id)
 }
 
 func (c *redisCache) GetStatus(ctx context.Context, id uuid.UUID) (*model.Status, error) {
-	val, err := c.rdb.Get(ctx, keyStatus(id)).Bytes()
+	val, err := c.rdb.Get(ctx, keyStatus(ctx, id)).Bytes()
 	if err == redis.Nil {
 		return nil, nil
 	}
@@ -54,18 +71,36 @@ func (c *redisCache) GetStatus(ctx context.Context, id uuid.UUID) (*model.Status
 	}
 	var st model.Status
 	if err := json.Unmarshal(val, &st); err != nil {
-		_ = c.rdb.Del(ctx, keyStatus(id)).Err()
+		_ = c.rdb.Del(ctx, keyStatus(ctx, id)).Err()
 		return nil, nil
 	}
 	return &st, nil
 }
 
+func (c *redisCache) GetStatusTTL(ctx context.Context, id uuid.UUID) (*model.Status, time.Duration, error) {
+	key := keyStatus(ctx, id)
+	pipe := c.rdb.Pipeline()
+	get, ttl := pipe.Get(ctx, key), pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err == redis.Nil {
+		return nil, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+	var st model.Status
+	if err := json.Unmarshal([]byte(get.Val()), &st); err != nil {
+		_ = c.rdb.Del(ctx, key).Err()
+		return nil, 0, nil
+	}
+	// PTTL is negative for a key without expiry (or gone meanwhile)
+	return &st, max(ttl.Val(), 0), nil
+}
+
 func (c *redisCache) SetStatus(ctx context.Context, id uuid.UUID, s model.Status) error {
 	b, err := json.Marshal(s)
 	if err != nil {
 		return err
 	}
-	return c.rdb.Set(ctx, keyStatus(id), b, c.ttl).Err()
+	return c.rdb.Set(ctx, keyStatus(ctx, id), b, c.ttl).Err()
 }
 
 // TTL exposes the configured expiration – handy for tests & metrics.
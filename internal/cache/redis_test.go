@@ -192,3 +192,30 @@ func TestRedisCache_SetStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisNonceStore_Contract(t *testing.T) {
+	nonces, err := NewRedisNonceStore("localhost:6379", "", 0)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer nonces.Close()
+	testNonceStore(t, nonces)
+}
+
+func TestRedisRateLimiter_Allow(t *testing.T) {
+	limiter, err := NewRedisRateLimiter("localhost:6379", "", 0)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer limiter.Close()
+	testRateLimiter(t, limiter)
+}
+
+func TestRedisRevocationList(t *testing.T) {
+	revoked, err := NewRedisRevocationList("localhost:6379", "", 0)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer revoked.Close()
+	testRevocationList(t, revoked)
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedJTIKeyFormat     = "auth:revoked:jti:%s"
+	revokedSubjectKeyFormat = "auth:revoked:sub:%s:%s" // tenant, subject
+)
+
+// RevocationList records access tokens that must no longer be accepted,
+// either one at a time by jti or every token of a subject issued before a
+// cutoff. Subjects are per tenant, taken from ctx.
+type RevocationList interface {
+	// Revoke rejects the token with this jti until it would have expired.
+	Revoke(ctx context.Context, jti string, expires time.Time) error
+	// RevokeSubject rejects every token of subject issued before at.
+	RevokeSubject(ctx context.Context, subject string, at time.Time) error
+	IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error)
+	Close() error
+}
+
+// NewRevocationList opens the revocation list that goes with cfg.Backend:
+// in process for the memory backend, else Redis.
+func NewRevocationList(cfg Config) (RevocationList, error) {
+	if cfg.Backend == BackendMemory {
+		return NewMemoryRevocationList(), nil
+	}
+	return NewRedisRevocationList(cfg.RedisAddr, "", 0)
+}
+
+type redisRevocations struct {
+	rdb *redis.Client
+}
+
+// NewRedisRevocationList initialize
+func NewRedisRevocationList(addr string, password string, db int) (RevocationList, error) {
+	rdb, err := dial(addr, password, db)
+	if err != nil {
+		return nil, err
+	}
+	return &redisRevocations{rdb: rdb}, nil
+}
+
+func keySubject(ctx context.Context, subject string) string {
+	tid, ok := tenant.FromContext(ctx)
+	if !ok {
+		tid = tenant.Default
+	}
+	return fmt.Sprintf(revokedSubjectKeyFormat, tid, subject)
+}
+
+func (r *redisRevocations) Revoke(ctx context.Context, jti string, expires time.Time) error {
+	ttl := time.Until(expires)
+	if jti == "" || ttl <= 0 {
+		return nil // nothing to remember: the token is already unusable
+	}
+	return r.rdb.Set(ctx, fmt.Sprintf(revokedJTIKeyFormat, jti), 1, ttl).Err()
+}
+
+// RevokeSubject keeps the cutoff without expiry: tokens minted with cmd/token
+// may be long-lived, and it is one key per user.
+func (r *redisRevocations) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	return r.rdb.Set(ctx, keySubject(ctx, subject), at.Unix(), 0).Err()
+}
+
+func (r *redisRevocations) IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	pipe := r.rdb.Pipeline()
+	var byJTI *redis.IntCmd
+	if jti != "" {
+		byJTI = pipe.Exists(ctx, fmt.Sprintf(revokedJTIKeyFormat, jti))
+	}
+	cutoff := pipe.Get(ctx, keySubject(ctx, subject))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return false, err
+	}
+	if byJTI != nil && byJTI.Val() > 0 {
+		return true, nil
+	}
+	at, err := cutoff.Int64()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return issuedAt.Unix() < at, nil
+}
+
+func (r *redisRevocations) Close() error { return r.rdb.Close() }
+
+// memoryRevocations is a process-local RevocationList. Revocations are lost
+// on restart and not seen by other replicas, so use it with one replica
+// only.
+type memoryRevocations struct {
+	mu       sync.Mutex
+	jtis     expiringMap[struct{}]
+	subjects map[string]int64 // keySubject -> cutoff, Unix seconds as in Redis
+	now      func() time.Time
+}
+
+// NewMemoryRevocationList returns an in-process RevocationList.
+func NewMemoryRevocationList() RevocationList {
+	return &memoryRevocations{jtis: newExpiringMap[struct{}](), subjects: map[string]int64{}, now: time.Now}
+}
+
+func (r *memoryRevocations) Revoke(_ context.Context, jti string, expires time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if jti == "" || !now.Before(expires) {
+		return nil
+	}
+	r.jtis.put(jti, struct{}{}, expires, now)
+	return nil
+}
+
+func (r *memoryRevocations) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subjects[keySubject(ctx, subject)] = at.Unix()
+	return nil
+}
+
+func (r *memoryRevocations) IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jtis.get(jti, r.now()); jti != "" && ok {
+		return true, nil
+	}
+	at, ok := r.subjects[keySubject(ctx, subject)]
+	return ok && issuedAt.Unix() < at, nil
+}
+
+func (r *memoryRevocations) Close() error { return nil }
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultL1TTL        = 2 * time.Second
+	invalidationChannel = "vehicle:status:invalidate"
+)
+
+// tierStats counts l1_hits, l1_misses, l2_hits, l2_misses, plus
+// invalidations received and failed publishes.
+var tierStats = expvar.NewMap("vehicle_cache")
+
+func init() {
+	ratios := expvar.NewMap("vehicle_cache_hit_ratio")
+	ratios.Set("l1", expvar.Func(func() any { return ratio("l1") }))
+	ratios.Set("l2", expvar.Func(func() any { return ratio("l2") }))
+}
+
+// ratio is hits/(hits+misses) of a tier, 0 before any lookup.
+func ratio(tier string) float64 {
+	hits, misses := counter(tier+"_hits"), counter(tier+"_misses")
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+func counter(name string) int64 {
+	if v, ok := tierStats.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// Invalidations tells the other replicas which keys changed.
+type Invalidations interface {
+	Publish(ctx context.Context, key string) error
+	// Listen calls drop for every key changed by another replica, and reset
+	// whenever messages may have been missed (on (re)connecting), until ctx
+	// is done.
+	Listen(ctx context.Context, drop func(key string), reset func())
+	Close() error
+}
+
+// tieredCache puts a small per-process L1 in front of a shared L2. Writes
+// go to L2, then to the local L1, and are announced so the other replicas
+// drop their L1 copy. Announcements can be lost, so L1 entries live only
+// l1TTL: that is how stale another replica's answer can be.
+type tieredCache struct {
+	l1     *memoryCache
+	l2     VehicleCache
+	bus    Invalidations
+	stop   context.CancelFunc
+	closed sync.WaitGroup
+}
+
+// NewTiered starts listening for invalidations; Close stops it and closes
+// l2 and bus. Zero l1Size or l1TTL take the defaults; L1 never keeps an
+// entry longer than L2 would.
+func NewTiered(l2 VehicleCache, bus Invalidations, l1Size int, l1TTL time.Duration) VehicleCache {
+	if l1TTL == 0 {
+		l1TTL = defaultL1TTL
+	}
+	l1TTL = min(l1TTL, l2.TTL())
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &tieredCache{l1: NewMemory(l1Size, l1TTL).(*memoryCache), l2: l2, bus: bus, stop: cancel}
+	t.closed.Add(1)
+	go func() {
+		defer t.closed.Done()
+		bus.Listen(ctx, func(key string) {
+			tierStats.Add("invalidations", 1)
+			t.l1.drop(key)
+		}, func() { _ = t.l1.Close() })
+	}()
+	return t
+}
+
+func (t *tieredCache) GetStatus(ctx context.Context, id uuid.UUID) (*model.Status, error) {
+	st, _, err := t.GetStatusTTL(ctx, id)
+	return st, err
+}
+
+// GetStatusTTL counts down to the L2 entry's expiry, also for L1 hits.
+func (t *tieredCache) GetStatusTTL(ctx context.Context, id uuid.UUID) (*model.Status, time.Duration, error) {
+	if st, left, _ := t.l1.GetStatusTTL(ctx, id); st != nil {
+		tierStats.Add("l1_hits", 1)
+		return st, left, nil
+	}
+	tierStats.Add("l1_misses", 1)
+	st, left, err := t.l2.GetStatusTTL(ctx, id)
+	if err != nil || st == nil {
+		if err == nil {
+			tierStats.Add("l2_misses", 1)
+		}
+		return nil, 0, err
+	}
+	tierStats.Add("l2_hits", 1)
+	if left > 0 {
+		_ = t.l1.put(ctx, id, *st, left)
+	}
+	return st, left, nil
+}
+
+// SetStatus fails only when L2 does; a lost announcement is logged and
+// outlived by at most the L1 TTL.
+func (t *tieredCache) SetStatus(ctx context.Context, id uuid.UUID, s model.Status) error {
+	key := keyStatus(ctx, id)
+	if err := t.l2.SetStatus(ctx, id, s); err != nil {
+		t.l1.drop(key)
+		return err
+	}
+	_ = t.l1.put(ctx, id, s, t.l2.TTL())
+	if err := t.bus.Publish(ctx, key); err != nil {
+		tierStats.Add("invalidation_errors", 1)
+		slog.Warn("cache invalidation not published", "key", key, "err", err)
+	}
+	return nil
+}
+
+// TTL is the shared tier's; L1 entries live for a fraction of it.
+func (t *tieredCache) TTL() time.Duration { return t.l2.TTL() }
+
+func (t *tieredCache) Close() error {
+	t.stop()
+	busErr := t.bus.Close()
+	t.closed.Wait()
+	if err := t.l2.Close(); err != nil {
+		return err
+	}
+	return busErr
+}
+
+// redisInvalidations broadcasts over Redis pub/sub. Messages are
+// "<node>|<key>" so a replica can skip its own.
+type redisInvalidations struct {
+	rdb  *redis.Client
+	node string
+}
+
+// NewRedisInvalidations initialize
+func NewRedisInvalidations(addr string, password string, db int) (Invalidations, error) {
+	rdb, err := dial(addr, password, db)
+	if err != nil {
+		return nil, err
+	}
+	return &redisInvalidations{rdb: rdb, node: uuid.NewString()}, nil
+}
+
+func (b *redisInvalidations) Publish(ctx context.Context, key string) error {
+	return b.rdb.Publish(ctx, invalidationChannel, b.node+"|"+key).Err()
+}
+
+func (b *redisInvalidations) Listen(ctx context.Context, drop func(string), reset func()) {
+	sub := b.rdb.Subscribe(ctx, invalidationChannel)
+	defer sub.Close()
+	// go-redis resubscribes after a lost connection and reports it with a
+	// fresh subscription message; whatever was published meanwhile is gone
+	msgs := sub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m, ok := <-msgs:
+			if !ok {
+				return
+			}
+			switch m := m.(type) {
+			case *redis.Subscription:
+				if m.Kind == "subscribe" {
+					reset()
+				}
+			case *redis.Message:
+				node, key, found := strings.Cut(m.Payload, "|")
+				if found && node != b.node {
+					drop(key)
+				}
+			}
+		}
+	}
+}
+
+func (b *redisInvalidations) Close() error { return b.rdb.Close() }
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hub is an in-process pub/sub that delivers synchronously.
+type hub struct {
+	mu    sync.Mutex
+	drops map[*hubBus]func(string)
+}
+
+type hubBus struct {
+	h     *hub
+	ready chan struct{}
+}
+
+func (h *hub) join() *hubBus { return &hubBus{h: h, ready: make(chan struct{})} }
+
+func (b *hubBus) Publish(_ context.Context, key string) error {
+	b.h.mu.Lock()
+	defer b.h.mu.Unlock()
+	for other, drop := range b.h.drops {
+		if other != b {
+			drop(key)
+		}
+	}
+	return nil
+}
+
+func (b *hubBus) Listen(ctx context.Context, drop func(string), reset func()) {
+	b.h.mu.Lock()
+	if b.h.drops == nil {
+		b.h.drops = map[*hubBus]func(string){}
+	}
+	b.h.drops[b] = drop
+	b.h.mu.Unlock()
+	reset()
+	close(b.ready)
+	<-ctx.Done()
+	b.h.mu.Lock()
+	delete(b.h.drops, b)
+	b.h.mu.Unlock()
+}
+
+func (b *hubBus) Close() error { return nil }
+
+// sharedL2 lets several tiered caches use one memory "Redis"; Close is
+// left to the test.
+type sharedL2 struct{ VehicleCache }
+
+func (sharedL2) Close() error { return nil }
+
+func TestTieredCache_Contract(t *testing.T) {
+	testVehicleCache(t, func(t *testing.T, ttl time.Duration) VehicleCache {
+		bus := (&hub{}).join()
+		c := NewTiered(NewMemory(100, ttl), bus, 10, time.Minute)
+		<-bus.ready
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	})
+}
+
+func TestTieredCache_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	l2 := sharedL2{NewMemory(100, time.Minute)}
+	h := &hub{}
+	busA, busB := h.join(), h.join()
+	a := NewTiered(l2, busA, 10, time.Minute)
+	b := NewTiered(l2, busB, 10, time.Minute)
+	defer a.Close()
+	defer b.Close()
+	<-busA.ready
+	<-busB.ready
+
+	id := uuid.New()
+	require.NoError(t, a.SetStatus(ctx, id, model.Status{Speed: 1}))
+
+	before := counter("l2_hits")
+	st, err := b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, st)
+	assert.Equal(t, 1.0, st.Speed)
+	assert.Equal(t, before+1, counter("l2_hits"), "b filled its L1 from L2")
+
+	before = counter("l1_hits")
+	st, err = b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, st.Speed)
+	assert.Equal(t, before+1, counter("l1_hits"), "served from b's L1")
+
+	// a's write drops b's L1 copy, so b doesn't serve the old speed
+	require.NoError(t, a.SetStatus(ctx, id, model.Status{Speed: 2}))
+	st, err = b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, st.Speed)
+
+	assert.Greater(t, ratio("l1"), 0.0)
+	assert.LessOrEqual(t, ratio("l1"), 1.0)
+}
+
+func TestTieredCache_StaleBound(t *testing.T) {
+	ctx := context.Background()
+	l2 := sharedL2{NewMemory(100, time.Minute)}
+	lost := &hub{} // b never hears from a
+	busA, busB := lost.join(), (&hub{}).join()
+	a := NewTiered(l2, busA, 10, time.Minute)
+	b := NewTiered(l2, busB, 10, 100*time.Millisecond)
+	defer a.Close()
+	defer b.Close()
+	<-busA.ready
+	<-busB.ready
+
+	id := uuid.New()
+	require.NoError(t, a.SetStatus(ctx, id, model.Status{Speed: 1}))
+	_, err := b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	require.NoError(t, a.SetStatus(ctx, id, model.Status{Speed: 2}))
+
+	st, err := b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, st.Speed, "stale while the L1 entry lives")
+	time.Sleep(150 * time.Millisecond)
+	st, err = b.GetStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, st.Speed, "fresh once it expired")
+}
+
+func TestTieredCache_TTLFollowsL2(t *testing.T) {
+	ctx := context.Background()
+	l2 := sharedL2{NewMemory(100, time.Minute)}
+	a := NewTiered(l2, (&hub{}).join(), 10, time.Second)
+	b := NewTiered(l2, (&hub{}).join(), 10, time.Second)
+	defer a.Close()
+	defer b.Close()
+
+	id := uuid.New()
+	require.NoError(t, a.SetStatus(ctx, id, model.Status{Speed: 1}))
+	for name, c := range map[string]VehicleCache{"writer": a, "reader": b} {
+		for i := 0; i < 2; i++ { // L2 hit, then L1 hit
+			_, left, err := c.GetStatusTTL(ctx, id)
+			require.NoError(t, err)
+			assert.Greater(t, left, 50*time.Second, name)
+		}
+	}
+}
+
+func TestRedisInvalidations(t *testing.T) {
+	a, err := NewRedisInvalidations("localhost:6379", "", 0)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer a.Close()
+	b, err := NewRedisInvalidations("localhost:6379", "", 0)
+	require.NoError(t, err)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscribed := make(chan struct{}, 1)
+	dropped := make(chan string, 10)
+	go b.Listen(ctx, func(key string) { dropped <- key }, func() { subscribed <- struct{}{} })
+	go a.Listen(ctx, func(key string) { t.Errorf("a heard its own key %q", key) }, func() {})
+	select {
+	case <-subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription")
+	}
+
+	key := "vehicle:status:test:" + uuid.NewString()
+	require.NoError(t, a.Publish(ctx, key))
+	select {
+	case got := <-dropped:
+		assert.Equal(t, key, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalidation not delivered")
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateAccessGrantHandler lets a restricted subject see one vehicle or a
+// group subtree; from defaults to now and a missing to never expires.
+func CreateAccessGrantHandler(svc service.AccessService) gin.HandlerFunc {
+	type request struct {
+		Subject   string     `json:"subject" binding:"required"`
+		VehicleID *uuid.UUID `json:"vehicle_id"`
+		GroupID   *uuid.UUID `json:"group_id"`
+		From      *time.Time `json:"from"`
+		To        *time.Time `json:"to"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		g := model.AccessGrant{Subject: req.Subject, VehicleID: req.VehicleID, GroupID: req.GroupID, To: req.To}
+		if req.From != nil {
+			g.From = *req.From
+		}
+		g, err := svc.Grant(c, g)
+		if err != nil {
+			accessError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, g)
+	}
+}
+
+// ListAccessGrantsHandler lists every grant, or those of ?subject=.
+func ListAccessGrantsHandler(svc service.AccessService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gs, err := svc.List(c, c.Query("subject"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gs)
+	}
+}
+
+func DeleteAccessGrantHandler(svc service.AccessService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		if err := svc.Revoke(c, id); err != nil {
+			accessError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func accessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidGrant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAuditHandler supports actor, action, resource (path prefix),
+// resource_id, from/to (RFC 3339), before (seq, to page back) and limit.
+func ListAuditHandler(svc service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f := repository.AuditFilter{
+			Actor:      c.Query("actor"),
+			Action:     c.Query("action"),
+			Resource:   c.Query("resource"),
+			ResourceID: c.Query("resource_id"),
+			Limit:      100,
+		}
+		for param, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+			if s := c.Query(param); s != "" {
+				ts, err := time.Parse(time.RFC3339, s)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "bad " + param})
+					return
+				}
+				*dst = ts
+			}
+		}
+		if s := c.Query("before"); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "bad before"})
+				return
+			}
+			f.Before = n
+		}
+		if s := c.Query("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > 1000 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be 1-1000"})
+				return
+			}
+			f.Limit = n
+		}
+		es, err := svc.Query(c, f)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, es)
+	}
+}
+
+// VerifyAuditHandler walks the caller's tenant chain and reports the first
+// broken link, if any.
+func VerifyAuditHandler(svc service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rep, err := svc.Verify(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, rep)
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IssueCredentialHandler creates a device credential; the secret is only returned here.
+func IssueCredentialHandler(svc service.CredentialService) gin.HandlerFunc {
+	type request struct {
+		VehicleID uuid.UUID  `json:"vehicle_id"`
+		DeviceID  *uuid.UUID `json:"device_id"`
+		Kind      string     `json:"kind"`
+		Label     string     `json:"label"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		cred, secret, err := svc.Issue(c, req.VehicleID, req.DeviceID, req.Kind, req.Label)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCredentialKind) || errors.Is(err, service.ErrInvalidCredentialBinding) ||
+				errors.Is(err, service.ErrHMACDisabled) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"credential": cred, "secret": secret})
+	}
+}
+
+// ListCredentialsHandler lists by vehicle (?id=) or by device (?device_id=).
+func ListCredentialsHandler(svc service.CredentialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, param := svc.List, "id"
+		if c.Query("device_id") != "" {
+			list, param = svc.ListByDevice, "device_id"
+		}
+		id, err := uuid.Parse(c.Query(param))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		creds, err := list(c, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, creds)
+	}
+}
+
+func RevokeCredentialHandler(svc service.CredentialService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Revoke(c, c.Param("key_id")); err != nil {
+			if errors.Is(err, service.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
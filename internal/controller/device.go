@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateDeviceHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var p model.DevicePatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		d, err := svc.Create(c, p)
+		if err != nil {
+			deviceError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, d)
+	}
+}
+
+// ListDevicesHandler pages with limit (default 100, max 1000) and offset.
+func ListDevicesHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit < 1 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be 1-1000"})
+			return
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad offset"})
+			return
+		}
+		ds, err := svc.List(c, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, ds)
+	}
+}
+
+func GetDeviceHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		d, err := svc.Get(c, id)
+		if err != nil {
+			deviceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+func UpdateDeviceHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var p model.DevicePatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		d, err := svc.Update(c, id, p)
+		if err != nil {
+			deviceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+// AssignDeviceHandler installs a device in a vehicle; "from" defaults to now
+// and may be in the past to record a swap after the fact.
+func AssignDeviceHandler(svc service.DeviceService) gin.HandlerFunc {
+	type request struct {
+		VehicleID uuid.UUID `json:"vehicle_id" binding:"required"`
+		From      time.Time `json:"from"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		a, err := svc.Assign(c, id, req.VehicleID, req.From)
+		if err != nil {
+			deviceError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, a)
+	}
+}
+
+// UnassignDeviceHandler ends the current assignment; "at" defaults to now.
+func UnassignDeviceHandler(svc service.DeviceService) gin.HandlerFunc {
+	type request struct {
+		At time.Time `json:"at"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := svc.Unassign(c, id, req.At); err != nil {
+			deviceError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func DeviceAssignmentsHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		as, err := svc.Assignments(c, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, as)
+	}
+}
+
+// VehicleDevicesHandler lists the devices a vehicle has carried, newest first.
+func VehicleDevicesHandler(svc service.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		as, err := svc.VehicleAssignments(c, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, as)
+	}
+}
+
+func deviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidDevice):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateDriverHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var p model.DriverPatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		d, err := svc.Create(c, p)
+		if err != nil {
+			driverError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, d)
+	}
+}
+
+// ListDriversHandler pages with limit (default 100, max 1000) and offset.
+func ListDriversHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit < 1 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be 1-1000"})
+			return
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad offset"})
+			return
+		}
+		ds, err := svc.List(c, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, ds)
+	}
+}
+
+func GetDriverHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		d, err := svc.Get(c, id)
+		if err != nil {
+			driverError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+func UpdateDriverHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var p model.DriverPatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		d, err := svc.Update(c, id, p)
+		if err != nil {
+			driverError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+// AssignDriverHandler starts a shift; "from" defaults to now and "to" may set
+// a planned end. Any shift the driver or the vehicle is on ends at "from".
+func AssignDriverHandler(svc service.DriverService) gin.HandlerFunc {
+	type request struct {
+		VehicleID uuid.UUID  `json:"vehicle_id" binding:"required"`
+		From      time.Time  `json:"from"`
+		To        *time.Time `json:"to"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		a, err := svc.Assign(c, id, req.VehicleID, req.From, req.To)
+		if err != nil {
+			driverError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, a)
+	}
+}
+
+// UnassignDriverHandler ends the driver's shift; "at" defaults to now.
+func UnassignDriverHandler(svc service.DriverService) gin.HandlerFunc {
+	type request struct {
+		At time.Time `json:"at"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := svc.Unassign(c, id, req.At); err != nil {
+			driverError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func DriverAssignmentsHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		as, err := svc.Assignments(c, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, as)
+	}
+}
+
+// DriverTripsHandler lists the trips attributed to a driver in the last 24 h.
+func DriverTripsHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		tr, err := svc.Trips(c, id, 24*time.Hour)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, tr)
+	}
+}
+
+// VehicleDriversHandler lists the shifts driven in a vehicle, newest first.
+func VehicleDriversHandler(svc service.DriverService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		as, err := svc.VehicleAssignments(c, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, as)
+	}
+}
+
+func driverError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidDriver):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, model.ErrLicenceExpired):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateGroupHandler(svc service.GroupService) gin.HandlerFunc {
+	type request struct {
+		Name     string     `json:"name" binding:"required"`
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		g, err := svc.Create(c, req.Name, req.ParentID)
+		if err != nil {
+			groupError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, g)
+	}
+}
+
+// ListGroupsHandler returns the whole tree, or one subtree with ?under=<id>,
+// in path order so parents come before their children.
+func ListGroupsHandler(svc service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if under := c.Query("under"); under != "" {
+			id, err := uuid.Parse(under)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+				return
+			}
+			gs, err := svc.Subtree(c, id)
+			if err != nil {
+				groupError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, gs)
+			return
+		}
+		gs, err := svc.List(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gs)
+	}
+}
+
+func GetGroupHandler(svc service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		g, err := svc.Get(c, id)
+		if err != nil {
+			groupError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, g)
+	}
+}
+
+func RenameGroupHandler(svc service.GroupService) gin.HandlerFunc {
+	type request struct {
+		Name string `json:"name" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		g, err := svc.Rename(c, id, req.Name)
+		if err != nil {
+			groupError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, g)
+	}
+}
+
+// MoveGroupHandler re-parents a group with its subtree; a null parent_id makes it a root.
+func MoveGroupHandler(svc service.GroupService) gin.HandlerFunc {
+	type request struct {
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		g, err := svc.Move(c, id, req.ParentID)
+		if err != nil {
+			groupError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, g)
+	}
+}
+
+func DeleteGroupHandler(svc service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		if err := svc.Delete(c, id); err != nil {
+			groupError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GroupVehiclesHandler lists members; ?recursive=true includes the subtree.
+func GroupVehiclesHandler(svc service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		vs, err := svc.Vehicles(c, id, c.Query("recursive") == "true")
+		if err != nil {
+			groupError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, vs)
+	}
+}
+
+func AddGroupVehiclesHandler(svc service.GroupService) gin.HandlerFunc {
+	type request struct {
+		VehicleIDs []uuid.UUID `json:"vehicle_ids" binding:"required,min=1,max=1000"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := svc.AddVehicles(c, id, req.VehicleIDs); err != nil {
+			groupError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func RemoveGroupVehicleHandler(svc service.GroupService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		vehicleID, err := uuid.Parse(c.Param("vehicle_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		if err := svc.RemoveVehicle(c, id, vehicleID); err != nil {
+			groupError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func groupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidGroup):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
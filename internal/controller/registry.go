@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/vin"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateVehicleHandler registers a vehicle; "id" is optional.
+func CreateVehicleHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	type request struct {
+		ID uuid.UUID `json:"id"`
+		model.VehiclePatch
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		v, err := svc.Create(c, req.ID, req.VehiclePatch)
+		if err != nil {
+			registryError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, v)
+	}
+}
+
+// ListVehiclesHandler supports tag, make, fuel_type, archived=true, limit and offset.
+func ListVehiclesHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f := repository.VehicleFilter{
+			Tag:             c.Query("tag"),
+			Make:            c.Query("make"),
+			FuelType:        c.Query("fuel_type"),
+			IncludeArchived: c.Query("archived") == "true",
+			Limit:           100,
+		}
+		if s := c.Query("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > 1000 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be 1-1000"})
+				return
+			}
+			f.Limit = n
+		}
+		if s := c.Query("offset"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "bad offset"})
+				return
+			}
+			f.Offset = n
+		}
+		vs, err := svc.List(c, f)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, vs)
+	}
+}
+
+func GetVehicleHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		v, err := svc.Get(c, id)
+		if err != nil {
+			registryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, v)
+	}
+}
+
+// UpdateVehicleHandler applies a partial update; omitted fields keep their value.
+func UpdateVehicleHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var p model.VehiclePatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		v, err := svc.Update(c, id, p)
+		if err != nil {
+			registryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, v)
+	}
+}
+
+func ArchiveVehicleHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	return setArchivedHandler(svc.Archive)
+}
+
+func RestoreVehicleHandler(svc service.VehicleRegistry) gin.HandlerFunc {
+	return setArchivedHandler(svc.Restore)
+}
+
+func setArchivedHandler(fn func(context.Context, uuid.UUID) (model.Vehicle, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		v, err := fn(c, id)
+		if err != nil {
+			registryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, v)
+	}
+}
+
+// DecodeVINHandler decodes a VIN offline so clients can pre-fill a registration form.
+func DecodeVINHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info, err := vin.Decode(c.Param("vin"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, info)
+	}
+}
+
+func registryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidVehicle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
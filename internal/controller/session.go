@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// StartSessionHandler exchanges a long-lived API token for a short-lived
+// access token and a refresh token carrying the same subject, tenant and
+// grants.
+func StartSessionHandler(svc service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet(middleware.ContextClaims).(auth.Claims)
+		pair, err := svc.Start(c, claims)
+		if err != nil {
+			if errors.Is(err, service.ErrNotSessionSource) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, pair)
+	}
+}
+
+// RefreshSessionHandler needs no bearer token: the refresh token is the credential.
+func RefreshSessionHandler(svc service.SessionService) gin.HandlerFunc {
+	type request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		pair, err := svc.Refresh(c, req.RefreshToken)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidRefreshToken) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+func LogoutHandler(svc service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet(middleware.ContextClaims).(auth.Claims)
+		if err := svc.Logout(c, claims); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RevokeAllHandler signs the caller, or with access:manage any other
+// subject of the tenant, out everywhere.
+func RevokeAllHandler(svc service.SessionService) gin.HandlerFunc {
+	type request struct {
+		Subject string `json:"subject"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		self := c.GetString("user")
+		if req.Subject == "" {
+			req.Subject = self
+		}
+		if req.Subject != self {
+			grants, _ := c.MustGet(middleware.ContextGrants).(auth.Grants)
+			if !grants.Has(auth.ScopeAccessManage) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "missing scope " + auth.ScopeAccessManage})
+				return
+			}
+		}
+		if err := svc.RevokeAll(c, req.Subject); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateShareLinkHandler issues a link to follow the vehicle during
+// [from, to); from defaults to now. The token is only returned here.
+func CreateShareLinkHandler(svc service.ShareService) gin.HandlerFunc {
+	type request struct {
+		Label    string     `json:"label"`
+		From     *time.Time `json:"from"`
+		To       time.Time  `json:"to" binding:"required"`
+		CoarsenM int        `json:"coarsen_m"`
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		l := model.ShareLink{Label: req.Label, To: req.To, CoarsenM: req.CoarsenM}
+		if req.From != nil {
+			l.From = *req.From
+		}
+		l, token, err := svc.Create(c, id, l)
+		if err != nil {
+			shareError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"link": l, "token": token, "path": "/share/" + token})
+	}
+}
+
+func ListShareLinksHandler(svc service.ShareService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		ls, err := svc.List(c, id)
+		if err != nil {
+			shareError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ls)
+	}
+}
+
+func RevokeShareLinkHandler(svc service.ShareService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		linkID, err := uuid.Parse(c.Param("link_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		if err := svc.Revoke(c, id, linkID); err != nil {
+			shareError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ViewShareHandler is the public, unauthenticated side of a share link.
+func ViewShareHandler(svc service.ShareService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		view, err := svc.View(c, c.Param("token"))
+		if err != nil {
+			shareError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+func shareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidShareLink):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrShareLinkGone):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrShareLinkNotStarted):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LoginHandler trades email and password (plus a TOTP code once enrolled)
+// for a token pair. tenant may be omitted for the default tenant.
+func LoginHandler(svc service.UserService) gin.HandlerFunc {
+	type request struct {
+		Tenant   string `json:"tenant"`
+		Email    string `json:"email"    binding:"required"`
+		Password string `json:"password" binding:"required"`
+		TOTP     string `json:"totp"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tid, err := tenant.Parse(req.Tenant)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad tenant"})
+			return
+		}
+		// on the request, not just the call, so the audit entry lands in this
+		// tenant's chain
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), tid))
+		pair, err := svc.Login(c.Request.Context(), req.Email, req.Password, req.TOTP)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, pair)
+	}
+}
+
+// ResetPasswordHandler sets a new password with a token from
+// IssuePasswordResetHandler; no bearer token needed.
+func ResetPasswordHandler(svc service.UserService) gin.HandlerFunc {
+	type request struct {
+		Token    string `json:"token"    binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := svc.ResetPassword(c, req.Token, req.Password); err != nil {
+			userError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SetupTOTPHandler starts second-factor enrolment for the calling user.
+func SetupTOTPHandler(svc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := callerID(c)
+		if !ok {
+			return
+		}
+		enrolment, err := svc.SetupTOTP(c, id)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, enrolment)
+	}
+}
+
+// EnableTOTPHandler and DisableTOTPHandler take {"code": "123456"}.
+func EnableTOTPHandler(svc service.UserService) gin.HandlerFunc {
+	return totpCodeHandler(svc.EnableTOTP)
+}
+
+func DisableTOTPHandler(svc service.UserService) gin.HandlerFunc {
+	return totpCodeHandler(svc.DisableTOTP)
+}
+
+func totpCodeHandler(apply func(ctx context.Context, id uuid.UUID, code string) error) gin.HandlerFunc {
+	type request struct {
+		Code string `json:"code" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		id, ok := callerID(c)
+		if !ok {
+			return
+		}
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := apply(c, id, req.Code); err != nil {
+			userError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// callerID is the user behind the bearer token; tokens minted for other
+// subjects (cmd/token, devices) have no account.
+func callerID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString("user"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "token subject is not a user"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func CreateUserHandler(svc service.UserService) gin.HandlerFunc {
+	type request struct {
+		model.UserPatch
+		Password string `json:"password" binding:"required"`
+	}
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		u, err := svc.Create(c, req.UserPatch, req.Password)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, u)
+	}
+}
+
+func ListUsersHandler(svc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		users, err := svc.List(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, users)
+	}
+}
+
+func GetUserHandler(svc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		u, err := svc.Get(c, id)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, u)
+	}
+}
+
+func UpdateUserHandler(svc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		var p model.UserPatch
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		u, err := svc.Update(c, id, p)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, u)
+	}
+}
+
+// IssuePasswordResetHandler returns a reset token for an admin to pass on;
+// there is no mail delivery.
+func IssuePasswordResetHandler(svc service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		token, expires, err := svc.IssueReset(c, id)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"token": token, "expires_at": expires})
+	}
+}
+
+func userError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidUser):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTOTPRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "totp_required": true})
+	case errors.Is(err, service.ErrInvalidLogin), errors.Is(err, service.ErrInvalidTOTP),
+		errors.Is(err, service.ErrInvalidResetToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTOTPNotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
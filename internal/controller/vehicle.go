@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
 	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/obd"
 	"github.com/aditi2420/fleet-tracker/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,67 +18,222 @@ type VehicleController struct {
 	VehicleService service.VehicleService
 }
 
-func GetStatusHandler1(svc service.VehicleService) gin.HandlerFunc {
+// GetStatusHandler returns one vehicle's status (?id=) or, with ?group_id=,
+// the statuses of every vehicle in the group's subtree.
+func GetStatusHandler(svc service.VehicleService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.Query("group_id") != "" {
+			groupStatuses(c, svc)
+			return
+		}
 		id, err := uuid.Parse(c.Query("id"))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
 			return
 		}
+		user := c.GetString("user")
 		st, err := svc.CurrentStatus(c, id)
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no status for vehicle"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, st)
+		c.JSON(http.StatusOK, gin.H{"status": st, "user": user})
 	}
 }
 
-func GetStatusHandler(svc service.VehicleService) gin.HandlerFunc {
+func groupStatuses(c *gin.Context, svc service.VehicleService) {
+	id, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+		return
+	}
+	sts, err := svc.GroupStatuses(c, id)
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown group"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"statuses": sts, "user": c.GetString("user")})
+}
+
+// GetTripsHandler lists the last 24h of trips for one vehicle (?id=) or for
+// a group's whole subtree (?group_id=).
+func GetTripsHandler(svc service.VehicleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list := svc.ListTrips
+		param, what := "id", "vehicle"
+		if c.Query("group_id") != "" {
+			list, param, what = svc.GroupTrips, "group_id", "group"
+		}
+		id, err := uuid.Parse(c.Query(param))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		tr, err := list(c, id, 24*time.Hour)
+		if err != nil {
+			readError(c, err, what)
+			return
+		}
+		c.JSON(http.StatusOK, tr)
+	}
+}
+
+// GetHistoryHandler returns stored fixes; from/to are RFC 3339 and default to the last 24h.
+func GetHistoryHandler(svc service.VehicleService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := uuid.Parse(c.Query("id"))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
 			return
 		}
-		user := c.GetString("user")
-		st, err := svc.CurrentStatus(c, id)
+		from, to, limit, ok := parseWindow(c)
+		if !ok {
+			return
+		}
+		hist, err := svc.History(c, id, from, to, limit)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			readError(c, err, "vehicle")
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"status": st, "user": user})
+		c.JSON(http.StatusOK, hist)
 	}
 }
 
-func GetTripsHandler(svc service.VehicleService) gin.HandlerFunc {
+// GetSignalsHandler returns decoded OBD/J1939 samples, optionally for one signal name.
+func GetSignalsHandler(svc service.VehicleService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := uuid.Parse(c.Query("id"))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
 			return
 		}
-		tr, err := svc.ListTrips(c, id, 24*time.Hour)
+		from, to, limit, ok := parseWindow(c)
+		if !ok {
+			return
+		}
+		samples, err := svc.Signals(c, id, c.Query("name"), from, to, limit)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			readError(c, err, "vehicle")
 			return
 		}
-		c.JSON(http.StatusOK, tr)
+		c.JSON(http.StatusOK, samples)
 	}
 }
 
-func IngestHandler(svc service.VehicleService) gin.HandlerFunc {
-	type payload struct {
-		VehicleID uuid.UUID    `json:"vehicle_id"`
-		Status    model.Status `json:"status"`
+func GetDTCsHandler(svc service.VehicleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Query("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		dtcs, err := svc.ActiveDTCs(c, id)
+		if err != nil {
+			readError(c, err, "vehicle")
+			return
+		}
+		c.JSON(http.StatusOK, dtcs)
+	}
+}
+
+func GetEventsHandler(svc service.VehicleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Query("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad uuid"})
+			return
+		}
+		ev, err := svc.Events(c, id, 24*time.Hour)
+		if err != nil {
+			readError(c, err, "vehicle")
+			return
+		}
+		c.JSON(http.StatusOK, ev)
 	}
+}
+
+// readError answers ErrNotFound with 404 naming what wasn't found, anything
+// else with 500. Restricted callers get the 404 for vehicles not granted to
+// them too.
+func readError(c *gin.Context, err error, what string) {
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown " + what})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// parseWindow reads the optional from/to/limit query params shared by the
+// time-series endpoints. It writes the 400 itself and reports ok=false.
+func parseWindow(c *gin.Context) (from, to time.Time, limit int, ok bool) {
+	var err error
+	to = time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad to"})
+			return
+		}
+	}
+	from = to.Add(-24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad from"})
+			return
+		}
+	}
+	limit = 1000
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > 10000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad limit"})
+			return
+		}
+	}
+	return from, to, limit, true
+}
+
+func IngestHandler(svc service.VehicleService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var p model.InputRequestPayload
 		if err := c.BindJSON(&p); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		if err := svc.Ingest(c, p.VehicleID, p.PlateNumber, p.Status); err != nil {
+		// a device credential may only report for the vehicle it is bound to
+		if bound, ok := c.Get(middleware.ContextDeviceVehicle); ok && bound.(uuid.UUID) != p.VehicleID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "device is not bound to this vehicle"})
+			return
+		}
+		// ... or, when bound to a device, only as that device
+		if dev, ok := c.Get(middleware.ContextDevice); ok {
+			id := dev.(uuid.UUID)
+			if p.DeviceID != nil && *p.DeviceID != id {
+				c.JSON(http.StatusForbidden, gin.H{"error": "credential is bound to another device"})
+				return
+			}
+			p.DeviceID, p.IMEI = &id, ""
+		}
+		if err := svc.Ingest(c, p); err != nil {
+			if errors.Is(err, model.ErrInvalidStatus) || errors.Is(err, obd.ErrMalformed) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, service.ErrUnknownDevice) || errors.Is(err, service.ErrDeviceUnassigned) ||
+				errors.Is(err, service.ErrDeviceMismatch) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, service.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "unknown vehicle"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
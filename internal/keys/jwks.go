@@ -0,0 +1,214 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWK is the subset of RFC 7517 needed for RSA and P-256 public keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at a JWKS URL.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK describes pub as a signing JWK with the given kid.
+func NewJWK(kid string, pub crypto.PublicKey) (JWK, error) {
+	alg, err := Alg(pub)
+	if err != nil {
+		return JWK{}, err
+	}
+	k := JWK{Kid: kid, Use: "sig", Alg: alg}
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = b64.EncodeToString(p.N.Bytes())
+		k.E = b64.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+	case *ecdsa.PublicKey:
+		k.Kty, k.Crv = "EC", "P-256"
+		k.X = b64.EncodeToString(p.X.FillBytes(make([]byte, 32)))
+		k.Y = b64.EncodeToString(p.Y.FillBytes(make([]byte, 32)))
+	}
+	return k, nil
+}
+
+// PublicKey decodes the key material.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: n: %w", k.Kid, err)
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: e: %w", k.Kid, err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 || exp.Int64() < 3 {
+			return nil, fmt.Errorf("jwk %q: bad exponent", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("%w: curve %q", ErrKeyType, k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: x: %w", k.Kid, err)
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: y: %w", k.Kid, err)
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("jwk %q: point not on curve", k.Kid)
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("%w: kty %q", ErrKeyType, k.Kty)
+}
+
+// JWKS fetches keys from a URL and caches them for TTL. A kid that is not in
+// the cache triggers a refetch (at most once per MinRefresh, also while no
+// fetch has succeeded yet) so keys published for a rotation are picked up
+// before the cache expires. Fetches run in the background, one at a time:
+// callers holding a cached key, stale or not, never wait for the network,
+// and when a refresh fails the previous keys keep being served.
+type JWKS struct {
+	URL        string
+	TTL        time.Duration
+	MinRefresh time.Duration
+	Client     *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	err     error // of the last fetch
+	fetched time.Time
+	tried   time.Time
+	pending chan struct{} // closed when the running fetch is done
+	now     func() time.Time
+}
+
+// NewJWKS returns a JWKS set for url caching keys for ttl.
+func NewJWKS(url string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		URL:        url,
+		TTL:        ttl,
+		MinRefresh: 30 * time.Second,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+func (s *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	now := s.now()
+	k, ok := s.keys[kid]
+	if ok && now.Sub(s.fetched) < s.TTL {
+		s.mu.Unlock()
+		return k, nil
+	}
+	if s.pending == nil && now.Sub(s.tried) >= s.MinRefresh {
+		s.tried = now
+		s.pending = make(chan struct{})
+		go s.refresh(now, s.pending)
+	}
+	pending := s.pending
+	s.mu.Unlock()
+
+	if ok {
+		return k, nil // stale; the refresh replaces it
+	}
+	if pending != nil {
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+	if s.keys == nil && s.err != nil {
+		return nil, s.err
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// refresh fetches the set on behalf of every caller, so it isn't tied to
+// any one caller's context; the client timeout bounds it.
+func (s *JWKS) refresh(started time.Time, done chan struct{}) {
+	keys, err := s.fetch(context.Background())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	switch {
+	case err == nil:
+		s.keys, s.fetched = keys, started
+	case s.keys != nil:
+		slog.Warn("jwks refresh failed, serving cached keys", "url", s.URL, "err", err)
+	}
+	s.pending = nil
+	close(done)
+}
+
+func (s *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: %s answered %s", s.URL, resp.Status)
+	}
+	var set JWKSet
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.PublicKey()
+		if err != nil {
+			slog.Warn("jwks: skipping key", "kid", k.Kid, "err", err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
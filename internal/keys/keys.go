@@ -0,0 +1,198 @@
+// Package keys loads the asymmetric keys access tokens are signed and
+// verified with. Keys are identified by the JWT "kid" header so several can
+// be valid at once while one is being rotated out.
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Supported signing algorithms.
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown key id")
+	ErrKeyType    = errors.New("unsupported key type")
+)
+
+// Set resolves a kid to the public key tokens carrying it are verified with.
+type Set interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Static is a fixed kid → key map, typically loaded from PEM files.
+type Static map[string]crypto.PublicKey
+
+func (s Static) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	if k, ok := s[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// Chain asks each set in turn and returns the first key found.
+type Chain []Set
+
+func (c Chain) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	err := fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	for _, s := range c {
+		k, e := s.Key(ctx, kid)
+		if e == nil {
+			return k, nil
+		}
+		if !errors.Is(e, ErrUnknownKey) {
+			err = e
+		}
+	}
+	return nil, err
+}
+
+// Alg returns the JWT algorithm a key signs or verifies with: RS256 for RSA,
+// ES256 for ECDSA on P-256.
+func Alg(key any) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return RS256, nil
+	case *rsa.PrivateKey:
+		return RS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return ES256, nil
+		}
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return ES256, nil
+		}
+	}
+	return "", fmt.Errorf("%w %T", ErrKeyType, key)
+}
+
+// Generate creates a private key for alg (RS256: RSA 2048, ES256: P-256).
+func Generate(alg string) (crypto.Signer, error) {
+	switch alg {
+	case RS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	return nil, fmt.Errorf("%w: algorithm %q", ErrKeyType, alg)
+}
+
+// LoadFiles parses "kid=path.pem,kid=path.pem" (the JWT_PUBLIC_KEYS format)
+// into a Static set. Each file holds a PKIX public key or a certificate.
+func LoadFiles(spec string) (Static, error) {
+	res := Static{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("public key %q: want kid=path", entry)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ParsePublicPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("public key %q: %w", kid, err)
+		}
+		res[kid] = k
+	}
+	return res, nil
+}
+
+// ParsePublicPEM reads a "PUBLIC KEY", "RSA PUBLIC KEY" or "CERTIFICATE" block.
+func ParsePublicPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Alg(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParsePrivatePEM reads a PKCS#8, PKCS#1 or SEC 1 private key.
+func ParsePrivatePEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Alg(key); err != nil {
+		return nil, err
+	}
+	return key.(crypto.Signer), nil
+}
+
+// EncodePrivatePEM returns key as a PKCS#8 "PRIVATE KEY" block.
+func EncodePrivatePEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublicPEM returns key as a PKIX "PUBLIC KEY" block.
+func EncodePublicPEM(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,194 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWK_RoundTrip(t *testing.T) {
+	for _, alg := range []string{RS256, ES256} {
+		t.Run(alg, func(t *testing.T) {
+			priv, err := Generate(alg)
+			require.NoError(t, err)
+			jwk, err := NewJWK("k1", priv.Public())
+			require.NoError(t, err)
+			assert.Equal(t, alg, jwk.Alg)
+
+			pub, err := jwk.PublicKey()
+			require.NoError(t, err)
+			assert.True(t, priv.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(pub))
+		})
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	priv, err := Generate(ES256)
+	require.NoError(t, err)
+	pubPEM, err := EncodePublicPEM(priv.Public())
+	require.NoError(t, err)
+	privPEM, err := EncodePrivatePEM(priv)
+	require.NoError(t, err)
+	pubPath, privPath := filepath.Join(dir, "k1.pub.pem"), filepath.Join(dir, "k1.key.pem")
+	require.NoError(t, os.WriteFile(pubPath, pubPEM, 0o644))
+	require.NoError(t, os.WriteFile(privPath, privPEM, 0o600))
+
+	set, err := LoadFiles(" k1=" + pubPath + ", ")
+	require.NoError(t, err)
+	_, err = set.Key(context.Background(), "k1")
+	assert.NoError(t, err)
+	_, err = set.Key(context.Background(), "k2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = LoadFiles("k1=" + privPath)
+	assert.Error(t, err, "a private key is not a public key")
+	_, err = LoadFiles(pubPath)
+	assert.Error(t, err, "kid is required")
+
+	signer, err := ParsePrivatePEM(privPEM)
+	require.NoError(t, err)
+	alg, err := Alg(signer)
+	require.NoError(t, err)
+	assert.Equal(t, ES256, alg)
+}
+
+func TestJWKS_Rotation(t *testing.T) {
+	oldKey, err := Generate(RS256)
+	require.NoError(t, err)
+	newKey, err := Generate(ES256)
+	require.NoError(t, err)
+
+	published := JWKSet{}
+	publish := func(kid string, k crypto.Signer) {
+		jwk, err := NewJWK(kid, k.Public())
+		require.NoError(t, err)
+		published.Keys = append(published.Keys, jwk)
+	}
+	publish("old", oldKey)
+
+	var hits atomic.Int32
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(published)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	set := NewJWKS(srv.URL, 10*time.Minute)
+	set.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err = set.Key(ctx, "old")
+	require.NoError(t, err)
+	_, err = set.Key(ctx, "old")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, hits.Load(), "cached")
+
+	// the issuer publishes the next key ahead of signing with it
+	publish("new", newKey)
+	_, err = set.Key(ctx, "new")
+	assert.ErrorIs(t, err, ErrUnknownKey, "refetch is rate limited")
+	now = now.Add(time.Minute)
+	_, err = set.Key(ctx, "new")
+	require.NoError(t, err, "unknown kid triggers a refetch")
+	assert.EqualValues(t, 2, hits.Load())
+
+	// the JWKS endpoint failing after the TTL keeps the last good keys
+	down.Store(true)
+	now = now.Add(time.Hour)
+	_, err = set.Key(ctx, "old")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return hits.Load() == 3 }, time.Second, time.Millisecond, "refreshed in the background")
+	_, err = set.Key(ctx, "old")
+	assert.NoError(t, err)
+}
+
+func TestJWKS_FetchOutsideLock(t *testing.T) {
+	key, err := Generate(ES256)
+	require.NoError(t, err)
+	jwk, err := NewJWK("k1", key.Public())
+	require.NoError(t, err)
+
+	var hits atomic.Int32
+	var down atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			<-release // the refresh hangs
+		}
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(JWKSet{Keys: []JWK{jwk}})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	var mu sync.Mutex
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	set := NewJWKS(srv.URL, 10*time.Minute)
+	set.now = func() time.Time { mu.Lock(); defer mu.Unlock(); return now }
+	ctx := context.Background()
+
+	_, err = set.Key(ctx, "k1")
+	require.NoError(t, err)
+
+	// past the TTL every caller still gets the cached key at once
+	mu.Lock()
+	now = now.Add(time.Hour)
+	mu.Unlock()
+	for i := 0; i < 5; i++ {
+		_, err = set.Key(ctx, "k1")
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool { return hits.Load() == 2 }, time.Second, time.Millisecond, "one refresh")
+
+	// an unknown kid waits for the running fetch, but only as long as its caller
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = set.Key(short, "k2")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestJWKS_FirstFetchFailsRateLimited(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	set := NewJWKS(srv.URL, 10*time.Minute)
+	set.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := set.Key(ctx, "k1")
+		assert.ErrorContains(t, err, "502")
+	}
+	assert.EqualValues(t, 1, hits.Load(), "no keys yet, retries still rate limited")
+
+	now = now.Add(time.Minute)
+	_, err := set.Key(ctx, "k1")
+	assert.Error(t, err)
+	assert.EqualValues(t, 2, hits.Load())
+}
@@ -0,0 +1,251 @@
+// Package loadgen ramps up simulated devices against the ingest endpoint
+// while querying status and trips, and reports latency and throughput.
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+)
+
+// Operation names used as keys in Report.Ops.
+const (
+	OpIngest = "ingest"
+	OpStatus = "status"
+	OpTrips  = "trips"
+)
+
+type Config struct {
+	BaseURL       string        `json:"base_url"`
+	Token         string        `json:"-"`
+	StartDevices  int           `json:"start_devices"`
+	Devices       int           `json:"devices"`
+	Ramp          time.Duration `json:"ramp"`
+	Duration      time.Duration `json:"duration"`
+	FixInterval   time.Duration `json:"fix_interval"`
+	Queriers      int           `json:"queriers"`
+	QueryInterval time.Duration `json:"query_interval"`
+	Seed          int64         `json:"seed"`
+}
+
+func (c *Config) defaults() error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("loadgen: base url is required")
+	}
+	if c.Devices <= 0 {
+		c.Devices = 100
+	}
+	if c.StartDevices <= 0 || c.StartDevices > c.Devices {
+		c.StartDevices = 1
+	}
+	if c.Duration <= 0 {
+		c.Duration = time.Minute
+	}
+	if c.Ramp < 0 || c.Ramp > c.Duration {
+		c.Ramp = c.Duration
+	}
+	if c.FixInterval <= 0 {
+		c.FixInterval = time.Second
+	}
+	if c.QueryInterval <= 0 {
+		c.QueryInterval = 100 * time.Millisecond
+	}
+	return nil
+}
+
+// Runner executes one load test.
+type Runner struct {
+	cfg    Config
+	client *http.Client
+	rec    *recorder
+
+	mu       sync.Mutex
+	vehicles []uuid.UUID // vehicles that have ingested at least once
+}
+
+func New(cfg Config) (*Runner, error) {
+	if err := cfg.defaults(); err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxIdleConnsPerHost = cfg.Devices + cfg.Queriers
+	return &Runner{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: tr},
+		rec:    newRecorder(),
+	}, nil
+}
+
+// Run blocks for the configured duration (or until ctx ends) and returns the report.
+func (r *Runner) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Duration)
+	defer cancel()
+
+	started := time.Now()
+	rng := rand.New(rand.NewSource(r.cfg.Seed))
+	var wg sync.WaitGroup
+
+	for i := 0; i < r.cfg.Queriers; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r.query(ctx, rand.New(rand.NewSource(seed)))
+		}(rng.Int63())
+	}
+
+	// launch devices linearly from StartDevices to Devices over Ramp
+	peak := 0
+	launch := func(n int) {
+		for ; peak < n; peak++ {
+			wg.Add(1)
+			go func(seed int64) {
+				defer wg.Done()
+				r.device(ctx, rand.New(rand.NewSource(seed)))
+			}(rng.Int63())
+		}
+	}
+	launch(r.cfg.StartDevices)
+	ticker := time.NewTicker(100 * time.Millisecond)
+loop:
+	for peak < r.cfg.Devices {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			frac := 1.0
+			if r.cfg.Ramp > 0 {
+				frac = float64(time.Since(started)) / float64(r.cfg.Ramp)
+			}
+			target := r.cfg.StartDevices + int(frac*float64(r.cfg.Devices-r.cfg.StartDevices))
+			launch(min(target, r.cfg.Devices))
+		}
+	}
+	ticker.Stop()
+
+	<-ctx.Done()
+	wg.Wait()
+	finished := time.Now()
+	elapsed := finished.Sub(started)
+	return Report{
+		Started:     started.UTC(),
+		Finished:    finished.UTC(),
+		DurationSec: round3(elapsed.Seconds()),
+		PeakDevices: peak,
+		Config:      r.cfg,
+		Ops:         r.rec.summarise(elapsed),
+	}
+}
+
+// device reports a random walk around Dubai every FixInterval.
+func (r *Runner) device(ctx context.Context, rng *rand.Rand) {
+	id := uuidFrom(rng)
+	lon, lat := 55.1+rng.Float64()*0.3, 25.0+rng.Float64()*0.3
+	registered := false
+
+	// spread the first fixes so devices don't tick in lock-step
+	select {
+	case <-time.After(time.Duration(rng.Int63n(int64(r.cfg.FixInterval)))):
+	case <-ctx.Done():
+		return
+	}
+	ticker := time.NewTicker(r.cfg.FixInterval)
+	defer ticker.Stop()
+	for {
+		lon += (rng.Float64() - 0.5) * 0.001
+		lat += (rng.Float64() - 0.5) * 0.001
+		body, _ := json.Marshal(model.InputRequestPayload{
+			VehicleID:   id,
+			PlateNumber: "LOAD-" + id.String()[:8],
+			Status: model.Status{
+				Location:  [2]float64{lon, lat},
+				Speed:     rng.Float64() * 100,
+				Timestamp: time.Now().UTC(),
+			},
+		})
+		status := r.do(ctx, OpIngest, http.MethodPost, "/api/vehicle/ingest", body)
+		if status >= 200 && status < 300 && !registered {
+			registered = true
+			r.mu.Lock()
+			r.vehicles = append(r.vehicles, id)
+			r.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// query alternates status and trips lookups of vehicles known to exist.
+func (r *Runner) query(ctx context.Context, rng *rand.Rand) {
+	ticker := time.NewTicker(r.cfg.QueryInterval)
+	defer ticker.Stop()
+	for n := 0; ; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		if len(r.vehicles) == 0 {
+			r.mu.Unlock()
+			continue
+		}
+		id := r.vehicles[rng.Intn(len(r.vehicles))]
+		r.mu.Unlock()
+
+		if n%2 == 0 {
+			r.do(ctx, OpStatus, http.MethodGet, "/api/vehicle/status?id="+id.String(), nil)
+		} else {
+			r.do(ctx, OpTrips, http.MethodGet, "/api/vehicle/trips?id="+id.String(), nil)
+		}
+	}
+}
+
+// do issues one request and records it; requests cut short by the end of
+// the run are not counted.
+func (r *Runner) do(ctx context.Context, op, method, path string, body []byte) int {
+	req, err := http.NewRequestWithContext(ctx, method, r.cfg.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.rec.record(op, elapsed, 0)
+		}
+		return 0
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	r.rec.record(op, elapsed, resp.StatusCode)
+	return resp.StatusCode
+}
+
+func uuidFrom(r *rand.Rand) uuid.UUID {
+	var id uuid.UUID
+	r.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id
+}
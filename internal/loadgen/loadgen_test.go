@@ -0,0 +1,105 @@
+package loadgen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentiles(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []float64
+		want Percentiles
+	}{
+		{"empty", nil, Percentiles{}},
+		{"single", []float64{7}, Percentiles{Mean: 7, P50: 7, P90: 7, P95: 7, P99: 7, Max: 7}},
+		{
+			"one to hundred",
+			func() []float64 {
+				v := make([]float64, 100)
+				for i := range v {
+					v[i] = float64(100 - i)
+				}
+				return v
+			}(),
+			Percentiles{Mean: 50.5, P50: 50, P90: 90, P95: 95, P99: 99, Max: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, percentiles(tt.in))
+		})
+	}
+}
+
+func TestRecorder_Summarise(t *testing.T) {
+	rec := newRecorder()
+	rec.record(OpIngest, 10*time.Millisecond, 202)
+	rec.record(OpIngest, 20*time.Millisecond, 202)
+	rec.record(OpIngest, 30*time.Millisecond, 429)
+	rec.record(OpIngest, 40*time.Millisecond, 0)
+
+	got := rec.summarise(2 * time.Second)[OpIngest]
+	assert.EqualValues(t, 4, got.Requests)
+	assert.EqualValues(t, 2, got.Errors)
+	assert.EqualValues(t, 1, got.Throttled)
+	assert.Equal(t, 0.5, got.ErrorRate)
+	assert.Equal(t, 1.0, got.Throughput)
+	assert.Equal(t, map[int]int64{202: 2, 429: 1, 0: 1}, got.Status)
+}
+
+func TestRunner_Run(t *testing.T) {
+	var ingests, queries atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/vehicle/ingest":
+			ingests.Add(1)
+			w.WriteHeader(http.StatusAccepted)
+		case strings.HasPrefix(r.URL.Path, "/api/vehicle/"):
+			queries.Add(1)
+			w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	runner, err := New(Config{
+		BaseURL:       srv.URL,
+		Token:         "tok",
+		StartDevices:  2,
+		Devices:       5,
+		Ramp:          200 * time.Millisecond,
+		Duration:      600 * time.Millisecond,
+		FixInterval:   50 * time.Millisecond,
+		Queriers:      2,
+		QueryInterval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	report := runner.Run(context.Background())
+	assert.Equal(t, 5, report.PeakDevices)
+	require.Contains(t, report.Ops, OpIngest)
+	assert.Equal(t, ingests.Load(), report.Ops[OpIngest].Requests)
+	assert.Zero(t, report.Ops[OpIngest].Errors)
+	assert.Positive(t, report.Ops[OpIngest].Throughput)
+	assert.Equal(t, queries.Load(), report.Ops[OpStatus].Requests+report.Ops[OpTrips].Requests)
+	assert.Positive(t, queries.Load())
+}
+
+func TestNew_RequiresURL(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+}
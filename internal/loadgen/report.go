@@ -0,0 +1,130 @@
+package loadgen
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Report is the JSON document written at the end of a run. Keep field names
+// stable: runs from different releases are diffed against each other.
+type Report struct {
+	Version     string             `json:"version,omitempty"`
+	Started     time.Time          `json:"started"`
+	Finished    time.Time          `json:"finished"`
+	DurationSec float64            `json:"duration_sec"`
+	PeakDevices int                `json:"peak_devices"`
+	Config      Config             `json:"config"`
+	Ops         map[string]OpStats `json:"ops"`
+}
+
+// OpStats summarises one kind of request.
+type OpStats struct {
+	Requests   int64         `json:"requests"`
+	Errors     int64         `json:"errors"` // transport errors and non-2xx, 429 included
+	Throttled  int64         `json:"throttled"`
+	ErrorRate  float64       `json:"error_rate"`
+	Throughput float64       `json:"throughput_rps"` // successful requests per second
+	LatencyMs  Percentiles   `json:"latency_ms"`
+	Status     map[int]int64 `json:"status_codes"`
+}
+
+type Percentiles struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// recorder collects raw samples per operation.
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*samples
+}
+
+type samples struct {
+	latencies []float64 // ms, successful and failed requests alike
+	errors    int64
+	throttled int64
+	status    map[int]int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: map[string]*samples{}}
+}
+
+// record stores one request; status 0 means a transport error.
+func (r *recorder) record(op string, d time.Duration, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.ops[op]
+	if !ok {
+		s = &samples{status: map[int]int64{}}
+		r.ops[op] = s
+	}
+	s.latencies = append(s.latencies, float64(d.Microseconds())/1000)
+	s.status[status]++
+	if status < 200 || status >= 300 {
+		s.errors++
+	}
+	if status == 429 {
+		s.throttled++
+	}
+}
+
+func (r *recorder) summarise(elapsed time.Duration) map[string]OpStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make(map[string]OpStats, len(r.ops))
+	for op, s := range r.ops {
+		n := int64(len(s.latencies))
+		st := OpStats{
+			Requests:  n,
+			Errors:    s.errors,
+			Throttled: s.throttled,
+			LatencyMs: percentiles(s.latencies),
+			Status:    s.status,
+		}
+		if n > 0 {
+			st.ErrorRate = round3(float64(s.errors) / float64(n))
+		}
+		if elapsed > 0 {
+			st.Throughput = round3(float64(n-s.errors) / elapsed.Seconds())
+		}
+		res[op] = st
+	}
+	return res
+}
+
+// percentiles uses the nearest-rank method on a sorted copy.
+func percentiles(v []float64) Percentiles {
+	if len(v) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]float64(nil), v...)
+	sort.Float64s(sorted)
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return round3(sorted[i])
+	}
+	sum := 0.0
+	for _, x := range sorted {
+		sum += x
+	}
+	return Percentiles{
+		Mean: round3(sum / float64(len(sorted))),
+		P50:  rank(50),
+		P90:  rank(90),
+		P95:  rank(95),
+		P99:  rank(99),
+		Max:  round3(sorted[len(sorted)-1]),
+	}
+}
+
+func round3(f float64) float64 { return math.Round(f*1000) / 1000 }
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAuditBody caps how much of a response NewAudit buffers to diff.
+const maxAuditBody = 64 << 10
+
+// AuditLog stores audit entries (see service.AuditService).
+type AuditLog interface {
+	Record(ctx context.Context, e model.AuditEntry) error
+}
+
+// AuditLoader fetches the current state of the resource a route changes, so
+// NewAudit can diff it before and after the call.
+type AuditLoader func(ctx context.Context, id uuid.UUID) (any, error)
+
+// AuditLoaderOf adapts a typed getter such as a service's Get.
+func AuditLoaderOf[T any](get func(context.Context, uuid.UUID) (T, error)) AuditLoader {
+	return func(ctx context.Context, id uuid.UUID) (any, error) { return get(ctx, id) }
+}
+
+// AuditConfig says what NewAudit records. Every mutating call is recorded;
+// GETs only for the routes (gin paths) listed in Reads. Loaders are keyed by
+// the route prefix of a resource, e.g. "/api/vehicles/:id", and also serve
+// the sub-routes below it.
+type AuditConfig struct {
+	Log     AuditLog
+	Reads   []string
+	Loaders map[string]AuditLoader
+}
+
+// NewAudit records who called what, from where and with what effect. It
+// must run after the auth middleware so loaders see the caller's tenant.
+// Changes are stored as a field diff of the resource: from its loader when
+// the route has one, else from the response of an /api create. Fields named like
+// secrets, passwords or tokens are redacted. The entry is written after the
+// handler, in the chain of the request's tenant or, for unauthenticated
+// calls, of the tenant the handler resolved (tenant.Record); a failure is
+// logged and doesn't undo the call.
+func NewAudit(cfg AuditConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		mutating := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if route == "" || !mutating && !slices.Contains(cfg.Reads, route) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		ctx, resolved := tenant.WithRecorder(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		var (
+			load   AuditLoader
+			id     uuid.UUID
+			before map[string]any
+			body   *captureWriter
+		)
+		if mutating {
+			load, id = cfg.loader(c, route)
+			if load != nil {
+				before = snapshot(c.Request.Context(), load, id)
+			} else if c.Request.Method == http.MethodPost && strings.HasPrefix(route, "/api/") {
+				body = &captureWriter{ResponseWriter: c.Writer}
+				c.Writer = body
+			}
+		}
+
+		c.Next()
+
+		e := model.AuditEntry{
+			At:         start,
+			Actor:      c.GetString("user"),
+			Action:     c.Request.Method + " " + route,
+			Resource:   c.Request.URL.Path,
+			ResourceID: resourceID(c),
+			Status:     c.Writer.Status(),
+			IP:         c.ClientIP(),
+			RequestID:  c.GetString(ContextRequestID),
+		}
+		if mutating && e.Status < http.StatusBadRequest {
+			var after map[string]any
+			switch {
+			case load != nil:
+				after = snapshot(c.Request.Context(), load, id)
+			case body != nil:
+				if json.Unmarshal(body.buf.Bytes(), &after) == nil {
+					model.Redact(after)
+				}
+			}
+			if d := model.Diff(before, after); d != nil {
+				e.Diff, _ = json.Marshal(d)
+			}
+		}
+		ctx = c.Request.Context()
+		if _, ok := tenant.FromContext(ctx); !ok {
+			if id, ok := resolved.ID(); ok {
+				ctx = tenant.WithID(ctx, id)
+			}
+		}
+		if err := cfg.Log.Record(ctx, e); err != nil {
+			slog.Error("audit log write failed", "err", err, "action", e.Action, "requestID", e.RequestID)
+		}
+	}
+}
+
+// loader picks the loader of the longest registered prefix of route.
+func (cfg AuditConfig) loader(c *gin.Context, route string) (AuditLoader, uuid.UUID) {
+	var (
+		best   AuditLoader
+		bestAt int
+	)
+	for prefix, load := range cfg.Loaders {
+		if len(prefix) > bestAt && (route == prefix || strings.HasPrefix(route, prefix+"/")) {
+			best, bestAt = load, len(prefix)
+		}
+	}
+	if best == nil {
+		return nil, uuid.Nil
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, uuid.Nil
+	}
+	return best, id
+}
+
+// snapshot loads a resource as a redacted JSON object; nil when it doesn't
+// exist (before a create, after a delete).
+func snapshot(ctx context.Context, load AuditLoader, id uuid.UUID) map[string]any {
+	v, err := load(ctx, id)
+	if err != nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if json.Unmarshal(raw, &m) != nil {
+		return nil
+	}
+	model.Redact(m)
+	return m
+}
+
+// resourceID is the id the call is about: the path :id, else ?id= or
+// ?group_id= of the vehicle reads.
+func resourceID(c *gin.Context) string {
+	for _, v := range []string{c.Param("id"), c.Param("key_id"), c.Query("id"), c.Query("group_id")} {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// captureWriter keeps a copy of the response body for NewAudit.
+type captureWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep buffers up to maxAuditBody; a longer body is dropped rather than
+// diffed half-read.
+func (w *captureWriter) keep(b []byte) {
+	if w.buf.Len()+len(b) > maxAuditBody {
+		w.buf.Reset()
+		w.buf.WriteString("-")
+		return
+	}
+	w.buf.Write(b)
+}
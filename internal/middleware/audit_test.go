@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuditLog struct {
+	entries []model.AuditEntry
+	tenants []uuid.UUID // tenant of each entry's context; uuid.Max when unscoped
+}
+
+func (l *fakeAuditLog) Record(ctx context.Context, e model.AuditEntry) error {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		id = uuid.Max
+	}
+	l.entries = append(l.entries, e)
+	l.tenants = append(l.tenants, id)
+	return nil
+}
+
+func TestNewAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+	names := map[uuid.UUID]string{id: "old"}
+	load := func(_ context.Context, id uuid.UUID) (map[string]string, error) {
+		n, ok := names[id]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return map[string]string{"name": n, "secret": "x"}, nil
+	}
+
+	log := &fakeAuditLog{}
+	r := gin.New()
+	r.Use(RequestID(), func(c *gin.Context) { c.Set("user", "alice") })
+	r.Use(NewAudit(AuditConfig{
+		Log:     log,
+		Reads:   []string{"/things/:id/location"},
+		Loaders: map[string]AuditLoader{"/things/:id": AuditLoaderOf(load)},
+	}))
+	r.GET("/things/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/things/:id/location", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.PATCH("/things/:id", func(c *gin.Context) {
+		names[id] = "new"
+		c.Status(http.StatusOK)
+	})
+	r.DELETE("/things/:id", func(c *gin.Context) {
+		delete(names, id)
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/api/things", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "t2", "token": "abc"})
+	})
+	r.POST("/api/fail", func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"error": "no"}) })
+
+	diff := func(d []byte) map[string]any {
+		if d == nil {
+			return nil
+		}
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(d, &m))
+		return m
+	}
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantLogged bool
+		wantAction string
+		wantDiff   map[string]any
+	}{
+		{name: "plain read is not audited", method: http.MethodGet, path: "/things/" + id.String()},
+		{name: "sensitive read", method: http.MethodGet, path: "/things/" + id.String() + "/location", wantLogged: true, wantAction: "GET /things/:id/location"},
+		{
+			name: "update diffs the loaded resource", method: http.MethodPatch, path: "/things/" + id.String(), wantLogged: true, wantAction: "PATCH /things/:id",
+			wantDiff: map[string]any{"name": map[string]any{"before": "old", "after": "new"}},
+		},
+		{
+			name: "delete", method: http.MethodDelete, path: "/things/" + id.String(), wantLogged: true, wantAction: "DELETE /things/:id",
+			wantDiff: map[string]any{
+				"name":   map[string]any{"before": "new", "after": nil},
+				"secret": map[string]any{"before": "[redacted]", "after": nil},
+			},
+		},
+		{
+			name: "create diffs the response", method: http.MethodPost, path: "/api/things", wantLogged: true, wantAction: "POST /api/things",
+			wantDiff: map[string]any{
+				"id":    map[string]any{"before": nil, "after": "t2"},
+				"token": map[string]any{"before": nil, "after": "[redacted]"},
+			},
+		},
+		{name: "failed call is logged without a diff", method: http.MethodPost, path: "/api/fail", wantLogged: true, wantAction: "POST /api/fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log.entries = nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(HeaderRequestID, "req-1")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if !tt.wantLogged {
+				assert.Empty(t, log.entries)
+				return
+			}
+			require.Len(t, log.entries, 1)
+			e := log.entries[0]
+			assert.Equal(t, "alice", e.Actor)
+			assert.Equal(t, tt.wantAction, e.Action)
+			assert.Equal(t, w.Code, e.Status)
+			assert.Equal(t, "req-1", e.RequestID)
+			assert.NotEmpty(t, e.IP)
+			if strings.Contains(tt.path, id.String()) {
+				assert.Equal(t, id.String(), e.ResourceID)
+			}
+			assert.Equal(t, tt.wantDiff, diff(e.Diff))
+		})
+	}
+}
+
+func TestNewAudit_Tenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	acme, globex := uuid.New(), uuid.New()
+
+	log := &fakeAuditLog{}
+	r := gin.New()
+	r.Use(NewAudit(AuditConfig{Log: log}))
+	r.POST("/auth/refresh", func(c *gin.Context) {
+		tenant.Record(c.Request.Context(), acme) // the token's tenant
+		c.Status(http.StatusOK)
+	})
+	r.POST("/auth/logout", func(c *gin.Context) {
+		setTenant(c, globex) // what jwtAuth does after the audit middleware
+		tenant.Record(c.Request.Context(), acme)
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/auth/password/reset", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+
+	tests := []struct {
+		name string
+		path string
+		want uuid.UUID
+	}{
+		{name: "resolved by the handler", path: "/auth/refresh", want: acme},
+		{name: "request tenant wins", path: "/auth/logout", want: globex},
+		{name: "unresolved stays unscoped", path: "/auth/password/reset", want: uuid.Max},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log.entries, log.tenants = nil, nil
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.path, nil))
+			require.Len(t, log.tenants, 1)
+			assert.Equal(t, tt.want, log.tenants[0])
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Device authentication headers.
+//
+// API key:  X-Device-Key: <key_id>.<secret>
+//
+// HMAC:     X-Device-Key-Id, X-Device-Timestamp (unix seconds), X-Device-Nonce
+// and X-Device-Signature = hex(HMAC-SHA256(sha256(secret), StringToSign)) where
+// StringToSign is METHOD \n REQUEST_URI \n TIMESTAMP \n NONCE \n hex(sha256(body)).
+const (
+	HeaderDeviceKey       = "X-Device-Key"
+	HeaderDeviceKeyID     = "X-Device-Key-Id"
+	HeaderDeviceTimestamp = "X-Device-Timestamp"
+	HeaderDeviceNonce     = "X-Device-Nonce"
+	HeaderDeviceSignature = "X-Device-Signature"
+
+	// MaxClockSkew bounds how old (or how far in the future) a signed request may be.
+	MaxClockSkew = 5 * time.Minute
+
+	// ContextDeviceVehicle holds the uuid.UUID a device credential is bound to.
+	ContextDeviceVehicle = "device_vehicle_id"
+
+	// ContextDevice holds the device uuid.UUID for credentials bound to a
+	// device rather than a vehicle; the vehicle is then resolved per fix.
+	ContextDevice = "device_id"
+
+	// ContextTenant holds the uuid.UUID of the tenant the request is scoped to.
+	ContextTenant = "tenant_id"
+
+	// ContextGrants holds the auth.Grants of the caller.
+	ContextGrants = "grants"
+
+	// ContextClaims holds the auth.Claims of a bearer token.
+	ContextClaims = "claims"
+
+	// ContextRequestID holds the request id string (see RequestID).
+	ContextRequestID = "request_id"
+)
+
+// DeviceCredentials looks up active credentials by their public key id.
+type DeviceCredentials interface {
+	FindActiveByKeyID(ctx context.Context, keyID string) (model.DeviceCredential, error)
+}
+
+// NewDeviceAuth authenticates trackers by API key or HMAC signature; HMAC
+// signing keys are opened with serverKey (see model.SealKey). Requests
+// without device headers are handed to fallback (normally the JWT middleware)
+// so humans and scripts can keep using bearer tokens.
+func NewDeviceAuth(creds DeviceCredentials, nonces cache.NonceStore, serverKey []byte, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			cred model.DeviceCredential
+			ok   bool
+		)
+		switch {
+		case c.GetHeader(HeaderDeviceKey) != "":
+			cred, ok = apiKeyCredential(c, creds)
+		case c.GetHeader(HeaderDeviceKeyID) != "":
+			cred, ok = signedCredential(c, creds, nonces, serverKey)
+		default:
+			fallback(c)
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid device credentials"})
+			return
+		}
+
+		c.Set("user", "device:"+cred.KeyID)
+		setTenant(c, cred.TenantID)
+		c.Set(ContextGrants, auth.Expand([]string{auth.RoleDevice}, nil))
+		setPrincipal(c, auth.Principal{Subject: "device:" + cred.KeyID})
+		if cred.DeviceID != nil {
+			c.Set(ContextDevice, *cred.DeviceID)
+		} else {
+			c.Set(ContextDeviceVehicle, cred.VehicleID)
+		}
+		c.Next()
+	}
+}
+
+func apiKeyCredential(c *gin.Context, creds DeviceCredentials) (model.DeviceCredential, bool) {
+	keyID, secret, found := strings.Cut(c.GetHeader(HeaderDeviceKey), ".")
+	if !found || secret == "" {
+		return model.DeviceCredential{}, false
+	}
+	cred, err := creds.FindActiveByKeyID(c, keyID)
+	if err != nil || cred.Kind != model.CredentialAPIKey {
+		return model.DeviceCredential{}, false
+	}
+	got := model.HashSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(cred.SecretHash)) != 1 {
+		return model.DeviceCredential{}, false
+	}
+	return cred, true
+}
+
+func signedCredential(c *gin.Context, creds DeviceCredentials, nonces cache.NonceStore, serverKey []byte) (model.DeviceCredential, bool) {
+	ts := c.GetHeader(HeaderDeviceTimestamp)
+	nonce := c.GetHeader(HeaderDeviceNonce)
+	sig, err := hex.DecodeString(c.GetHeader(HeaderDeviceSignature))
+	if ts == "" || nonce == "" || err != nil || len(sig) == 0 {
+		return model.DeviceCredential{}, false
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return model.DeviceCredential{}, false
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return model.DeviceCredential{}, false
+	}
+
+	keyID := c.GetHeader(HeaderDeviceKeyID)
+	cred, err := creds.FindActiveByKeyID(c, keyID)
+	if err != nil || cred.Kind != model.CredentialHMAC {
+		return model.DeviceCredential{}, false
+	}
+	key, err := model.OpenKey(serverKey, cred.SealedKey)
+	if err != nil {
+		return model.DeviceCredential{}, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return model.DeviceCredential{}, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(StringToSign(c.Request.Method, c.Request.URL.RequestURI(), ts, nonce, body)))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return model.DeviceCredential{}, false
+	}
+
+	// Only burn the nonce once the signature is known good, so garbage
+	// requests can't pre-empt a legitimate device's nonce.
+	fresh, err := nonces.Remember(c, keyID+":"+nonce, 2*MaxClockSkew)
+	if err != nil || !fresh {
+		return model.DeviceCredential{}, false
+	}
+	return cred, true
+}
+
+// StringToSign builds the canonical request string devices sign.
+func StringToSign(method, requestURI, timestamp, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{method, requestURI, timestamp, nonce, hex.EncodeToString(sum[:])}, "\n")
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeCreds map[string]model.DeviceCredential
+
+func (f fakeCreds) FindActiveByKeyID(_ context.Context, keyID string) (model.DeviceCredential, error) {
+	cred, ok := f[keyID]
+	if !ok {
+		return model.DeviceCredential{}, gorm.ErrRecordNotFound
+	}
+	return cred, nil
+}
+
+type fakeNonces map[string]bool
+
+func (f fakeNonces) Remember(_ context.Context, nonce string, _ time.Duration) (bool, error) {
+	if f[nonce] {
+		return false, nil
+	}
+	f[nonce] = true
+	return true, nil
+}
+
+func (f fakeNonces) Close() error { return nil }
+
+func TestNewDeviceAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	vehicleID := uuid.New()
+	const apiSecret, hmacSecret = "api-secret", "hmac-secret"
+	serverKey := []byte("server-key")
+	sealed, err := model.SealKey(serverKey, model.SigningKey(hmacSecret))
+	require.NoError(t, err)
+	creds := fakeCreds{
+		"dk_api":    {KeyID: "dk_api", Kind: model.CredentialAPIKey, VehicleID: vehicleID, SecretHash: model.HashSecret(apiSecret)},
+		"dk_hmac":   {KeyID: "dk_hmac", Kind: model.CredentialHMAC, VehicleID: vehicleID, SealedKey: sealed},
+		"dk_legacy": {KeyID: "dk_legacy", Kind: model.CredentialHMAC, VehicleID: vehicleID, SecretHash: model.HashSecret(hmacSecret)},
+	}
+	nonces := fakeNonces{}
+
+	r := gin.New()
+	fallback := func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusTeapot)
+	}
+	r.POST("/api/vehicle/ingest", NewDeviceAuth(creds, nonces, serverKey, fallback), func(c *gin.Context) {
+		bound, _ := c.Get(ContextDeviceVehicle)
+		assert.Equal(t, vehicleID, bound)
+		c.Status(http.StatusOK)
+	})
+
+	const body = `{"vehicle_id":"x"}`
+	sign := func(req *http.Request, keyID, secret, nonce string, ts time.Time) {
+		key := sha256.Sum256([]byte(secret))
+		tsStr := strconv.FormatInt(ts.Unix(), 10)
+		mac := hmac.New(sha256.New, key[:])
+		mac.Write([]byte(StringToSign(req.Method, req.URL.RequestURI(), tsStr, nonce, []byte(body))))
+		req.Header.Set(HeaderDeviceKeyID, keyID)
+		req.Header.Set(HeaderDeviceTimestamp, tsStr)
+		req.Header.Set(HeaderDeviceNonce, nonce)
+		req.Header.Set(HeaderDeviceSignature, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(req *http.Request)
+		expected int
+	}{
+		{name: "positive - no device headers falls back", setup: func(req *http.Request) {}, expected: http.StatusTeapot},
+		{name: "positive - api key", setup: func(req *http.Request) { req.Header.Set(HeaderDeviceKey, "dk_api."+apiSecret) }, expected: http.StatusOK},
+		{name: "negative - wrong api key", setup: func(req *http.Request) { req.Header.Set(HeaderDeviceKey, "dk_api.nope") }, expected: http.StatusUnauthorized},
+		{name: "negative - hmac key used as api key", setup: func(req *http.Request) { req.Header.Set(HeaderDeviceKey, "dk_hmac."+hmacSecret) }, expected: http.StatusUnauthorized},
+		{name: "positive - signed request", setup: func(req *http.Request) { sign(req, "dk_hmac", hmacSecret, "n1", time.Now()) }, expected: http.StatusOK},
+		{name: "negative - replayed nonce", setup: func(req *http.Request) { sign(req, "dk_hmac", hmacSecret, "n1", time.Now()) }, expected: http.StatusUnauthorized},
+		{name: "negative - stale timestamp", setup: func(req *http.Request) { sign(req, "dk_hmac", hmacSecret, "n2", time.Now().Add(-time.Hour)) }, expected: http.StatusUnauthorized},
+		{name: "negative - wrong secret", setup: func(req *http.Request) { sign(req, "dk_hmac", "guess", "n3", time.Now()) }, expected: http.StatusUnauthorized},
+		{name: "negative - unsealed legacy key", setup: func(req *http.Request) { sign(req, "dk_legacy", hmacSecret, "n4", time.Now()) }, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/vehicle/ingest", strings.NewReader(body))
+			tt.setup(req)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestNewDeviceAuth_DeviceBound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deviceID := uuid.New()
+	creds := fakeCreds{
+		"dk_dev": {KeyID: "dk_dev", Kind: model.CredentialAPIKey, DeviceID: &deviceID, SecretHash: model.HashSecret("s")},
+	}
+	r := gin.New()
+	r.POST("/api/vehicle/ingest", NewDeviceAuth(creds, fakeNonces{}, nil, nil), func(c *gin.Context) {
+		_, vehicleBound := c.Get(ContextDeviceVehicle)
+		dev, _ := c.Get(ContextDevice)
+		assert.False(t, vehicleBound)
+		assert.Equal(t, deviceID, dev)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/vehicle/ingest", strings.NewReader(`{}`))
+	req.Header.Set(HeaderDeviceKey, "dk_dev.s")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
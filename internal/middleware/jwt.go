@@ -1,51 +1,168 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// NewJWT ...
-func NewJWT(secret []byte) gin.HandlerFunc {
+// JWTConfig says which tokens NewJWT accepts. RS256/ES256 tokens are verified
+// with the Keys entry named by their kid header; HS256 tokens with Secret.
+// Either may be left empty to refuse that kind. Issuer and Audience, when
+// set, must match the iss and aud claims. Revocations, when set, is asked
+// about every token; if it can't answer the request is refused. Tokens whose
+// iss belongs to one of Trusted are handed to it instead (e.g. an OIDC
+// identity provider).
+type JWTConfig struct {
+	Secret      []byte
+	Keys        keys.Set
+	Issuer      string
+	Audience    string
+	Revocations cache.RevocationList
+	Trusted     []TrustedIssuer
+}
+
+// TrustedIssuer verifies tokens minted by a third party and maps them onto
+// the API's claims.
+type TrustedIssuer interface {
+	Issuer() string
+	Verify(ctx context.Context, raw string) (auth.Claims, error)
+}
+
+// Parse verifies raw with the configured keys, issuer and audience and
+// decodes it into claims.
+func (cfg JWTConfig) Parse(ctx context.Context, raw string, claims jwt.Claims) error {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(cfg.methods())}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	_, err := jwt.NewParser(opts...).ParseWithClaims(raw, claims, cfg.keyFunc(ctx))
+	return err
+}
+
+// verify routes raw to the trusted issuer named in its (not yet verified)
+// iss claim, or to the local keys.
+func (cfg JWTConfig) verify(ctx context.Context, raw string) (auth.Claims, error) {
+	if len(cfg.Trusted) > 0 {
+		var peek jwt.RegisteredClaims
+		if _, _, err := jwt.NewParser().ParseUnverified(raw, &peek); err == nil {
+			for _, t := range cfg.Trusted {
+				if t.Issuer() == peek.Issuer {
+					return t.Verify(ctx, raw)
+				}
+			}
+		}
+	}
+	var claims auth.Claims
+	err := cfg.Parse(ctx, raw, &claims)
+	return claims, err
+}
+
+// NewJWT verifies the bearer token and scopes the request context to its
+// tenant. Handlers pass *gin.Context to services, so the engine must have
+// ContextWithFallback enabled for the scope to reach the repositories.
+func NewJWT(cfg JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const bearer = "Bearer "
 
-		auth := c.GetHeader("Authorization")
-		if !strings.HasPrefix(auth, bearer) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, bearer) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
 			return
 		}
-		tok := strings.TrimPrefix(auth, bearer)
+		tok := strings.TrimPrefix(header, bearer)
 
-		var claims jwt.RegisteredClaims
-		parsed, err := jwt.ParseWithClaims(tok, &claims, func(t *jwt.Token) (any, error) {
-			if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return secret, nil
-		})
-		if err != nil || !parsed.Valid {
+		claims, err := cfg.verify(c.Request.Context(), tok)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			return
 		}
 
+		tid, err := tenant.Parse(claims.Tenant)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid tenant claim"})
+			return
+		}
+
+		setTenant(c, tid)
+		if cfg.Revocations != nil {
+			var iat time.Time
+			if claims.IssuedAt != nil {
+				iat = claims.IssuedAt.Time
+			}
+			revoked, err := cfg.Revocations.IsRevoked(c.Request.Context(), claims.ID, claims.Subject, iat)
+			if err != nil {
+				slog.Error("revocation list unavailable", "err", err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "cannot check token revocation"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+				return
+			}
+		}
+
 		// ✅ store values in Gin’s context
 		c.Set("user", claims.Subject)
-		c.Set("claims", claims)
+		c.Set(ContextClaims, claims)
+		c.Set(ContextGrants, auth.Expand(claims.Roles, claims.Scopes))
+		setPrincipal(c, auth.Principal{Subject: claims.Subject, Restricted: claims.Restricted})
 		c.Next()
 	}
 }
 
-// GenerateDevToken ...
-func GenerateDevToken(sub string, secret []byte, ttl time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   sub,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+// methods is never nil: an empty list must reject every token, while nil
+// would let the parser accept any algorithm.
+func (cfg JWTConfig) methods() []string {
+	res := []string{}
+	if len(cfg.Secret) > 0 {
+		res = append(res, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.Keys != nil {
+		res = append(res, keys.RS256, keys.ES256)
+	}
+	return res
+}
+
+// keyFunc picks the verification key. An asymmetric key is only used for
+// the algorithm it belongs to, so an RSA key can't verify an ES256 token.
+func (cfg JWTConfig) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			return cfg.Secret, nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		key, err := cfg.Keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if alg, err := keys.Alg(key); err != nil || alg != t.Method.Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key, nil
 	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// setTenant scopes the rest of the request to tenant id.
+func setTenant(c *gin.Context, id uuid.UUID) {
+	c.Set(ContextTenant, id)
+	c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), id))
+}
+
+// setPrincipal exposes the caller to the service layer.
+func setPrincipal(c *gin.Context, p auth.Principal) {
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
 }
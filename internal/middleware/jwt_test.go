@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJWT_Tenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	acme := uuid.New()
+
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.GET("/me", NewJWT(JWTConfig{Secret: secret}), func(c *gin.Context) {
+		id, ok := tenant.FromContext(c) // as services see it
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, id.String())
+	})
+
+	token := func(tenantClaim string) string {
+		tok, err := auth.GenerateDevToken(devClaims("alice", tenantClaim), secret, time.Minute)
+		require.NoError(t, err)
+		return tok
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "positive - tenant claim", token: token(acme.String()), wantCode: http.StatusOK, wantBody: acme.String()},
+		{name: "positive - no claim is the default tenant", token: token(""), wantCode: http.StatusOK, wantBody: tenant.Default.String()},
+		{name: "negative - malformed claim", token: token("acme"), wantCode: http.StatusUnauthorized},
+		{name: "negative - wrong secret", token: func() string {
+			tok, _ := auth.GenerateDevToken(devClaims("alice", acme.String()), []byte("other"), time.Minute)
+			return tok
+		}(), wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewJWT_Keys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	rsaOld, err := keys.Generate(keys.RS256)
+	require.NoError(t, err)
+	ecNew, err := keys.Generate(keys.ES256)
+	require.NoError(t, err)
+	stranger, err := keys.Generate(keys.ES256)
+	require.NoError(t, err)
+
+	// mid-rotation: both the outgoing and the incoming key verify
+	set := keys.Static{"2024-rsa": rsaOld.Public(), "2025-ec": ecNew.Public()}
+	r := gin.New()
+	r.GET("/me", NewJWT(JWTConfig{Keys: set, Issuer: "fleet-auth", Audience: "fleet-api"}), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user"))
+	})
+
+	claims := func(iss, aud string) auth.Claims {
+		c := devClaims("alice", "")
+		c.Issuer, c.Audience = iss, jwt.ClaimStrings{aud}
+		return c
+	}
+	sign := func(c auth.Claims, key crypto.Signer, kid string) string {
+		tok, err := auth.SignToken(c, key, kid, time.Minute)
+		require.NoError(t, err)
+		return tok
+	}
+	valid := claims("fleet-auth", "fleet-api")
+	hs, err := auth.GenerateDevToken(valid, secret, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{name: "positive - RS256 outgoing key", token: sign(valid, rsaOld, "2024-rsa"), wantCode: http.StatusOK},
+		{name: "positive - ES256 incoming key", token: sign(valid, ecNew, "2025-ec"), wantCode: http.StatusOK},
+		{name: "negative - unknown kid", token: sign(valid, ecNew, "2026-ec"), wantCode: http.StatusUnauthorized},
+		{name: "negative - kid of another key", token: sign(valid, stranger, "2025-ec"), wantCode: http.StatusUnauthorized},
+		{name: "negative - kid of a key for another algorithm", token: sign(valid, ecNew, "2024-rsa"), wantCode: http.StatusUnauthorized},
+		{name: "negative - wrong issuer", token: sign(claims("someone", "fleet-api"), ecNew, "2025-ec"), wantCode: http.StatusUnauthorized},
+		{name: "negative - wrong audience", token: sign(claims("fleet-auth", "billing"), ecNew, "2025-ec"), wantCode: http.StatusUnauthorized},
+		{name: "negative - HS256 without a secret configured", token: hs, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+// fakeRevocations revokes by jti and by subject cutoff; err simulates Redis
+// being down.
+type fakeRevocations struct {
+	jtis    map[string]bool
+	cutoffs map[string]time.Time
+	err     error
+}
+
+func (f *fakeRevocations) Revoke(_ context.Context, jti string, _ time.Time) error {
+	f.jtis[jti] = true
+	return nil
+}
+
+func (f *fakeRevocations) RevokeSubject(_ context.Context, subject string, at time.Time) error {
+	f.cutoffs[subject] = at
+	return nil
+}
+
+func (f *fakeRevocations) IsRevoked(_ context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	cutoff, ok := f.cutoffs[subject]
+	return f.jtis[jti] || (ok && issuedAt.Before(cutoff)), nil
+}
+
+func (f *fakeRevocations) Close() error { return nil }
+
+func TestNewJWT_Revocations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	revoked := &fakeRevocations{jtis: map[string]bool{}, cutoffs: map[string]time.Time{}}
+
+	r := gin.New()
+	r.GET("/me", NewJWT(JWTConfig{Secret: secret, Revocations: revoked}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	token := func(sub string) (string, auth.Claims) {
+		claims := devClaims(sub, "")
+		tok, err := auth.GenerateDevToken(claims, secret, time.Minute)
+		require.NoError(t, err)
+		parsed := auth.Claims{}
+		_, _, err = jwt.NewParser().ParseUnverified(tok, &parsed)
+		require.NoError(t, err)
+		require.NotEmpty(t, parsed.ID, "every token gets a jti")
+		return tok, parsed
+	}
+	stolen, stolenClaims := token("alice")
+	other, _ := token("alice")
+	bob, _ := token("bob")
+	require.NoError(t, revoked.Revoke(context.Background(), stolenClaims.ID, stolenClaims.ExpiresAt.Time))
+	require.NoError(t, revoked.RevokeSubject(context.Background(), "bob", time.Now().Add(time.Second)))
+
+	call := func(tok string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusUnauthorized, call(stolen), "revoked jti")
+	assert.Equal(t, http.StatusNoContent, call(other), "another token of the same user")
+	assert.Equal(t, http.StatusUnauthorized, call(bob), "issued before revoke-all")
+
+	revoked.err = errors.New("redis down")
+	assert.Equal(t, http.StatusServiceUnavailable, call(other), "fails closed")
+}
+
+func devClaims(sub, tenant string, roles ...string) auth.Claims {
+	return auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: sub}, Tenant: tenant, Roles: roles}
+}
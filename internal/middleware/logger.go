@@ -2,10 +2,28 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"log/slog"
 	"time"
 )
 
+// HeaderRequestID carries the request id in both directions.
+const HeaderRequestID = "X-Request-ID"
+
+// RequestID keeps the caller's X-Request-ID (up to 128 bytes) or makes one
+// up, echoes it in the response and stores it under ContextRequestID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		c.Set(ContextRequestID, id)
+		c.Header(HeaderRequestID, id)
+		c.Next()
+	}
+}
+
 func HTTPLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -20,6 +38,7 @@ func HTTPLogger() gin.HandlerFunc {
 			"status", c.Writer.Status(),
 			"duration", time.Since(start).Milliseconds(),
 			"clientIP", c.ClientIP(),
+			"requestID", c.GetString(ContextRequestID),
 		)
 	}
 }
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultPlan is used for clients without an explicit plan.
+const DefaultPlan = "default"
+
+var (
+	throttled     = expvar.NewMap("ratelimit_throttled")
+	limiterErrors = expvar.NewInt("ratelimit_errors")
+)
+
+// RateLimitConfig describes one limiter in the middleware chain.
+type RateLimitConfig struct {
+	Limiter cache.RateLimiter
+	// Name prefixes bucket keys and metrics, e.g. "ingest-vehicle".
+	Name string
+	// Plans maps a plan name to its limit; a client whose plan is missing
+	// falls back to DefaultPlan, and no DefaultPlan means unlimited.
+	Plans map[string]cache.Limit
+	// PlanOf resolves the caller's plan; nil means everyone is on DefaultPlan.
+	PlanOf func(c *gin.Context) string
+	// KeyOf picks the bucket; an empty key skips limiting for the request.
+	KeyOf func(c *gin.Context) string
+}
+
+// NewRateLimit answers 429 with Retry-After once a bucket is empty. If the
+// limiter backend fails the request is let through: losing rate limiting is
+// better than losing telemetry.
+func NewRateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := cfg.KeyOf(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		plan := DefaultPlan
+		if cfg.PlanOf != nil {
+			if p := cfg.PlanOf(c); p != "" {
+				plan = p
+			}
+		}
+		limit, ok := cfg.Plans[plan]
+		if !ok {
+			if limit, ok = cfg.Plans[DefaultPlan]; !ok {
+				c.Next()
+				return
+			}
+		}
+
+		allowed, retry, err := cfg.Limiter.Allow(c, cfg.Name+":"+key, limit)
+		if err != nil {
+			limiterErrors.Add(1)
+			slog.Error("rate limiter unavailable", "limiter", cfg.Name, "err", err)
+			c.Next()
+			return
+		}
+		if !allowed {
+			throttled.Add(cfg.Name+":"+plan, 1)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientKey buckets by authenticated caller: the device key for trackers,
+// the JWT subject for everyone else.
+func ClientKey(c *gin.Context) string {
+	return c.GetString("user")
+}
+
+// VehicleKey buckets by the vehicle_id of a JSON body (or its device_id or
+// imei), leaving the body readable for the handler.
+func VehicleKey(c *gin.Context) string {
+	if v, ok := c.Get(ContextDeviceVehicle); ok {
+		return fmt.Sprint(v)
+	}
+	if d, ok := c.Get(ContextDevice); ok {
+		return fmt.Sprint("device:", d)
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var p struct {
+		VehicleID string `json:"vehicle_id"`
+		DeviceID  string `json:"device_id"`
+		IMEI      string `json:"imei"`
+	}
+	if json.Unmarshal(body, &p) != nil {
+		return ""
+	}
+	switch {
+	case p.VehicleID != "":
+		return p.VehicleID
+	case p.DeviceID != "":
+		return "device:" + p.DeviceID
+	case p.IMEI != "":
+		return "imei:" + p.IMEI
+	}
+	return ""
+}
+
+// ParsePlans reads "name=rate:burst,..." such as "default=5:10,premium=50:100".
+func ParsePlans(s string) (map[string]cache.Limit, error) {
+	plans := map[string]cache.Limit{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(item, "=")
+		rateStr, burstStr, ok2 := strings.Cut(spec, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("rate limit plan %q: want name=rate:burst", item)
+		}
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate limit plan %q: bad rate", item)
+		}
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("rate limit plan %q: bad burst", item)
+		}
+		plans[strings.TrimSpace(name)] = cache.Limit{Rate: rate, Burst: burst}
+	}
+	return plans, nil
+}
+
+// ParseAssignments reads "client=plan,..." where client is a JWT subject or
+// "device:<key_id>".
+func ParseAssignments(s string) map[string]string {
+	res := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		client, plan, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if ok {
+			res[strings.TrimSpace(client)] = strings.TrimSpace(plan)
+		}
+	}
+	return res
+}
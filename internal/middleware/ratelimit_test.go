@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLimiter allows Burst calls per key and then refuses.
+type countingLimiter struct {
+	calls map[string]int
+	err   error
+}
+
+func (l *countingLimiter) Allow(_ context.Context, key string, lim cache.Limit) (bool, time.Duration, error) {
+	if l.err != nil {
+		return false, 0, l.err
+	}
+	l.calls[key]++
+	return l.calls[key] <= lim.Burst, 1500 * time.Millisecond, nil
+}
+
+func (l *countingLimiter) Close() error { return nil }
+
+func TestNewRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(l cache.RateLimiter) *gin.Engine {
+		r := gin.New()
+		r.POST("/ingest", NewRateLimit(RateLimitConfig{
+			Limiter: l,
+			Name:    "test",
+			Plans:   map[string]cache.Limit{DefaultPlan: {Rate: 1, Burst: 2}, "premium": {Rate: 1, Burst: 3}},
+			PlanOf: func(c *gin.Context) string {
+				if c.GetHeader("X-Plan") != "" {
+					return c.GetHeader("X-Plan")
+				}
+				return ""
+			},
+			KeyOf: VehicleKey,
+		}), func(c *gin.Context) {
+			// the limiter must not consume the body
+			var p struct {
+				VehicleID string `json:"vehicle_id"`
+			}
+			require.NoError(t, c.BindJSON(&p))
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+	send := func(r *gin.Engine, vehicle, plan string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(`{"vehicle_id":"`+vehicle+`"}`))
+		req.Header.Set("X-Plan", plan)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("positive - default plan throttles after burst", func(t *testing.T) {
+		r := newRouter(&countingLimiter{calls: map[string]int{}})
+
+		assert.Equal(t, http.StatusOK, send(r, "v1", "").Code)
+		assert.Equal(t, http.StatusOK, send(r, "v1", "").Code)
+		w := send(r, "v1", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+		// other vehicles have their own bucket
+		assert.Equal(t, http.StatusOK, send(r, "v2", "").Code)
+	})
+
+	t.Run("positive - plan raises the limit", func(t *testing.T) {
+		r := newRouter(&countingLimiter{calls: map[string]int{}})
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, send(r, "v1", "premium").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, send(r, "v1", "premium").Code)
+	})
+
+	t.Run("positive - limiter failure fails open", func(t *testing.T) {
+		r := newRouter(&countingLimiter{err: errors.New("redis down")})
+
+		assert.Equal(t, http.StatusOK, send(r, "v1", "").Code)
+	})
+}
+
+func TestParsePlans(t *testing.T) {
+	plans, err := ParsePlans("default=5:10, premium=0.5:100")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]cache.Limit{"default": {Rate: 5, Burst: 10}, "premium": {Rate: 0.5, Burst: 100}}, plans)
+
+	for _, bad := range []string{"default", "default=5", "default=x:1", "default=1:0"} {
+		_, err := ParsePlans(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestVehicleKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deviceID := uuid.New()
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(c *gin.Context)
+		want  string
+	}{
+		{name: "vehicle_id", body: `{"vehicle_id":"v1","device_id":"d1"}`, want: "v1"},
+		{name: "device_id", body: `{"device_id":"d1"}`, want: "device:d1"},
+		{name: "imei", body: `{"imei":"490154203237518"}`, want: "imei:490154203237518"},
+		{name: "device-bound credential wins", body: `{"vehicle_id":"v1"}`,
+			setup: func(c *gin.Context) { c.Set(ContextDevice, deviceID) }, want: "device:" + deviceID.String()},
+		{name: "not json", body: `nope`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(tt.body))
+			if tt.setup != nil {
+				tt.setup(c)
+			}
+			assert.Equal(t, tt.want, VehicleKey(c))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope lets the request through only if the authenticated caller
+// holds every listed scope. It must run after NewJWT or NewDeviceAuth.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		g, _ := c.Get(ContextGrants)
+		grants, _ := g.(auth.Grants)
+		if !grants.Has(scopes...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "missing scope " + strings.Join(scopes, " "),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Unrestricted rejects principals limited by access grants. It guards routes
+// that list fleet-wide data (groups, devices, drivers) or change anything.
+func Unrestricted() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := auth.PrincipalFrom(c.Request.Context()); ok && p.Restricted {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available to restricted users"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	const apiSecret = "api-secret"
+	creds := fakeCreds{
+		"dk_api": {KeyID: "dk_api", Kind: model.CredentialAPIKey, VehicleID: uuid.New(), SecretHash: model.HashSecret(apiSecret)},
+	}
+	jwtAuth := NewJWT(JWTConfig{Secret: secret})
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r.GET("/status", jwtAuth, RequireScope(auth.ScopeVehicleRead), ok)
+	r.POST("/vehicles", jwtAuth, RequireScope(auth.ScopeVehicleWrite), ok)
+	r.POST("/ingest", NewDeviceAuth(creds, fakeNonces{}, nil, jwtAuth), RequireScope(auth.ScopeTelemetryWrite), ok)
+
+	bearer := func(claims auth.Claims) http.Header {
+		tok, err := auth.GenerateDevToken(claims, secret, time.Minute)
+		require.NoError(t, err)
+		return http.Header{"Authorization": {"Bearer " + tok}}
+	}
+	viewer := bearer(devClaims("v", "", auth.RoleViewer))
+	dispatcher := bearer(devClaims("d", "", auth.RoleDispatcher))
+	legacy := bearer(devClaims("old", ""))
+	scoped := devClaims("s", "")
+	scoped.Scopes = []string{auth.ScopeTelemetryWrite}
+	ingestOnly := bearer(scoped)
+	device := http.Header{HeaderDeviceKey: {"dk_api." + apiSecret}}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   http.Header
+		wantCode int
+	}{
+		{name: "viewer reads", method: http.MethodGet, path: "/status", header: viewer, wantCode: http.StatusNoContent},
+		{name: "viewer cannot write", method: http.MethodPost, path: "/vehicles", header: viewer, wantCode: http.StatusForbidden},
+		{name: "viewer cannot ingest", method: http.MethodPost, path: "/ingest", header: viewer, wantCode: http.StatusForbidden},
+		{name: "dispatcher writes", method: http.MethodPost, path: "/vehicles", header: dispatcher, wantCode: http.StatusNoContent},
+		{name: "token without roles or scopes", method: http.MethodGet, path: "/status", header: legacy, wantCode: http.StatusForbidden},
+		{name: "explicit scope", method: http.MethodPost, path: "/ingest", header: ingestOnly, wantCode: http.StatusNoContent},
+		{name: "explicit scope only", method: http.MethodGet, path: "/status", header: ingestOnly, wantCode: http.StatusForbidden},
+		{name: "device credential ingests", method: http.MethodPost, path: "/ingest", header: device, wantCode: http.StatusNoContent},
+		{name: "unauthenticated", method: http.MethodGet, path: "/status", header: http.Header{}, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header = tt.header
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestUnrestricted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+
+	r := gin.New()
+	r.GET("/groups", NewJWT(JWTConfig{Secret: secret}), Unrestricted(), func(c *gin.Context) {
+		p, _ := auth.PrincipalFrom(c.Request.Context())
+		c.String(http.StatusOK, p.Subject)
+	})
+
+	restricted := devClaims("contractor", "", auth.RoleViewer)
+	restricted.Restricted = true
+	tests := []struct {
+		name     string
+		claims   auth.Claims
+		wantCode int
+		wantBody string
+	}{
+		{name: "regular user", claims: devClaims("alice", "", auth.RoleViewer), wantCode: http.StatusOK, wantBody: "alice"},
+		{name: "restricted user", claims: restricted, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := auth.GenerateDevToken(tt.claims, secret, time.Minute)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodGet, "/groups", nil)
+			req.Header.Set("Authorization", "Bearer "+tok)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidGrant is wrapped by every access grant validation failure.
+var ErrInvalidGrant = errors.New("invalid access grant")
+
+// AccessGrant lets a restricted subject (the JWT sub) see one vehicle, or
+// every vehicle in a group's subtree, during [From, To).
+type AccessGrant struct {
+	ID        uuid.UUID  `json:"id"                   gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"                    gorm:"type:uuid;index"`
+	Subject   string     `json:"subject"              gorm:"index"`
+	VehicleID *uuid.UUID `json:"vehicle_id,omitempty" gorm:"type:uuid"`
+	GroupID   *uuid.UUID `json:"group_id,omitempty"   gorm:"type:uuid"`
+	From      time.Time  `json:"from"                 gorm:"column:valid_from"`
+	To        *time.Time `json:"to,omitempty"         gorm:"column:valid_to"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (AccessGrant) TableName() string { return "access_grants" }
+
+// Covers reports whether the grant is in force at ts.
+func (g AccessGrant) Covers(ts time.Time) bool {
+	return !ts.Before(g.From) && (g.To == nil || ts.Before(*g.To))
+}
+
+// Validate requires a subject and exactly one of vehicle or group, and a
+// window that ends after it starts.
+func (g AccessGrant) Validate() error {
+	if strings.TrimSpace(g.Subject) == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidGrant)
+	}
+	if (g.VehicleID == nil) == (g.GroupID == nil) {
+		return fmt.Errorf("%w: exactly one of vehicle_id or group_id is required", ErrInvalidGrant)
+	}
+	if g.To != nil && !g.To.After(g.From) {
+		return fmt.Errorf("%w: to must be after from", ErrInvalidGrant)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessGrant_Validate(t *testing.T) {
+	vehicle, group := uuid.New(), uuid.New()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := from.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		grant   AccessGrant
+		wantErr bool
+	}{
+		{name: "positive - vehicle", grant: AccessGrant{Subject: "bob", VehicleID: &vehicle, From: from}},
+		{name: "positive - group with end", grant: AccessGrant{Subject: "bob", GroupID: &group, From: from, To: ptr(from.Add(time.Hour))}},
+		{name: "negative - no subject", grant: AccessGrant{Subject: " ", VehicleID: &vehicle}, wantErr: true},
+		{name: "negative - no target", grant: AccessGrant{Subject: "bob"}, wantErr: true},
+		{name: "negative - both targets", grant: AccessGrant{Subject: "bob", VehicleID: &vehicle, GroupID: &group}, wantErr: true},
+		{name: "negative - ends before start", grant: AccessGrant{Subject: "bob", VehicleID: &vehicle, From: from, To: &before}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.grant.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidGrant)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
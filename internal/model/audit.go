@@ -0,0 +1,138 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// AuditEntry records one API call. Entries of a tenant form a hash chain:
+// Seq counts up from 1 and Hash covers the entry and the previous Hash, so
+// editing, deleting or reordering rows breaks every later link.
+type AuditEntry struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID   uuid.UUID      `gorm:"type:uuid;uniqueIndex:idx_audit_seq" json:"-"`
+	Seq        int64          `gorm:"uniqueIndex:idx_audit_seq" json:"seq"`
+	At         time.Time      `json:"at"`
+	Actor      string         `gorm:"index" json:"actor"`       // JWT sub; empty for anonymous calls such as login
+	Action     string         `json:"action"`                   // method and route, e.g. "PATCH /api/vehicles/:id"
+	Resource   string         `json:"resource"`                 // request path
+	ResourceID string         `gorm:"index" json:"resource_id"` // the :id, ?id= or ?group_id= of the call
+	Status     int            `json:"status"`
+	Diff       datatypes.JSON `json:"diff,omitempty"` // {"field": {"before": ..., "after": ...}}
+	IP         string         `json:"ip"`
+	RequestID  string         `json:"request_id"`
+	PrevHash   string         `json:"prev_hash"`
+	Hash       string         `json:"hash"`
+}
+
+func (AuditEntry) TableName() string { return "audit_log" }
+
+// ComputeHash returns the hex SHA-256 of the entry's content and PrevHash.
+// At is hashed in UTC at microsecond precision, what Postgres keeps, and Diff
+// in canonical form, since JSONB hands it back with its own key order and
+// spacing.
+func (e AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	for _, f := range []string{
+		e.PrevHash,
+		e.ID.String(),
+		e.TenantID.String(),
+		strconv.FormatInt(e.Seq, 10),
+		e.At.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		e.Actor,
+		e.Action,
+		e.Resource,
+		e.ResourceID,
+		strconv.Itoa(e.Status),
+		canonicalJSON(e.Diff),
+		e.IP,
+		e.RequestID,
+	} {
+		// length-prefixed so fields can't bleed into each other
+		h.Write([]byte(strconv.Itoa(len(f)) + ":" + f + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalJSON re-encodes b compactly with sorted keys, as json.Marshal
+// writes a map; what isn't valid JSON is hashed as is.
+func canonicalJSON(b []byte) string {
+	var v any
+	if len(b) == 0 || json.Unmarshal(b, &v) != nil {
+		return string(b)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+// Diff compares two JSON objects field by field; nil when nothing changed.
+// Either side may be nil for creations and deletions.
+func Diff(before, after map[string]any) map[string]any {
+	res := map[string]any{}
+	for k, b := range before {
+		a, ok := after[k]
+		if !ok || !jsonEqual(a, b) {
+			res[k] = map[string]any{"before": b, "after": a}
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			res[k] = map[string]any{"before": nil, "after": a}
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// jsonEqual compares decoded JSON values.
+func jsonEqual(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		return ok && Diff(av, bv) == nil
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// redactedFields are never written to the audit log.
+var redactedFields = []string{"secret", "password", "token"}
+
+// Redact blanks fields whose name contains a credential word, at any depth.
+func Redact(v map[string]any) {
+	for k, val := range v {
+		lk := strings.ToLower(k)
+		for _, w := range redactedFields {
+			if strings.Contains(lk, w) {
+				v[k] = "[redacted]"
+				break
+			}
+		}
+		if m, ok := val.(map[string]any); ok && v[k] != "[redacted]" {
+			Redact(m)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after map[string]any
+		want          map[string]any
+	}{
+		{name: "unchanged", before: map[string]any{"a": 1.0, "tags": []any{"x"}}, after: map[string]any{"a": 1.0, "tags": []any{"x"}}},
+		{
+			name:   "changed and added",
+			before: map[string]any{"a": 1.0, "b": "x"},
+			after:  map[string]any{"a": 2.0, "b": "x", "c": true},
+			want: map[string]any{
+				"a": map[string]any{"before": 1.0, "after": 2.0},
+				"c": map[string]any{"before": nil, "after": true},
+			},
+		},
+		{name: "nested change", before: map[string]any{"m": map[string]any{"k": "v"}}, after: map[string]any{"m": map[string]any{"k": "w"}},
+			want: map[string]any{"m": map[string]any{"before": map[string]any{"k": "v"}, "after": map[string]any{"k": "w"}}}},
+		{name: "create", after: map[string]any{"a": 1.0}, want: map[string]any{"a": map[string]any{"before": nil, "after": 1.0}}},
+		{name: "delete", before: map[string]any{"a": 1.0}, want: map[string]any{"a": map[string]any{"before": 1.0, "after": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.before, tt.after))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	v := map[string]any{
+		"key_id": "dk_1",
+		"secret": "s3cr3t",
+		"token":  map[string]any{"nested": "x"},
+		"inner":  map[string]any{"refresh_token": "r", "name": "n"},
+	}
+	Redact(v)
+	assert.Equal(t, map[string]any{
+		"key_id": "dk_1",
+		"secret": "[redacted]",
+		"token":  "[redacted]",
+		"inner":  map[string]any{"refresh_token": "[redacted]", "name": "n"},
+	}, v)
+}
+
+func TestAuditEntry_ComputeHash(t *testing.T) {
+	e := AuditEntry{Seq: 1, Actor: "alice", Action: "POST /api/vehicles", Status: 201}
+	h := e.ComputeHash()
+	assert.Len(t, h, 64)
+	assert.Equal(t, h, e.ComputeHash())
+
+	for name, change := range map[string]func(*AuditEntry){
+		"actor":     func(e *AuditEntry) { e.Actor = "mallory" },
+		"prev hash": func(e *AuditEntry) { e.PrevHash = "00" },
+		"seq":       func(e *AuditEntry) { e.Seq = 2 },
+		"diff":      func(e *AuditEntry) { e.Diff = []byte(`{}`) },
+		// field boundaries are part of the hash
+		"shifted": func(e *AuditEntry) { e.Actor, e.Action = "aliceP", "OST /api/vehicles" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := e
+			change(&c)
+			assert.NotEqual(t, h, c.ComputeHash())
+		})
+	}
+}
+
+func TestAuditEntry_ComputeHash_JSONB(t *testing.T) {
+	diff, err := json.Marshal(Diff(
+		map[string]any{"plate_number": "A-1", "year": 2019.0, "meta": map[string]any{"fuel": "diesel", "axles": 2.0}},
+		map[string]any{"plate_number": "B-2", "year": 2020.0, "meta": map[string]any{"fuel": "petrol", "axles": 2.0}},
+	))
+	require.NoError(t, err)
+	e := AuditEntry{Seq: 1, Action: "PATCH /api/vehicles/:id", Status: 200, Diff: diff}
+	h := e.ComputeHash()
+
+	// what Postgres returns for that JSONB value: shorter keys first, spaces
+	// after ':' and ','
+	e.Diff = []byte(`{"meta": {"after": {"fuel": "petrol", "axles": 2}, "before": {"fuel": "diesel", "axles": 2}}, ` +
+		`"year": {"after": 2020, "before": 2019}, "plate_number": {"after": "B-2", "before": "A-1"}}`)
+	assert.Equal(t, h, e.ComputeHash())
+
+	e.Diff = []byte(`{"meta": {"after": {"fuel": "petrol", "axles": 2}, "before": {"fuel": "diesel", "axles": 2}}, ` +
+		`"year": {"after": 2021, "before": 2019}, "plate_number": {"after": "B-2", "before": "A-1"}}`)
+	assert.NotEqual(t, h, e.ComputeHash())
+}
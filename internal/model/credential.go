@@ -0,0 +1,102 @@
+package model
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device credential kinds.
+const (
+	CredentialAPIKey = "api_key" // secret sent as-is in X-Device-Key
+	CredentialHMAC   = "hmac"    // secret never leaves the device, requests are signed
+)
+
+// DeviceCredential maps to "device_credentials". The secret itself is never
+// stored. API keys keep only its SHA-256 in SecretHash. HMAC credentials need
+// the signing key (SigningKey of the secret) to check signatures, so it is
+// kept in SealedKey, encrypted with the server's key: reading the table alone
+// doesn't let anyone sign as the device. A credential is bound either to a
+// vehicle or to a device; device-bound credentials follow the device from
+// vehicle to vehicle.
+type DeviceCredential struct {
+	ID         uuid.UUID  `json:"id"                gorm:"type:uuid;primaryKey"`
+	TenantID   uuid.UUID  `json:"-"                 gorm:"type:uuid;index"`
+	KeyID      string     `json:"key_id"            gorm:"uniqueIndex"`     // global: looked up before the tenant is known
+	VehicleID  uuid.UUID  `json:"vehicle_id"        gorm:"type:uuid;index"` // uuid.Nil when bound to a device
+	DeviceID   *uuid.UUID `json:"device_id,omitempty" gorm:"type:uuid;index"`
+	Kind       string     `json:"kind"`
+	SecretHash string     `json:"-"` // api_key only
+	SealedKey  string     `json:"-"` // hmac only, see SealKey
+	Label      string     `json:"label,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (DeviceCredential) TableName() string { return "device_credentials" }
+
+// HashSecret is the digest stored for a device secret.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SigningKey is the HMAC key a device derives from its secret.
+func SigningKey(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// ErrBadSealedKey is returned when a sealed key can't be opened, e.g. after
+// the server key changed.
+var ErrBadSealedKey = errors.New("sealed key cannot be opened")
+
+// SealKey encrypts an HMAC signing key with AES-256-GCM under a key derived
+// from serverKey.
+func SealKey(serverKey, key []byte) (string, error) {
+	aead, err := keyAEAD(serverKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(aead.Seal(nonce, nonce, key, nil)), nil
+}
+
+// OpenKey reverses SealKey.
+func OpenKey(serverKey []byte, sealed string) ([]byte, error) {
+	aead, err := keyAEAD(serverKey)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil || len(b) < aead.NonceSize() {
+		return nil, ErrBadSealedKey
+	}
+	key, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrBadSealedKey
+	}
+	return key, nil
+}
+
+func keyAEAD(serverKey []byte) (cipher.AEAD, error) {
+	if len(serverKey) == 0 {
+		return nil, ErrBadSealedKey
+	}
+	k := sha256.Sum256(serverKey)
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
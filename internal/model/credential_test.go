@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealKey(t *testing.T) {
+	key := SigningKey("hmac-secret")
+	sealed, err := SealKey([]byte("server"), key)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, HashSecret("hmac-secret"))
+
+	again, err := SealKey([]byte("server"), key)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "fresh nonce every time")
+
+	opened, err := OpenKey([]byte("server"), sealed)
+	require.NoError(t, err)
+	assert.Equal(t, key, opened)
+
+	raw, err := base64.RawStdEncoding.DecodeString(sealed)
+	require.NoError(t, err)
+	raw[len(raw)-1] ^= 1
+	tampered := base64.RawStdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name      string
+		serverKey string
+		sealed    string
+	}{
+		{name: "negative - other server key", serverKey: "rotated", sealed: sealed},
+		{name: "negative - no server key", serverKey: "", sealed: sealed},
+		{name: "negative - tampered", serverKey: "server", sealed: tampered},
+		{name: "negative - garbage", serverKey: "server", sealed: "!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OpenKey([]byte(tt.serverKey), tt.sealed)
+			assert.ErrorIs(t, err, ErrBadSealedKey)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidDevice is wrapped by every device validation failure.
+	ErrInvalidDevice = errors.New("invalid device")
+
+	// ErrAssignmentOverlap is returned when an assignment would start before
+	// the device's latest assignment ends.
+	ErrAssignmentOverlap = errors.New("assignment overlaps an existing one")
+)
+
+// Device is a tracker, identified by IMEI and/or serial number, independent
+// of the vehicle it happens to be installed in.
+type Device struct {
+	ID        uuid.UUID `json:"id"                 gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `json:"-"                  gorm:"type:uuid;uniqueIndex:idx_devices_tenant_imei,priority:1;uniqueIndex:idx_devices_tenant_serial,priority:1"`
+	IMEI      *string   `json:"imei,omitempty"     gorm:"uniqueIndex:idx_devices_tenant_imei"`
+	Serial    *string   `json:"serial,omitempty"   gorm:"uniqueIndex:idx_devices_tenant_serial"`
+	Model     string    `json:"model,omitempty"`
+	Firmware  string    `json:"firmware,omitempty"`
+	ICCID     string    `json:"iccid,omitempty"` // SIM card
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Device) TableName() string { return "devices" }
+
+// DeviceAssignment records that a device was installed in a vehicle during
+// [From, To). To is nil while the device is still installed.
+type DeviceAssignment struct {
+	ID        uuid.UUID  `json:"id"         gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"          gorm:"type:uuid;index"`
+	DeviceID  uuid.UUID  `json:"device_id"  gorm:"type:uuid;index"`
+	VehicleID uuid.UUID  `json:"vehicle_id" gorm:"type:uuid;index"`
+	From      time.Time  `json:"from"       gorm:"column:valid_from"`
+	To        *time.Time `json:"to,omitempty" gorm:"column:valid_to"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (DeviceAssignment) TableName() string { return "device_assignments" }
+
+// Covers reports whether ts falls inside the assignment.
+func (a DeviceAssignment) Covers(ts time.Time) bool {
+	return !ts.Before(a.From) && (a.To == nil || ts.Before(*a.To))
+}
+
+// Normalize trims identifiers; empty IMEI/serial become nil so they don't
+// collide in the unique indexes.
+func (d *Device) Normalize() {
+	d.IMEI = trimmedOrNil(d.IMEI)
+	d.Serial = trimmedOrNil(d.Serial)
+	d.Model = strings.TrimSpace(d.Model)
+	d.Firmware = strings.TrimSpace(d.Firmware)
+	d.ICCID = strings.TrimSpace(d.ICCID)
+	d.Label = strings.TrimSpace(d.Label)
+}
+
+// Validate requires an IMEI or a serial; the IMEI must be 15 digits with a
+// valid Luhn check digit and the ICCID 18–22 digits.
+func (d Device) Validate() error {
+	if d.IMEI == nil && d.Serial == nil {
+		return invalidDevice("imei or serial is required")
+	}
+	if d.IMEI != nil && (len(*d.IMEI) != 15 || !digits(*d.IMEI) || !luhn(*d.IMEI)) {
+		return invalidDevice("imei %q is not a valid 15-digit IMEI", *d.IMEI)
+	}
+	if d.ICCID != "" && (len(d.ICCID) < 18 || len(d.ICCID) > 22 || !digits(d.ICCID)) {
+		return invalidDevice("iccid must be 18-22 digits")
+	}
+	return nil
+}
+
+// DevicePatch is the body of device create and update calls; nil fields are
+// left unchanged.
+type DevicePatch struct {
+	IMEI     *string `json:"imei"`
+	Serial   *string `json:"serial"`
+	Model    *string `json:"model"`
+	Firmware *string `json:"firmware"`
+	ICCID    *string `json:"iccid"`
+	Label    *string `json:"label"`
+}
+
+// Apply copies the set fields onto d.
+func (p DevicePatch) Apply(d *Device) {
+	if p.IMEI != nil {
+		imei := *p.IMEI
+		d.IMEI = &imei
+	}
+	if p.Serial != nil {
+		serial := *p.Serial
+		d.Serial = &serial
+	}
+	if p.Model != nil {
+		d.Model = *p.Model
+	}
+	if p.Firmware != nil {
+		d.Firmware = *p.Firmware
+	}
+	if p.ICCID != nil {
+		d.ICCID = *p.ICCID
+	}
+	if p.Label != nil {
+		d.Label = *p.Label
+	}
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// luhn validates the trailing check digit of an all-digit string.
+func luhn(s string) bool {
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		n := int(s[i] - '0')
+		if (len(s)-i)%2 == 0 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+func invalidDevice(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDevice, fmt.Sprintf(format, args...))
+}
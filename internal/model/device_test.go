@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevice_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  Device
+		wantErr bool
+	}{
+		{name: "positive - imei only", device: Device{IMEI: ptr("490154203237518")}},
+		{name: "positive - serial only", device: Device{Serial: ptr("FMB920-0042")}},
+		{name: "positive - with iccid", device: Device{Serial: ptr("S1"), ICCID: "8997103118123456789"}},
+		{name: "negative - no identifier", device: Device{Model: "FMB920"}, wantErr: true},
+		{name: "negative - imei check digit", device: Device{IMEI: ptr("490154203237519")}, wantErr: true},
+		{name: "negative - imei too short", device: Device{IMEI: ptr("49015420323751")}, wantErr: true},
+		{name: "negative - imei not numeric", device: Device{IMEI: ptr("49015420323751A")}, wantErr: true},
+		{name: "negative - iccid too short", device: Device{Serial: ptr("S1"), ICCID: "8997103118"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.device.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDevice)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDevice_NormalizeDropsBlankIdentifiers(t *testing.T) {
+	d := Device{IMEI: ptr(" "), Serial: ptr(" S1 ")}
+	d.Normalize()
+	assert.Nil(t, d.IMEI)
+	assert.Equal(t, "S1", *d.Serial)
+}
+
+func TestDeviceAssignment_Covers(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	closed := DeviceAssignment{From: from, To: &to}
+	open := DeviceAssignment{From: from}
+
+	assert.False(t, closed.Covers(from.Add(-time.Second)))
+	assert.True(t, closed.Covers(from))
+	assert.True(t, closed.Covers(to.Add(-time.Second)))
+	assert.False(t, closed.Covers(to), "end is exclusive")
+	assert.True(t, open.Covers(to.Add(1000*time.Hour)))
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// RawDiagnostics is the optional OBD-II / J1939 block of an ingest payload.
+// Data is hex encoded exactly as read from the bus.
+type RawDiagnostics struct {
+	OBD   []RawPID `json:"obd,omitempty"`
+	J1939 []RawPGN `json:"j1939,omitempty"`
+	// DTCs are service 03 words ("0301") or formatted codes ("P0301").
+	// A present-but-empty list means "no active codes" and clears them;
+	// omitting the field leaves the active set untouched.
+	DTCs []string `json:"dtcs"`
+}
+
+type RawPID struct {
+	PID  string `json:"pid"`  // e.g. "0C"
+	Data string `json:"data"` // e.g. "1AF8"
+}
+
+type RawPGN struct {
+	PGN  uint32 `json:"pgn"`  // e.g. 61444
+	Data string `json:"data"` // 8 bytes, e.g. "FFFFFF6812FFFFFF"
+}
+
+// SignalSample maps to "signal_samples": decoded time series per vehicle.
+type SignalSample struct {
+	ID        uuid.UUID `json:"-"          gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `json:"-"          gorm:"type:uuid;index"`
+	VehicleID uuid.UUID `json:"vehicle_id" gorm:"type:uuid;index:idx_signal_vehicle_name_time,priority:1"`
+	Name      string    `json:"name"       gorm:"index:idx_signal_vehicle_name_time,priority:2"`
+	Timestamp time.Time `json:"timestamp"  gorm:"index:idx_signal_vehicle_name_time,priority:3"`
+	Value     float64   `json:"value"`
+	Unit      string    `json:"unit"`
+}
+
+// ActiveDTC maps to "active_dtcs": the currently raised trouble codes.
+type ActiveDTC struct {
+	VehicleID uuid.UUID `json:"vehicle_id" gorm:"type:uuid;primaryKey"`
+	Code      string    `json:"code"       gorm:"primaryKey"`
+	TenantID  uuid.UUID `json:"-"          gorm:"type:uuid;index"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Event types written to "vehicle_events".
+const (
+	EventDTCActive  = "dtc_active"
+	EventDTCCleared = "dtc_cleared"
+)
+
+// Event maps to "vehicle_events".
+type Event struct {
+	ID        uuid.UUID      `json:"id"         gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID      `json:"-"          gorm:"type:uuid;index"`
+	VehicleID uuid.UUID      `json:"vehicle_id" gorm:"type:uuid;index:idx_events_vehicle_time,priority:1"`
+	Type      string         `json:"type"`
+	Code      string         `json:"code,omitempty"`
+	Details   datatypes.JSON `json:"details,omitempty"`
+	Timestamp time.Time      `json:"timestamp"  gorm:"index:idx_events_vehicle_time,priority:2"`
+}
+
+func (SignalSample) TableName() string { return "signal_samples" }
+func (ActiveDTC) TableName() string    { return "active_dtcs" }
+func (Event) TableName() string        { return "vehicle_events" }
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidDriver is wrapped by every driver validation failure.
+	ErrInvalidDriver = errors.New("invalid driver")
+
+	// ErrLicenceExpired is returned when a driver is put on a shift that starts
+	// after their licence expired.
+	ErrLicenceExpired = errors.New("driving licence expired")
+)
+
+// Shift sources.
+const (
+	ShiftManual = "manual" // assigned through the API
+	ShiftTag    = "tag"    // driver presented an iButton/RFID tag to the tracker
+)
+
+// Driver is a person who drives fleet vehicles. Tag is the iButton/RFID id the
+// tracker reports as driver_tag.
+type Driver struct {
+	ID            uuid.UUID  `json:"id"                       gorm:"type:uuid;primaryKey"`
+	TenantID      uuid.UUID  `json:"-"                        gorm:"type:uuid;uniqueIndex:idx_drivers_tenant_licence,priority:1;uniqueIndex:idx_drivers_tenant_tag,priority:1"`
+	Name          string     `json:"name"`
+	LicenceNumber *string    `json:"licence_number,omitempty" gorm:"uniqueIndex:idx_drivers_tenant_licence"`
+	LicenceExpiry *time.Time `json:"licence_expiry,omitempty"`
+	Phone         string     `json:"phone,omitempty"`
+	Email         string     `json:"email,omitempty"`
+	Tag           *string    `json:"tag,omitempty"            gorm:"uniqueIndex:idx_drivers_tenant_tag"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (Driver) TableName() string { return "drivers" }
+
+// LicenceValidAt reports whether the licence has not expired at ts. A driver
+// without a recorded expiry is assumed valid.
+func (d Driver) LicenceValidAt(ts time.Time) bool {
+	return d.LicenceExpiry == nil || ts.Before(*d.LicenceExpiry)
+}
+
+// DriverAssignment is a shift: the driver drove the vehicle during [From, To).
+// To is nil while the shift is still running.
+type DriverAssignment struct {
+	ID        uuid.UUID  `json:"id"         gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"          gorm:"type:uuid;index"`
+	DriverID  uuid.UUID  `json:"driver_id"  gorm:"type:uuid;index"`
+	VehicleID uuid.UUID  `json:"vehicle_id" gorm:"type:uuid;index"`
+	From      time.Time  `json:"from"       gorm:"column:valid_from"`
+	To        *time.Time `json:"to,omitempty" gorm:"column:valid_to"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (DriverAssignment) TableName() string { return "driver_assignments" }
+
+// Covers reports whether ts falls inside the shift.
+func (a DriverAssignment) Covers(ts time.Time) bool {
+	return !ts.Before(a.From) && (a.To == nil || ts.Before(*a.To))
+}
+
+// NormalizeTag upper-cases a tag id and strips the separators readers
+// disagree on, so "01-00:00 1a" and "0100001A" match.
+func NormalizeTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', ':':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(tag)))
+}
+
+// Normalize trims fields; empty licence numbers and tags become nil so they
+// don't collide in the unique indexes.
+func (d *Driver) Normalize() {
+	d.Name = strings.TrimSpace(d.Name)
+	d.LicenceNumber = trimmedOrNil(d.LicenceNumber)
+	if d.LicenceNumber != nil {
+		n := strings.ToUpper(*d.LicenceNumber)
+		d.LicenceNumber = &n
+	}
+	if d.Tag != nil {
+		t := NormalizeTag(*d.Tag)
+		d.Tag = &t
+	}
+	d.Tag = trimmedOrNil(d.Tag)
+	d.Phone = strings.TrimSpace(d.Phone)
+	d.Email = strings.TrimSpace(d.Email)
+}
+
+// Validate requires a name; email, when set, must parse and the tag is at
+// most 64 characters.
+func (d Driver) Validate() error {
+	if d.Name == "" {
+		return invalidDriver("name is required")
+	}
+	if len(d.Name) > 200 {
+		return invalidDriver("name is longer than 200 characters")
+	}
+	if d.Email != "" {
+		if _, err := mail.ParseAddress(d.Email); err != nil {
+			return invalidDriver("email %q is not valid", d.Email)
+		}
+	}
+	if d.Tag != nil && len(*d.Tag) > 64 {
+		return invalidDriver("tag is longer than 64 characters")
+	}
+	return nil
+}
+
+// DriverPatch is the body of driver create and update calls; nil fields are
+// left unchanged.
+type DriverPatch struct {
+	Name          *string    `json:"name"`
+	LicenceNumber *string    `json:"licence_number"`
+	LicenceExpiry *time.Time `json:"licence_expiry"`
+	Phone         *string    `json:"phone"`
+	Email         *string    `json:"email"`
+	Tag           *string    `json:"tag"`
+}
+
+// Apply copies the set fields onto d.
+func (p DriverPatch) Apply(d *Driver) {
+	if p.Name != nil {
+		d.Name = *p.Name
+	}
+	if p.LicenceNumber != nil {
+		n := *p.LicenceNumber
+		d.LicenceNumber = &n
+	}
+	if p.LicenceExpiry != nil {
+		exp := p.LicenceExpiry.UTC()
+		d.LicenceExpiry = &exp
+	}
+	if p.Phone != nil {
+		d.Phone = *p.Phone
+	}
+	if p.Email != nil {
+		d.Email = *p.Email
+	}
+	if p.Tag != nil {
+		tag := *p.Tag
+		d.Tag = &tag
+	}
+}
+
+func invalidDriver(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDriver, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriver_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  Driver
+		wantErr bool
+	}{
+		{name: "positive - name only", driver: Driver{Name: "Alice"}},
+		{name: "positive - full profile", driver: Driver{Name: "Alice", LicenceNumber: ptr("D1234567"), Email: "alice@example.com", Tag: ptr("01000012AB34CD56")}},
+		{name: "negative - no name", driver: Driver{Email: "alice@example.com"}, wantErr: true},
+		{name: "negative - bad email", driver: Driver{Name: "Alice", Email: "alice"}, wantErr: true},
+		{name: "negative - tag too long", driver: Driver{Name: "Alice", Tag: ptr(string(make([]byte, 65)))}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.driver.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDriver)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	assert.Equal(t, "0100001A", NormalizeTag(" 01-00:00 1a "))
+	assert.Equal(t, "", NormalizeTag(" - "))
+
+	d := Driver{Name: " Alice ", Tag: ptr(" : "), LicenceNumber: ptr(" d123 ")}
+	d.Normalize()
+	assert.Equal(t, "Alice", d.Name)
+	assert.Nil(t, d.Tag)
+	assert.Equal(t, "D123", *d.LicenceNumber)
+}
+
+func TestDriver_LicenceValidAt(t *testing.T) {
+	exp := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := Driver{LicenceExpiry: &exp}
+	assert.True(t, d.LicenceValidAt(exp.Add(-time.Second)))
+	assert.False(t, d.LicenceValidAt(exp))
+	assert.True(t, Driver{}.LicenceValidAt(exp))
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidGroup is wrapped by every group validation failure.
+	ErrInvalidGroup = errors.New("invalid group")
+
+	// ErrGroupCycle is returned when a group would be moved under itself.
+	ErrGroupCycle = errors.New("group cannot be moved into its own subtree")
+
+	// ErrGroupNotEmpty is returned when deleting a group that still has children.
+	ErrGroupNotEmpty = errors.New("group still has child groups")
+)
+
+// MaxGroupDepth bounds how deeply groups nest (region → depot → team …).
+const MaxGroupDepth = 8
+
+// VehicleGroup is one node of the fleet tree. Path is the materialised
+// path of ids from the root, "/<root>/…/<id>/", so a subtree is every group
+// whose path starts with this one's.
+type VehicleGroup struct {
+	ID        uuid.UUID  `json:"id"                  gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"                   gorm:"type:uuid;index"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+	Name      string     `json:"name"`
+	Path      string     `json:"path"                gorm:"index"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (VehicleGroup) TableName() string { return "vehicle_groups" }
+
+// Depth is 1 for a root group and 0 for the zero value (the virtual root).
+func (g VehicleGroup) Depth() int {
+	if g.Path == "" {
+		return 0
+	}
+	return strings.Count(g.Path, "/") - 1
+}
+
+// ErrGroupTooDeep wraps ErrInvalidGroup for trees deeper than MaxGroupDepth.
+var ErrGroupTooDeep = fmt.Errorf("%w: more than %d levels", ErrInvalidGroup, MaxGroupDepth)
+
+// ChildPath is the path of a direct child with the given id.
+func (g VehicleGroup) ChildPath(id uuid.UUID) string {
+	if g.Path == "" {
+		return "/" + id.String() + "/"
+	}
+	return g.Path + id.String() + "/"
+}
+
+func (g VehicleGroup) Validate() error {
+	name := strings.TrimSpace(g.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	case len(name) > 100:
+		return fmt.Errorf("%w: name longer than 100 characters", ErrInvalidGroup)
+	}
+	return nil
+}
+
+// GroupMember puts a vehicle in a group; a vehicle may belong to several.
+type GroupMember struct {
+	GroupID   uuid.UUID `json:"group_id"   gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `json:"-"          gorm:"type:uuid;index"`
+	VehicleID uuid.UUID `json:"vehicle_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (GroupMember) TableName() string { return "vehicle_group_members" }
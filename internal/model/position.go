@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Position maps to the "positions" table: one row per ingested fix, so the
+// full telemetry history survives after last_status has moved on.
+type Position struct {
+	ID         uuid.UUID      `json:"-"          gorm:"type:uuid;primaryKey"`
+	TenantID   uuid.UUID      `json:"-"          gorm:"type:uuid;index"`
+	VehicleID  uuid.UUID      `json:"vehicle_id" gorm:"type:uuid;index:idx_positions_vehicle_time,priority:1"`
+	Timestamp  time.Time      `json:"timestamp"  gorm:"index:idx_positions_vehicle_time,priority:2"`
+	Longitude  float64        `json:"longitude"`
+	Latitude   float64        `json:"latitude"`
+	Speed      float64        `json:"speed"`
+	Heading    *float64       `json:"heading,omitempty"`
+	Altitude   *float64       `json:"altitude,omitempty"`
+	HDOP       *float64       `json:"hdop,omitempty"`
+	Satellites *int           `json:"satellites,omitempty"`
+	Odometer   *float64       `json:"odometer,omitempty"`
+	Ignition   *bool          `json:"ignition,omitempty"`
+	ExtVoltage *float64       `json:"ext_voltage,omitempty"`
+	Attributes datatypes.JSON `json:"attributes,omitempty"`
+}
+
+// NewPosition flattens a Status into a history row.
+func NewPosition(vehicleID uuid.UUID, s Status) Position {
+	p := Position{
+		ID:         uuid.New(),
+		VehicleID:  vehicleID,
+		Timestamp:  s.Timestamp,
+		Longitude:  s.Location[0],
+		Latitude:   s.Location[1],
+		Speed:      s.Speed,
+		Heading:    s.Heading,
+		Altitude:   s.Altitude,
+		HDOP:       s.HDOP,
+		Satellites: s.Satellites,
+		Odometer:   s.Odometer,
+		Ignition:   s.Ignition,
+		ExtVoltage: s.ExtVoltage,
+	}
+	if len(s.Attributes) > 0 {
+		p.Attributes, _ = json.Marshal(s.Attributes)
+	}
+	return p
+}
+
+// Status rebuilds the API representation of a stored fix.
+func (p Position) Status() Status {
+	s := Status{
+		Location:   [2]float64{p.Longitude, p.Latitude},
+		Speed:      p.Speed,
+		Timestamp:  p.Timestamp,
+		Heading:    p.Heading,
+		Altitude:   p.Altitude,
+		HDOP:       p.HDOP,
+		Satellites: p.Satellites,
+		Odometer:   p.Odometer,
+		Ignition:   p.Ignition,
+		ExtVoltage: p.ExtVoltage,
+	}
+	if len(p.Attributes) > 0 {
+		_ = json.Unmarshal(p.Attributes, &s.Attributes)
+	}
+	return s
+}
+
+func (Position) TableName() string { return "positions" }
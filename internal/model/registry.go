@@ -0,0 +1,150 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/vin"
+)
+
+// ErrInvalidVehicle is wrapped by every registry validation failure.
+var ErrInvalidVehicle = errors.New("invalid vehicle")
+
+// FuelTypes lists the accepted values of Vehicle.FuelType.
+var FuelTypes = []string{"petrol", "diesel", "electric", "hybrid", "lpg", "cng", "hydrogen"}
+
+const (
+	maxPlateLen          = 16
+	maxVehicleTags       = 32
+	maxTagLen            = 32
+	maxVehicleAttributes = 64
+)
+
+// Normalize trims free-text fields, upper-cases plate and VIN, lower-cases
+// the fuel type and de-duplicates tags. Call it before Validate.
+func (v *Vehicle) Normalize() {
+	v.PlateNumber = strings.ToUpper(strings.TrimSpace(v.PlateNumber))
+	if v.VIN != nil {
+		if n := vin.Normalize(*v.VIN); n == "" {
+			v.VIN = nil
+		} else {
+			v.VIN = &n
+		}
+	}
+	v.Make = strings.TrimSpace(v.Make)
+	v.Model = strings.TrimSpace(v.Model)
+	v.FuelType = strings.ToLower(strings.TrimSpace(v.FuelType))
+
+	var tags []string
+	for _, t := range v.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	v.Tags = tags
+}
+
+// Validate checks the registry metadata; LastStatus is validated on ingest.
+func (v Vehicle) Validate() error {
+	switch {
+	case v.PlateNumber == "":
+		return invalidVehicle("plate_number is required")
+	case len(v.PlateNumber) > maxPlateLen:
+		return invalidVehicle("plate_number longer than %d characters", maxPlateLen)
+	case v.Year != 0 && (v.Year < 1900 || v.Year > time.Now().Year()+1):
+		return invalidVehicle("year %d out of range", v.Year)
+	case v.FuelType != "" && !slices.Contains(FuelTypes, v.FuelType):
+		return invalidVehicle("fuel_type must be one of %s", strings.Join(FuelTypes, ", "))
+	case negative(v.CapacityKg):
+		return invalidVehicle("capacity_kg %v must be >= 0", v.CapacityKg)
+	case negative(v.TankLiters):
+		return invalidVehicle("tank_liters %v must be >= 0", v.TankLiters)
+	case negative(v.OdometerBaseKm):
+		return invalidVehicle("odometer_base_km %v must be >= 0", v.OdometerBaseKm)
+	case len(v.Tags) > maxVehicleTags:
+		return invalidVehicle("too many tags (%d > %d)", len(v.Tags), maxVehicleTags)
+	case len(v.Attributes) > maxVehicleAttributes:
+		return invalidVehicle("too many attributes (%d > %d)", len(v.Attributes), maxVehicleAttributes)
+	}
+	if v.VIN != nil {
+		if err := vin.Validate(*v.VIN); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidVehicle, err)
+		}
+	}
+	for _, t := range v.Tags {
+		if len(t) > maxTagLen {
+			return invalidVehicle("tag %q longer than %d characters", t, maxTagLen)
+		}
+	}
+	for k := range v.Attributes {
+		if k == "" {
+			return invalidVehicle("attribute names must not be empty")
+		}
+	}
+	return nil
+}
+
+// VehiclePatch is the body of create and update calls. Nil fields are left
+// unchanged; an empty VIN clears it.
+type VehiclePatch struct {
+	PlateNumber    *string         `json:"plate_number"`
+	VIN            *string         `json:"vin"`
+	Make           *string         `json:"make"`
+	Model          *string         `json:"model"`
+	Year           *int            `json:"year"`
+	FuelType       *string         `json:"fuel_type"`
+	CapacityKg     *float64        `json:"capacity_kg"`
+	TankLiters     *float64        `json:"tank_liters"`
+	OdometerBaseKm *float64        `json:"odometer_base_km"`
+	Attributes     *map[string]any `json:"attributes"`
+	Tags           *[]string       `json:"tags"`
+}
+
+// Apply copies the set fields onto v.
+func (p VehiclePatch) Apply(v *Vehicle) {
+	if p.PlateNumber != nil {
+		v.PlateNumber = *p.PlateNumber
+	}
+	if p.VIN != nil {
+		vin := *p.VIN
+		v.VIN = &vin
+	}
+	if p.Make != nil {
+		v.Make = *p.Make
+	}
+	if p.Model != nil {
+		v.Model = *p.Model
+	}
+	if p.Year != nil {
+		v.Year = *p.Year
+	}
+	if p.FuelType != nil {
+		v.FuelType = *p.FuelType
+	}
+	if p.CapacityKg != nil {
+		v.CapacityKg = *p.CapacityKg
+	}
+	if p.TankLiters != nil {
+		v.TankLiters = *p.TankLiters
+	}
+	if p.OdometerBaseKm != nil {
+		v.OdometerBaseKm = *p.OdometerBaseKm
+	}
+	if p.Attributes != nil {
+		v.Attributes = *p.Attributes
+	}
+	if p.Tags != nil {
+		v.Tags = *p.Tags
+	}
+}
+
+func negative(f float64) bool { return math.IsNaN(f) || f < 0 }
+
+func invalidVehicle(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidVehicle, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicle_Validate(t *testing.T) {
+	base := func() Vehicle {
+		return Vehicle{PlateNumber: "DXB 12345"}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(v *Vehicle)
+		wantErr bool
+	}{
+		{name: "positive - plate only", mutate: func(v *Vehicle) {}},
+		{
+			name: "positive - full metadata",
+			mutate: func(v *Vehicle) {
+				v.VIN = ptr("1HGCM82633A004352")
+				v.Make, v.Model, v.Year = "Toyota", "Hiace", 2022
+				v.FuelType = "diesel"
+				v.CapacityKg, v.TankLiters, v.OdometerBaseKm = 1200, 70, 48211
+				v.Tags = []string{"cold-chain", "dubai"}
+				v.Attributes = map[string]any{"colour": "white"}
+			},
+		},
+		{name: "negative - missing plate", mutate: func(v *Vehicle) { v.PlateNumber = "" }, wantErr: true},
+		{name: "negative - long plate", mutate: func(v *Vehicle) { v.PlateNumber = strings.Repeat("A", 17) }, wantErr: true},
+		{name: "negative - short vin", mutate: func(v *Vehicle) { v.VIN = ptr("1HGCM8263") }, wantErr: true},
+		{name: "negative - vin with letter O", mutate: func(v *Vehicle) { v.VIN = ptr("1HGCM82633AO04352") }, wantErr: true},
+		{name: "negative - north american check digit", mutate: func(v *Vehicle) { v.VIN = ptr("1HGCM82643A004352") }, wantErr: true},
+		{name: "positive - foreign vin with bad check digit", mutate: func(v *Vehicle) { v.VIN = ptr("WVWZZZ1JZ3W386752") }},
+		{name: "negative - year too old", mutate: func(v *Vehicle) { v.Year = 1850 }, wantErr: true},
+		{name: "negative - year in future", mutate: func(v *Vehicle) { v.Year = 3000 }, wantErr: true},
+		{name: "negative - unknown fuel", mutate: func(v *Vehicle) { v.FuelType = "coal" }, wantErr: true},
+		{name: "negative - negative capacity", mutate: func(v *Vehicle) { v.CapacityKg = -1 }, wantErr: true},
+		{name: "negative - long tag", mutate: func(v *Vehicle) { v.Tags = []string{strings.Repeat("t", 33)} }, wantErr: true},
+		{name: "negative - empty attribute name", mutate: func(v *Vehicle) { v.Attributes = map[string]any{"": 1} }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := base()
+			tt.mutate(&v)
+			err := v.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidVehicle)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVehicle_Normalize(t *testing.T) {
+	v := Vehicle{
+		PlateNumber: " dxb 1 ",
+		VIN:         ptr("  "),
+		FuelType:    "Diesel",
+		Tags:        []string{"Dubai", "dubai", " ", "cold-chain"},
+	}
+	v.Normalize()
+
+	assert.Equal(t, "DXB 1", v.PlateNumber)
+	assert.Nil(t, v.VIN)
+	assert.Equal(t, "diesel", v.FuelType)
+	assert.Equal(t, []string{"dubai", "cold-chain"}, []string(v.Tags))
+}
+
+func TestVehiclePatch_Apply(t *testing.T) {
+	v := Vehicle{PlateNumber: "A1", Make: "Ford", Year: 2019}
+	VehiclePatch{Make: ptr("Volvo"), Tags: &[]string{"x"}}.Apply(&v)
+
+	assert.Equal(t, "A1", v.PlateNumber)
+	assert.Equal(t, "Volvo", v.Make)
+	assert.Equal(t, 2019, v.Year)
+	assert.Equal(t, []string{"x"}, []string(v.Tags))
+}
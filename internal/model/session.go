@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// RefreshToken is one link of a session's refresh chain. Only the SHA-256 of
+// the secret is stored. Each refresh uses the token up and issues the next
+// one in the same Family; presenting a used token again means it leaked, and
+// the whole family is revoked. The access token claims are kept so refreshes
+// re-issue the same grants.
+type RefreshToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID   uuid.UUID `gorm:"type:uuid;index"`
+	Family     uuid.UUID `gorm:"type:uuid;index"`
+	Subject    string    `gorm:"index"`
+	SecretHash string
+	Roles      datatypes.JSONSlice[string]
+	Scopes     datatypes.JSONSlice[string]
+	Restricted bool
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+func (RefreshToken) TableName() string { return "refresh_tokens" }
+
+// Active reports whether the token can still be exchanged at ts.
+func (t RefreshToken) Active(ts time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && ts.Before(t.ExpiresAt)
+}
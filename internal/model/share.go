@@ -0,0 +1,141 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Share link limits.
+const (
+	MaxShareWindow  = 30 * 24 * time.Hour
+	MaxShareCoarsen = 50_000 // metres
+)
+
+var (
+	// ErrInvalidShareLink is wrapped by every share link validation failure.
+	ErrInvalidShareLink = errors.New("invalid share link")
+	// ErrBadShareToken means the token is malformed or wasn't signed by us.
+	ErrBadShareToken = errors.New("bad share token")
+)
+
+// ShareLink lets anyone holding its token follow one vehicle during
+// [From, To) without an account. CoarsenM, when set, snaps every position
+// to a grid of that many metres.
+type ShareLink struct {
+	ID        uuid.UUID  `json:"id"                   gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"                    gorm:"type:uuid;index"`
+	VehicleID uuid.UUID  `json:"vehicle_id"           gorm:"type:uuid;index"`
+	Label     string     `json:"label,omitempty"`
+	From      time.Time  `json:"from"                 gorm:"column:valid_from"`
+	To        time.Time  `json:"to"                   gorm:"column:valid_to"`
+	CoarsenM  int        `json:"coarsen_m,omitempty"`
+	CreatedBy string     `json:"created_by"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (ShareLink) TableName() string { return "share_links" }
+
+// Validate requires a window of at most MaxShareWindow and a sane coarsening.
+func (l ShareLink) Validate() error {
+	switch {
+	case !l.To.After(l.From):
+		return fmt.Errorf("%w: to must be after from", ErrInvalidShareLink)
+	case l.To.Sub(l.From) > MaxShareWindow:
+		return fmt.Errorf("%w: window longer than %s", ErrInvalidShareLink, MaxShareWindow)
+	case l.CoarsenM < 0 || l.CoarsenM > MaxShareCoarsen:
+		return fmt.Errorf("%w: coarsen_m must be 0-%d", ErrInvalidShareLink, MaxShareCoarsen)
+	}
+	return nil
+}
+
+// Coarsen snaps a [lon, lat] location to the centre of its CoarsenM grid
+// cell, so nearby fixes become indistinguishable.
+func (l ShareLink) Coarsen(loc [2]float64) [2]float64 {
+	if l.CoarsenM <= 0 {
+		return loc
+	}
+	const metresPerDegree = 111_320.0
+	latStep := float64(l.CoarsenM) / metresPerDegree
+	lat := snap(loc[1], latStep)
+	// cells are as wide as they are tall, measured at the snapped latitude
+	lonStep := latStep / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+	return [2]float64{snap(loc[0], lonStep), lat}
+}
+
+func snap(v, step float64) float64 {
+	return (math.Floor(v/step) + 0.5) * step
+}
+
+// SharedFix is the part of a fix a share link reveals.
+type SharedFix struct {
+	Location  [2]float64 `json:"location"` // [long, lat], coarsened when the link asks for it
+	Speed     float64    `json:"speed"`
+	Heading   *float64   `json:"heading,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// Share reduces s to what link may reveal. Heading is dropped on coarsened
+// links, where it would point at the exact road.
+func (l ShareLink) Share(s Status) SharedFix {
+	f := SharedFix{Location: l.Coarsen(s.Location), Speed: s.Speed, Timestamp: s.Timestamp}
+	if l.CoarsenM == 0 {
+		f.Heading = s.Heading
+	}
+	return f
+}
+
+// SharedView is what the public share endpoint serves.
+type SharedView struct {
+	Label      string      `json:"label,omitempty"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	Status     *SharedFix  `json:"status"`     // latest fix inside the window, null before the first
+	Breadcrumb []SharedFix `json:"breadcrumb"` // recent fixes, oldest first
+}
+
+// ShareToken signs a link id and its expiry with secret. The token is
+// base64url(id ‖ expiry) "." base64url(HMAC-SHA256), so it can be checked
+// and expired without a database round trip.
+func ShareToken(id uuid.UUID, expires time.Time, secret []byte) string {
+	payload := make([]byte, 24)
+	copy(payload, id[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expires.Unix()))
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(shareMAC(payload, secret))
+}
+
+// ParseShareToken verifies a ShareToken and returns what it carries. It
+// doesn't check the expiry; the caller compares it with its clock.
+func ParseShareToken(tok string, secret []byte) (uuid.UUID, time.Time, error) {
+	enc := base64.RawURLEncoding
+	p, s, ok := strings.Cut(tok, ".")
+	if !ok {
+		return uuid.Nil, time.Time{}, ErrBadShareToken
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, time.Time{}, ErrBadShareToken
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, shareMAC(payload, secret)) {
+		return uuid.Nil, time.Time{}, ErrBadShareToken
+	}
+	id, _ := uuid.FromBytes(payload[:16])
+	return id, time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0), nil
+}
+
+func shareMAC(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("share-link:v1:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
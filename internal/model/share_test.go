@@ -0,0 +1,81 @@
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLink_Validate(t *testing.T) {
+	from := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		link    ShareLink
+		wantErr bool
+	}{
+		{name: "positive - two hours", link: ShareLink{From: from, To: from.Add(2 * time.Hour)}},
+		{name: "positive - coarsened", link: ShareLink{From: from, To: from.Add(time.Hour), CoarsenM: 500}},
+		{name: "negative - empty window", link: ShareLink{From: from, To: from}, wantErr: true},
+		{name: "negative - too long", link: ShareLink{From: from, To: from.Add(MaxShareWindow + time.Second)}, wantErr: true},
+		{name: "negative - negative coarsening", link: ShareLink{From: from, To: from.Add(time.Hour), CoarsenM: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.link.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidShareLink)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestShareLink_Coarsen(t *testing.T) {
+	exact := ShareLink{}
+	loc := [2]float64{55.2708, 25.2048}
+	assert.Equal(t, loc, exact.Coarsen(loc))
+
+	l := ShareLink{CoarsenM: 1000}
+	got := l.Coarsen(loc)
+	// within half a cell diagonal of the real position
+	dLat := (got[1] - loc[1]) * 111_320
+	dLon := (got[0] - loc[0]) * 111_320 * math.Cos(loc[1]*math.Pi/180)
+	assert.Less(t, math.Hypot(dLat, dLon), 1000*math.Sqrt2/2+1)
+	// nearby fixes in the same cell collapse onto one point
+	assert.Equal(t, got, l.Coarsen([2]float64{loc[0] + 0.0001, loc[1] + 0.0001}))
+
+	s := Status{Location: loc, Speed: 42, Heading: ptr(90.0)}
+	assert.Nil(t, l.Share(s).Heading, "coarsened links hide the heading")
+	assert.Equal(t, ptr(90.0), exact.Share(s).Heading)
+}
+
+func TestShareToken(t *testing.T) {
+	secret := []byte("share-secret")
+	id := uuid.New()
+	exp := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	tok := ShareToken(id, exp, secret)
+
+	gotID, gotExp, err := ParseShareToken(tok, secret)
+	require.NoError(t, err)
+	assert.Equal(t, id, gotID)
+	assert.True(t, exp.Equal(gotExp))
+
+	other := ShareToken(id, exp.Add(time.Hour), []byte("other"))
+	for name, bad := range map[string]string{
+		"wrong secret":    other,
+		"swapped payload": other[:len(other)-44] + tok[len(tok)-43:],
+		"no signature":    tok[:32],
+		"garbage":         "not-a-token",
+		"empty":           "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ParseShareToken(bad, secret)
+			assert.ErrorIs(t, err, ErrBadShareToken)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidStatus is wrapped by every validation failure so callers can map
+// it to a 400 without string matching.
+var ErrInvalidStatus = errors.New("invalid status")
+
+const maxStatusAttributes = 64
+
+// Validate checks the mandatory fields and the range of every optional field
+// that was reported.
+func (s Status) Validate() error {
+	lon, lat := s.Location[0], s.Location[1]
+	switch {
+	case math.IsNaN(lon) || lon < -180 || lon > 180:
+		return invalid("longitude %v out of range [-180, 180]", lon)
+	case math.IsNaN(lat) || lat < -90 || lat > 90:
+		return invalid("latitude %v out of range [-90, 90]", lat)
+	case math.IsNaN(s.Speed) || s.Speed < 0:
+		return invalid("speed %v must be >= 0", s.Speed)
+	case s.Timestamp.IsZero():
+		return invalid("timestamp is required")
+	}
+
+	if s.Heading != nil && (math.IsNaN(*s.Heading) || *s.Heading < 0 || *s.Heading >= 360) {
+		return invalid("heading %v out of range [0, 360)", *s.Heading)
+	}
+	if s.Altitude != nil && math.IsNaN(*s.Altitude) {
+		return invalid("altitude is not a number")
+	}
+	if s.HDOP != nil && (math.IsNaN(*s.HDOP) || *s.HDOP < 0) {
+		return invalid("hdop %v must be >= 0", *s.HDOP)
+	}
+	if s.Satellites != nil && *s.Satellites < 0 {
+		return invalid("satellites %d must be >= 0", *s.Satellites)
+	}
+	if s.Odometer != nil && (math.IsNaN(*s.Odometer) || *s.Odometer < 0) {
+		return invalid("odometer %v must be >= 0", *s.Odometer)
+	}
+	if s.ExtVoltage != nil && (math.IsNaN(*s.ExtVoltage) || *s.ExtVoltage < 0) {
+		return invalid("ext_voltage %v must be >= 0", *s.ExtVoltage)
+	}
+	if len(s.Attributes) > maxStatusAttributes {
+		return invalid("too many attributes (%d > %d)", len(s.Attributes), maxStatusAttributes)
+	}
+	for k := range s.Attributes {
+		if k == "" {
+			return invalid("attribute names must not be empty")
+		}
+	}
+	return nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidStatus, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestStatus_Validate(t *testing.T) {
+	base := func() Status {
+		return Status{
+			Location:  [2]float64{55.2967, 25.2767},
+			Speed:     72.5,
+			Timestamp: time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(s *Status)
+		wantErr bool
+	}{
+		{name: "positive - legacy three fields", mutate: func(s *Status) {}},
+		{
+			name: "positive - all optional fields",
+			mutate: func(s *Status) {
+				s.Heading = ptr(359.9)
+				s.Altitude = ptr(-12.0)
+				s.HDOP = ptr(0.8)
+				s.Satellites = ptr(11)
+				s.Odometer = ptr(120345.6)
+				s.Ignition = ptr(true)
+				s.ExtVoltage = ptr(13.8)
+				s.Attributes = map[string]any{"din1": true, "fuel_raw": 512}
+			},
+		},
+		{name: "negative - longitude out of range", mutate: func(s *Status) { s.Location[0] = 181 }, wantErr: true},
+		{name: "negative - latitude out of range", mutate: func(s *Status) { s.Location[1] = -91 }, wantErr: true},
+		{name: "negative - NaN speed", mutate: func(s *Status) { s.Speed = math.NaN() }, wantErr: true},
+		{name: "negative - negative speed", mutate: func(s *Status) { s.Speed = -1 }, wantErr: true},
+		{name: "negative - missing timestamp", mutate: func(s *Status) { s.Timestamp = time.Time{} }, wantErr: true},
+		{name: "negative - heading 360", mutate: func(s *Status) { s.Heading = ptr(360.0) }, wantErr: true},
+		{name: "negative - negative hdop", mutate: func(s *Status) { s.HDOP = ptr(-0.1) }, wantErr: true},
+		{name: "negative - negative satellites", mutate: func(s *Status) { s.Satellites = ptr(-1) }, wantErr: true},
+		{name: "negative - empty attribute name", mutate: func(s *Status) { s.Attributes = map[string]any{"": 1} }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := base()
+			tt.mutate(&s)
+
+			err := s.Validate()
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidStatus)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStatus_JSONBackwardCompatible(t *testing.T) {
+	raw := `{"location":[55.2967,25.2767],"speed":72.5,"timestamp":"2025-06-26T03:11:00Z"}`
+
+	var s Status
+	require.NoError(t, json.Unmarshal([]byte(raw), &s))
+	assert.Nil(t, s.Heading)
+	assert.Nil(t, s.Ignition)
+	assert.NoError(t, s.Validate())
+
+	out, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, raw, string(out))
+}
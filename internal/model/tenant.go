@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is a customer organisation. Every other table carries a tenant_id
+// and is only ever read through a context scoped to one tenant.
+type Tenant struct {
+	ID        uuid.UUID `json:"id"   gorm:"type:uuid;primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Tenant) TableName() string { return "tenants" }
@@ -8,7 +8,9 @@ import (
 // Trips maps to "trips" table.
 type Trips struct {
 	ID        uuid.UUID  `json:"id"         gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID  `json:"-"          gorm:"type:uuid;index"`
 	VehicleID uuid.UUID  `json:"vehicle_id" gorm:"type:uuid;index"`
+	DriverID  *uuid.UUID `json:"driver_id,omitempty" gorm:"type:uuid;index"` // driver on shift at StartTime
 	StartTime time.Time  `json:"start_time"`
 	EndTime   *time.Time `json:"end_time,omitempty"`
 	Mileage   float64    `json:"mileage"`
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ErrInvalidUser is wrapped by every user validation failure.
+var ErrInvalidUser = errors.New("invalid user")
+
+// Password length limits; bcrypt ignores everything past 72 bytes.
+const (
+	MinPasswordLength = 10
+	MaxPasswordLength = 72
+)
+
+// User is a person who logs in with email and password. Tokens issued at
+// login carry the user id as subject and the user's roles and scopes.
+type User struct {
+	ID           uuid.UUID                   `json:"id"                   gorm:"type:uuid;primaryKey"`
+	TenantID     uuid.UUID                   `json:"-"                    gorm:"type:uuid;uniqueIndex:idx_users_tenant_email,priority:1"`
+	Email        string                      `json:"email"                gorm:"uniqueIndex:idx_users_tenant_email"`
+	Name         string                      `json:"name"`
+	PasswordHash string                      `json:"-"`
+	Roles        datatypes.JSONSlice[string] `json:"roles"`
+	Scopes       datatypes.JSONSlice[string] `json:"scopes,omitempty"`
+	Restricted   bool                        `json:"restricted"`
+
+	// TOTPSecret is set when enrolment starts and only enforced once
+	// TOTPEnabled; TOTPLastStep stops a code from being used twice.
+	TOTPSecret   string `json:"-"`
+	TOTPEnabled  bool   `json:"totp_enabled"`
+	TOTPLastStep int64  `json:"-"`
+
+	FailedLogins int        `json:"-"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (User) TableName() string { return "users" }
+
+// Subject is the JWT sub of the user's tokens and the subject of their access grants.
+func (u User) Subject() string { return u.ID.String() }
+
+// LockedAt reports whether logins are refused at ts.
+func (u User) LockedAt(ts time.Time) bool {
+	return u.LockedUntil != nil && ts.Before(*u.LockedUntil)
+}
+
+// Normalize trims the name and lower-cases the email.
+func (u *User) Normalize() {
+	u.Name = strings.TrimSpace(u.Name)
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+}
+
+// Validate requires a parseable email. Roles and scopes are checked by the
+// service against package auth.
+func (u User) Validate() error {
+	if u.Email == "" {
+		return fmt.Errorf("%w: email is required", ErrInvalidUser)
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return fmt.Errorf("%w: email %q is not valid", ErrInvalidUser, u.Email)
+	}
+	if len(u.Name) > 200 {
+		return fmt.Errorf("%w: name is longer than 200 characters", ErrInvalidUser)
+	}
+	return nil
+}
+
+// ValidatePassword enforces the length limits.
+func ValidatePassword(p string) error {
+	switch {
+	case len(p) < MinPasswordLength:
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, MinPasswordLength)
+	case len(p) > MaxPasswordLength:
+		return fmt.Errorf("%w: password must be at most %d bytes", ErrInvalidUser, MaxPasswordLength)
+	}
+	return nil
+}
+
+// UserPatch is the body of user create and update calls; nil fields are
+// left unchanged.
+type UserPatch struct {
+	Email      *string   `json:"email"`
+	Name       *string   `json:"name"`
+	Roles      *[]string `json:"roles"`
+	Scopes     *[]string `json:"scopes"`
+	Restricted *bool     `json:"restricted"`
+}
+
+// Apply copies the set fields onto u.
+func (p UserPatch) Apply(u *User) {
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+	if p.Name != nil {
+		u.Name = *p.Name
+	}
+	if p.Roles != nil {
+		u.Roles = *p.Roles
+	}
+	if p.Scopes != nil {
+		u.Scopes = *p.Scopes
+	}
+	if p.Restricted != nil {
+		u.Restricted = *p.Restricted
+	}
+}
+
+// PasswordReset is a single-use token that lets a user set a new password.
+// Only the SHA-256 of its secret is stored.
+type PasswordReset struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID   uuid.UUID `gorm:"type:uuid;index"`
+	UserID     uuid.UUID `gorm:"type:uuid;index"`
+	SecretHash string
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+}
+
+func (PasswordReset) TableName() string { return "password_resets" }
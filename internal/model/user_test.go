@@ -0,0 +1,51 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    User
+		wantErr bool
+	}{
+		{name: "positive - email only", user: User{Email: "alice@example.com"}},
+		{name: "negative - no email", user: User{Name: "Alice"}, wantErr: true},
+		{name: "negative - display name form", user: User{Email: "Alice <alice@example.com>"}, wantErr: true},
+		{name: "negative - not an address", user: User{Email: "alice"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	u := User{Email: " Alice@Example.COM ", Name: " Alice "}
+	u.Normalize()
+	assert.Equal(t, "alice@example.com", u.Email)
+	assert.Equal(t, "Alice", u.Name)
+}
+
+func TestValidatePassword(t *testing.T) {
+	assert.NoError(t, ValidatePassword("correct horse"))
+	assert.ErrorIs(t, ValidatePassword("short"), ErrInvalidUser)
+	assert.ErrorIs(t, ValidatePassword(strings.Repeat("x", 73)), ErrInvalidUser, "bcrypt would truncate it")
+}
+
+func TestUser_LockedAt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Minute)
+	assert.False(t, User{}.LockedAt(now))
+	assert.True(t, User{LockedUntil: &until}.LockedAt(now))
+	assert.False(t, User{LockedUntil: &until}.LockedAt(until))
+}
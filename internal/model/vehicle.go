@@ -9,18 +9,61 @@ import (
 )
 
 // Status is the JSON blob we cache & store.
+//
+// Only location, speed and timestamp are mandatory; everything else is
+// optional so that trackers sending the original three fields keep working.
+// Pointer fields distinguish "not reported" from a genuine zero value.
 type Status struct {
 	Location  [2]float64 `json:"location"` // [long, lat]
-	Speed     float64    `json:"speed"`
+	Speed     float64    `json:"speed"`    // km/h
 	Timestamp time.Time  `json:"timestamp"`
+
+	Heading    *float64 `json:"heading,omitempty"`     // degrees clockwise from north, [0, 360)
+	Altitude   *float64 `json:"altitude,omitempty"`    // metres above sea level
+	HDOP       *float64 `json:"hdop,omitempty"`        // horizontal dilution of precision
+	Satellites *int     `json:"satellites,omitempty"`  // satellites used in the fix
+	Odometer   *float64 `json:"odometer,omitempty"`    // km, as reported by the device
+	Ignition   *bool    `json:"ignition,omitempty"`    // engine/ignition line state
+	ExtVoltage *float64 `json:"ext_voltage,omitempty"` // external power supply, volts
+
+	// Attributes carries free-form device IO (digital inputs, sensor readings …).
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 // Vehicle maps to the "vehicle" table.
 // The struct tags satisfy both JSON (HTTP responses) and GORM.
 type Vehicle struct {
 	ID          uuid.UUID      `json:"id"           gorm:"type:uuid;primaryKey"`
-	PlateNumber string         `json:"plate_number" gorm:"uniqueIndex"`
+	TenantID    uuid.UUID      `json:"-"            gorm:"type:uuid;uniqueIndex:idx_vehicle_tenant_plate,priority:1;uniqueIndex:idx_vehicle_tenant_vin,priority:1"`
+	PlateNumber string         `json:"plate_number" gorm:"uniqueIndex:idx_vehicle_tenant_plate"`
 	LastStatus  datatypes.JSON `json:"last_status"`
+
+	// Registry metadata. Vehicles that only ever appeared through ingest
+	// leave all of it empty; ingest never overwrites it.
+	VIN            *string                     `json:"vin,omitempty" gorm:"uniqueIndex:idx_vehicle_tenant_vin"`
+	Make           string                      `json:"make,omitempty"`
+	Model          string                      `json:"model,omitempty"`
+	Year           int                         `json:"year,omitempty"`
+	FuelType       string                      `json:"fuel_type,omitempty"`
+	CapacityKg     float64                     `json:"capacity_kg,omitempty"`      // payload
+	TankLiters     float64                     `json:"tank_liters,omitempty"`      // fuel tank size
+	OdometerBaseKm float64                     `json:"odometer_base_km,omitempty"` // odometer reading at registration
+	Attributes     datatypes.JSONMap           `json:"attributes,omitempty"`
+	Tags           datatypes.JSONSlice[string] `json:"tags,omitempty"`
+	ArchivedAt     *time.Time                  `json:"archived_at,omitempty"`
+	CreatedAt      time.Time                   `json:"created_at"`
+	UpdatedAt      time.Time                   `json:"updated_at"`
+
+	// Warnings flags metadata that disagrees with the decoded VIN; it is
+	// only filled in on create and update responses.
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
+}
+
+// VehicleStatus is one entry of a fleet-wide status listing.
+type VehicleStatus struct {
+	VehicleID   uuid.UUID `json:"vehicle_id"`
+	PlateNumber string    `json:"plate_number"`
+	Status      Status    `json:"status"`
 }
 
 func (v *Vehicle) DecodeStatus() (Status, error) {
@@ -29,8 +72,16 @@ func (v *Vehicle) DecodeStatus() (Status, error) {
 	return s, err
 }
 
+// InputRequestPayload is one ingest call. Trackers registered as devices may
+// send device_id or imei instead of vehicle_id; the vehicle is then resolved
+// from the device's assignment at the fix timestamp. DriverTag is the
+// iButton/RFID id read by the tracker, if any.
 type InputRequestPayload struct {
-	VehicleID   uuid.UUID `json:"vehicle_id"`
-	PlateNumber string    `json:"plate_number"`
-	Status      Status    `json:"status"`
+	VehicleID   uuid.UUID       `json:"vehicle_id"`
+	DeviceID    *uuid.UUID      `json:"device_id,omitempty"`
+	IMEI        string          `json:"imei,omitempty"`
+	PlateNumber string          `json:"plate_number"`
+	DriverTag   string          `json:"driver_tag,omitempty"`
+	Status      Status          `json:"status"`
+	Diagnostics *RawDiagnostics `json:"diagnostics,omitempty"`
 }
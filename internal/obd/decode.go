@@ -0,0 +1,74 @@
+package obd
+
+import (
+	"fmt"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+// Result is everything decoded from one RawDiagnostics block.
+type Result struct {
+	Values []Value
+	// DTCs is nil when the payload did not report trouble codes at all and
+	// non-nil (possibly empty) when it reported the full active set.
+	DTCs []string
+}
+
+// Decode decodes every PID, PGN and DTC of a payload. Any malformed entry
+// fails the whole block so a half-understood frame is never stored.
+func Decode(d model.RawDiagnostics) (Result, error) {
+	var res Result
+	seen := map[string]bool{}
+	addDTC := func(code string) {
+		if !seen[code] {
+			seen[code] = true
+			res.DTCs = append(res.DTCs, code)
+		}
+	}
+
+	for _, p := range d.OBD {
+		v, ok, err := DecodePID(p.PID, p.Data)
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			res.Values = append(res.Values, v)
+		}
+	}
+
+	for _, p := range d.J1939 {
+		if p.PGN == pgnDM1 {
+			codes, err := DecodeDM1(p.Data)
+			if err != nil {
+				return Result{}, err
+			}
+			if res.DTCs == nil {
+				res.DTCs = []string{}
+			}
+			for _, c := range codes {
+				addDTC(c)
+			}
+			continue
+		}
+		vs, err := DecodePGN(p.PGN, p.Data)
+		if err != nil {
+			return Result{}, fmt.Errorf("pgn %d: %w", p.PGN, err)
+		}
+		res.Values = append(res.Values, vs...)
+	}
+
+	if d.DTCs != nil && res.DTCs == nil {
+		res.DTCs = []string{}
+	}
+	for _, raw := range d.DTCs {
+		code, err := DecodeDTC(raw)
+		if err != nil {
+			return Result{}, err
+		}
+		if code == "P0000" {
+			continue // padding in service 03 responses
+		}
+		addDTC(code)
+	}
+	return res, nil
+}
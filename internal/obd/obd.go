@@ -0,0 +1,183 @@
+// Package obd turns raw OBD-II mode 01 PIDs and SAE J1939 PGNs into named,
+// unit-converted engineering values, and decodes diagnostic trouble codes.
+//
+// Only the signals we act on are in the tables below; unknown PIDs/PGNs are
+// ignored rather than rejected so devices can send more than we understand.
+package obd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformed is wrapped by every decoding failure.
+var ErrMalformed = errors.New("malformed diagnostic data")
+
+// Value is one decoded engineering value.
+type Value struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+type pidDef struct {
+	name   string
+	unit   string
+	length int
+	decode func(b []byte) float64
+}
+
+// OBD-II service 01 PIDs (SAE J1979). A, B, C, D are the data bytes.
+var pids = map[byte]pidDef{
+	0x04: {"engine_load", "%", 1, func(b []byte) float64 { return float64(b[0]) * 100 / 255 }},
+	0x05: {"coolant_temp", "C", 1, func(b []byte) float64 { return float64(b[0]) - 40 }},
+	0x0B: {"intake_manifold_pressure", "kPa", 1, func(b []byte) float64 { return float64(b[0]) }},
+	0x0C: {"engine_rpm", "rpm", 2, func(b []byte) float64 { return float64(u16be(b)) / 4 }},
+	0x0D: {"vehicle_speed", "km/h", 1, func(b []byte) float64 { return float64(b[0]) }},
+	0x0F: {"intake_air_temp", "C", 1, func(b []byte) float64 { return float64(b[0]) - 40 }},
+	0x10: {"maf_rate", "g/s", 2, func(b []byte) float64 { return float64(u16be(b)) / 100 }},
+	0x11: {"throttle_position", "%", 1, func(b []byte) float64 { return float64(b[0]) * 100 / 255 }},
+	0x1F: {"run_time", "s", 2, func(b []byte) float64 { return float64(u16be(b)) }},
+	0x2F: {"fuel_level", "%", 1, func(b []byte) float64 { return float64(b[0]) * 100 / 255 }},
+	0x42: {"control_module_voltage", "V", 2, func(b []byte) float64 { return float64(u16be(b)) / 1000 }},
+	0x46: {"ambient_air_temp", "C", 1, func(b []byte) float64 { return float64(b[0]) - 40 }},
+	0x5C: {"engine_oil_temp", "C", 1, func(b []byte) float64 { return float64(b[0]) - 40 }},
+	0x5E: {"fuel_rate", "L/h", 2, func(b []byte) float64 { return float64(u16be(b)) / 20 }},
+	0xA6: {"odometer", "km", 4, func(b []byte) float64 { return float64(binary.BigEndian.Uint32(b)) / 10 }},
+}
+
+// spnDef describes one SPN inside a PGN (SAE J1939-71). Offsets are
+// zero-based; multi-byte values are little-endian as on the wire.
+type spnDef struct {
+	name   string
+	unit   string
+	offset int
+	length int // 1, 2 or 4 bytes
+	scale  float64
+	bias   float64
+}
+
+const pgnDM1 = 65226 // active diagnostic trouble codes
+
+var pgns = map[uint32][]spnDef{
+	61444: {{"engine_rpm", "rpm", 3, 2, 0.125, 0}},                                              // EEC1, SPN 190
+	65262: {{"coolant_temp", "C", 0, 1, 1, -40}, {"engine_oil_temp", "C", 2, 2, 0.03125, -273}}, // ET1, SPN 110/175
+	65265: {{"vehicle_speed", "km/h", 1, 2, 1.0 / 256, 0}},                                      // CCVS, SPN 84
+	65266: {{"fuel_rate", "L/h", 0, 2, 0.05, 0}},                                                // LFE, SPN 183
+	65253: {{"engine_hours", "h", 0, 4, 0.05, 0}},                                               // HOURS, SPN 247
+	65271: {{"battery_voltage", "V", 4, 2, 0.05, 0}},                                            // VEP1, SPN 168
+	65276: {{"fuel_level", "%", 1, 1, 0.4, 0}},                                                  // DD, SPN 96
+}
+
+// DecodePID decodes a service 01 response. ok is false for PIDs we don't know.
+func DecodePID(pidHex, dataHex string) (v Value, ok bool, err error) {
+	pid, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(pidHex), "0x"), 16, 8)
+	if err != nil {
+		return Value{}, false, fmt.Errorf("%w: pid %q", ErrMalformed, pidHex)
+	}
+	data, err := decodeHex(dataHex)
+	if err != nil {
+		return Value{}, false, err
+	}
+	def, known := pids[byte(pid)]
+	if !known {
+		return Value{}, false, nil
+	}
+	if len(data) < def.length {
+		return Value{}, false, fmt.Errorf("%w: pid %02X needs %d bytes, got %d", ErrMalformed, pid, def.length, len(data))
+	}
+	return Value{Name: def.name, Value: def.decode(data[:def.length]), Unit: def.unit}, true, nil
+}
+
+// DecodePGN decodes the SPNs we know from one 8-byte J1939 frame. Signals the
+// ECU flags as "not available" or "error" are skipped.
+func DecodePGN(pgn uint32, dataHex string) ([]Value, error) {
+	data, err := decodeHex(dataHex)
+	if err != nil {
+		return nil, err
+	}
+	var res []Value
+	for _, def := range pgns[pgn] {
+		if len(data) < def.offset+def.length {
+			return nil, fmt.Errorf("%w: pgn %d too short (%d bytes)", ErrMalformed, pgn, len(data))
+		}
+		raw, valid := readSPN(data[def.offset:], def.length)
+		if !valid {
+			continue
+		}
+		res = append(res, Value{Name: def.name, Value: float64(raw)*def.scale + def.bias, Unit: def.unit})
+	}
+	return res, nil
+}
+
+// DecodeDM1 returns the active faults of a J1939 DM1 frame as "SPN<n>-FMI<m>".
+func DecodeDM1(dataHex string) ([]string, error) {
+	data, err := decodeHex(dataHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 6 {
+		return nil, fmt.Errorf("%w: dm1 too short (%d bytes)", ErrMalformed, len(data))
+	}
+	var codes []string
+	// bytes 0-1 are lamp status, then 4 bytes per DTC
+	for i := 2; i+4 <= len(data); i += 4 {
+		d := data[i : i+4]
+		spn := uint32(d[0]) | uint32(d[1])<<8 | uint32(d[2]&0xE0)<<11
+		fmi := d[2] & 0x1F
+		if spn == 0 && fmi == 0 {
+			continue // "no active faults" filler
+		}
+		if spn == 0x7FFFF {
+			continue // padding
+		}
+		codes = append(codes, fmt.Sprintf("SPN%d-FMI%d", spn, fmi))
+	}
+	return codes, nil
+}
+
+// DecodeDTC turns a service 03 two-byte word ("0301") into its SAE J2012
+// form ("P0301"). Codes already in that form are normalised and returned.
+func DecodeDTC(raw string) (string, error) {
+	raw = strings.ToUpper(strings.TrimSpace(raw))
+	if len(raw) == 5 && strings.ContainsRune("PCBU", rune(raw[0])) {
+		if _, err := strconv.ParseUint(raw[1:], 16, 16); err != nil {
+			return "", fmt.Errorf("%w: dtc %q", ErrMalformed, raw)
+		}
+		return raw, nil
+	}
+	b, err := decodeHex(raw)
+	if err != nil || len(b) != 2 {
+		return "", fmt.Errorf("%w: dtc %q", ErrMalformed, raw)
+	}
+	system := "PCBU"[b[0]>>6]
+	return fmt.Sprintf("%c%d%X%02X", system, (b[0]>>4)&0x3, b[0]&0x0F, b[1]), nil
+}
+
+func readSPN(b []byte, length int) (uint32, bool) {
+	switch length {
+	case 1:
+		return uint32(b[0]), b[0] <= 0xFA
+	case 2:
+		v := binary.LittleEndian.Uint16(b)
+		return uint32(v), v <= 0xFAFF
+	default:
+		v := binary.LittleEndian.Uint32(b)
+		return v, v <= 0xFAFFFFFF
+	}
+}
+
+func u16be(b []byte) uint16 { return binary.BigEndian.Uint16(b) }
+
+func decodeHex(s string) ([]byte, error) {
+	s = strings.NewReplacer(" ", "", ":", "").Replace(s)
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not hex", ErrMalformed, s)
+	}
+	return b, nil
+}
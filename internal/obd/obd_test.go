@@ -0,0 +1,116 @@
+package obd
+
+import (
+	"testing"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePID(t *testing.T) {
+	tests := []struct {
+		name      string
+		pid, data string
+		expected  Value
+		known     bool
+		expectErr bool
+	}{
+		{name: "positive - engine rpm", pid: "0C", data: "1AF8", expected: Value{"engine_rpm", 1726, "rpm"}, known: true},
+		{name: "positive - coolant temp", pid: "05", data: "7B", expected: Value{"coolant_temp", 83, "C"}, known: true},
+		{name: "positive - fuel level", pid: "0x2F", data: "FF", expected: Value{"fuel_level", 100, "%"}, known: true},
+		{name: "positive - odometer", pid: "A6", data: "00 01 E2 40", expected: Value{"odometer", 12345.6, "km"}, known: true},
+		{name: "positive - unknown pid ignored", pid: "99", data: "00", known: false},
+		{name: "negative - short data", pid: "0C", data: "1A", expectErr: true},
+		{name: "negative - bad hex", pid: "0C", data: "zz", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok, err := DecodePID(tt.pid, tt.data)
+
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrMalformed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.known, ok)
+			if tt.known {
+				assert.Equal(t, tt.expected.Name, v.Name)
+				assert.Equal(t, tt.expected.Unit, v.Unit)
+				assert.InDelta(t, tt.expected.Value, v.Value, 1e-9)
+			}
+		})
+	}
+}
+
+func TestDecodePGN(t *testing.T) {
+	// EEC1: engine speed 0x1268 * 0.125 = 589 rpm
+	vs, err := DecodePGN(61444, "FFFFFF6812FFFFFF")
+	require.NoError(t, err)
+	require.Len(t, vs, 1)
+	assert.Equal(t, "engine_rpm", vs[0].Name)
+	assert.InDelta(t, 589.0, vs[0].Value, 1e-9)
+
+	// ET1: coolant 0x7D-40 = 85 C, oil temp not available
+	vs, err = DecodePGN(65262, "7DFFFFFFFFFFFFFF")
+	require.NoError(t, err)
+	require.Len(t, vs, 1)
+	assert.Equal(t, Value{"coolant_temp", 85, "C"}, vs[0])
+
+	_, err = DecodePGN(61444, "FFFF")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestDecodeDTC(t *testing.T) {
+	tests := []struct{ raw, expected string }{
+		{"0301", "P0301"},
+		{"4123", "C0123"},
+		{"8A01", "B0A01"}, {"A301", "B2301"},
+		{"C100", "U0100"},
+		{"p0420", "P0420"},
+	}
+	for _, tt := range tests {
+		code, err := DecodeDTC(tt.raw)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, code)
+	}
+
+	_, err := DecodeDTC("XYZ")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("positive - mixed payload with dm1", func(t *testing.T) {
+		res, err := Decode(model.RawDiagnostics{
+			OBD:   []model.RawPID{{PID: "0C", Data: "1AF8"}},
+			J1939: []model.RawPGN{{PGN: 65276, Data: "FFFAFFFFFFFFFFFF"}, {PGN: 65226, Data: "0400640001FFFFFF"}},
+			DTCs:  []string{"0301", "0000", "P0301"},
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, res.Values, 2)
+		assert.ElementsMatch(t, []string{"SPN100-FMI1", "P0301"}, res.DTCs)
+	})
+
+	t.Run("positive - dtcs omitted leaves set unknown", func(t *testing.T) {
+		res, err := Decode(model.RawDiagnostics{OBD: []model.RawPID{{PID: "0D", Data: "3C"}}})
+
+		require.NoError(t, err)
+		assert.Nil(t, res.DTCs)
+	})
+
+	t.Run("positive - empty dtcs clears", func(t *testing.T) {
+		res, err := Decode(model.RawDiagnostics{DTCs: []string{}})
+
+		require.NoError(t, err)
+		assert.NotNil(t, res.DTCs)
+		assert.Empty(t, res.DTCs)
+	})
+
+	t.Run("negative - malformed pid fails the block", func(t *testing.T) {
+		_, err := Decode(model.RawDiagnostics{OBD: []model.RawPID{{PID: "0C", Data: "1"}}})
+
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+}
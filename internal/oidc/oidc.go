@@ -0,0 +1,242 @@
+// Package oidc lets the API accept tokens from an external OpenID Connect
+// identity provider. Keys come from the provider's discovery document and
+// JWKS; its claims are mapped onto the API's roles, scopes and tenants.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/keys"
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SubjectPrefix is put in front of the provider's sub so SSO users can't
+// collide with local users or cmd/token subjects.
+const SubjectPrefix = "oidc:"
+
+var ErrNoTenant = errors.New("token does not map to a tenant")
+
+// Config describes the provider and how its claims map onto ours.
+type Config struct {
+	Issuer   string // discovery runs against <Issuer>/.well-known/openid-configuration
+	ClientID string // required audience
+
+	// RolesClaim names the claim (a dotted path into nested objects, such as
+	// realm_access.roles) listing the user's groups. RoleMap turns each
+	// group into API roles; groups it doesn't list, and every group when
+	// there is no map, grant nothing.
+	RolesClaim string
+	RoleMap    map[string][]string
+
+	// TenantClaim names the claim holding the tenant; its value is looked
+	// up in TenantMap, or parsed as a UUID when there is no map. Without a
+	// claim, or when it is missing from the token, Tenant is used.
+	TenantClaim string
+	TenantMap   map[string]uuid.UUID
+	Tenant      *uuid.UUID
+
+	JWKSTTL time.Duration
+	Client  *http.Client
+}
+
+// Provider is a discovered identity provider; it implements
+// middleware.TrustedIssuer.
+type Provider struct {
+	cfg    Config
+	verify middleware.JWTConfig
+}
+
+type discovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// Discover fetches the provider's metadata and prepares its key set.
+func Discover(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.JWKSTTL == 0 {
+		cfg.JWKSTTL = 10 * time.Minute
+	}
+	url := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery: %s answered %s", url, resp.Status)
+	}
+	var doc discovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	// the spec requires an exact match; anything else is a misconfiguration
+	// or a mix-up attack
+	if doc.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", doc.Issuer, cfg.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: no jwks_uri")
+	}
+
+	jwks := keys.NewJWKS(doc.JWKSURI, cfg.JWKSTTL)
+	jwks.Client = cfg.Client
+	return &Provider{
+		cfg:    cfg,
+		verify: middleware.JWTConfig{Keys: jwks, Issuer: cfg.Issuer, Audience: cfg.ClientID},
+	}, nil
+}
+
+func (p *Provider) Issuer() string { return p.cfg.Issuer }
+
+// Verify checks signature, issuer, audience and expiry, then maps the claims.
+func (p *Provider) Verify(ctx context.Context, raw string) (auth.Claims, error) {
+	var mc jwt.MapClaims
+	if err := p.verify.Parse(ctx, raw, &mc); err != nil {
+		return auth.Claims{}, err
+	}
+	return p.Map(mc)
+}
+
+// Map turns verified provider claims into API claims.
+func (p *Provider) Map(mc jwt.MapClaims) (auth.Claims, error) {
+	sub, _ := mc.GetSubject()
+	if sub == "" {
+		return auth.Claims{}, errors.New("oidc: token has no sub")
+	}
+	var claims auth.Claims
+	claims.Subject = SubjectPrefix + sub
+	claims.Issuer = p.cfg.Issuer
+	claims.ID, _ = mc["jti"].(string)
+	if iat, err := mc.GetIssuedAt(); err == nil {
+		claims.IssuedAt = iat
+	}
+	if exp, err := mc.GetExpirationTime(); err == nil {
+		claims.ExpiresAt = exp
+	}
+
+	tid, err := p.tenant(mc)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if tid != tenant.Default {
+		claims.Tenant = tid.String()
+	}
+	claims.Roles = p.roles(mc)
+	claims.Scopes = apiScopes(mc)
+	return claims, nil
+}
+
+func (p *Provider) tenant(mc jwt.MapClaims) (uuid.UUID, error) {
+	if p.cfg.TenantClaim != "" {
+		if v := stringsAt(mc, p.cfg.TenantClaim); len(v) > 0 {
+			if id, ok := p.cfg.TenantMap[v[0]]; ok {
+				return id, nil
+			}
+			// with a map only its tenants are reachable
+			if p.cfg.TenantMap == nil {
+				if id, err := uuid.Parse(v[0]); err == nil {
+					return id, nil
+				}
+			}
+			return uuid.Nil, fmt.Errorf("%w: %s %q", ErrNoTenant, p.cfg.TenantClaim, v[0])
+		}
+	}
+	if p.cfg.Tenant != nil {
+		return *p.cfg.Tenant, nil
+	}
+	return uuid.Nil, ErrNoTenant
+}
+
+// roles maps the provider's groups; unmapped groups and unknown roles are
+// dropped. A group is never taken as a role by name: anyone able to create
+// a group called "admin" at the IdP would get it.
+func (p *Provider) roles(mc jwt.MapClaims) []string {
+	if p.cfg.RolesClaim == "" || p.cfg.RoleMap == nil {
+		return nil
+	}
+	var res []string
+	seen := map[string]bool{}
+	for _, group := range stringsAt(mc, p.cfg.RolesClaim) {
+		for _, r := range p.cfg.RoleMap[group] {
+			if !seen[r] && auth.Validate([]string{r}, nil) == nil {
+				seen[r] = true
+				res = append(res, r)
+			}
+		}
+	}
+	return res
+}
+
+// apiScopes keeps the entries of the OAuth "scope" claim that are API scopes,
+// so an access token granted e.g. "openid vehicle:read" can read.
+func apiScopes(mc jwt.MapClaims) []string {
+	var res []string
+	for _, v := range stringsAt(mc, "scope") {
+		for _, sc := range strings.Fields(v) {
+			if auth.Validate(nil, []string{sc}) == nil {
+				res = append(res, sc)
+			}
+		}
+	}
+	return res
+}
+
+// stringsAt reads a string or list of strings at a dotted path.
+func stringsAt(mc jwt.MapClaims, path string) []string {
+	var v any = map[string]any(mc)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		res := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// ParseMap reads "key=value,key=value" (the OIDC_ROLE_MAP format); a key may
+// repeat to map one group to several roles.
+func ParseMap(s string) (map[string][]string, error) {
+	res := map[string][]string{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(entry, "=")
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("%q: want key=value", entry)
+		}
+		res[k] = append(res[k], v)
+	}
+	return res, nil
+}
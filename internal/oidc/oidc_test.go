@@ -0,0 +1,193 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/middleware"
+	"github.com/aditi2420/fleet-tracker/internal/oidc/oidctest"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	idp := oidctest.New()
+	defer idp.Close()
+
+	p, err := Discover(context.Background(), Config{Issuer: idp.Issuer(), ClientID: "fleet"})
+	require.NoError(t, err)
+	assert.Equal(t, idp.Issuer(), p.Issuer())
+
+	// a trailing slash changes the issuer, which must match exactly
+	_, err = Discover(context.Background(), Config{Issuer: idp.Issuer() + "/", ClientID: "fleet"})
+	assert.Error(t, err)
+
+	_, err = Discover(context.Background(), Config{Issuer: idp.Issuer() + "/nope", ClientID: "fleet"})
+	assert.Error(t, err)
+}
+
+func TestProvider_Verify(t *testing.T) {
+	idp := oidctest.New()
+	defer idp.Close()
+
+	acme, fallback := uuid.New(), uuid.New()
+	p, err := Discover(context.Background(), Config{
+		Issuer:      idp.Issuer(),
+		ClientID:    "fleet",
+		RolesClaim:  "realm_access.roles",
+		RoleMap:     map[string][]string{"fleet-ops": {auth.RoleDispatcher}, "it": {auth.RoleAdmin, auth.RoleViewer}},
+		TenantClaim: "org",
+		TenantMap:   map[string]uuid.UUID{"acme": acme},
+		Tenant:      &fallback,
+	})
+	require.NoError(t, err)
+
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"sub": "u1", "aud": "fleet", "jti": "j1"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	roles := func(r ...any) map[string]any { return map[string]any{"roles": r} }
+
+	tests := []struct {
+		name       string
+		token      string
+		wantErr    bool
+		wantTenant string
+		wantRoles  []string
+		wantScopes []string
+	}{
+		{
+			name:       "positive - mapped roles, scopes and tenant",
+			token:      idp.Sign(claims(jwt.MapClaims{"realm_access": roles("fleet-ops", "other"), "org": "acme", "scope": "openid vehicle:read email"})),
+			wantTenant: acme.String(),
+			wantRoles:  []string{auth.RoleDispatcher},
+			wantScopes: []string{auth.ScopeVehicleRead},
+		},
+		{
+			name:       "positive - one group, several roles",
+			token:      idp.Sign(claims(jwt.MapClaims{"realm_access": roles("it"), "org": "acme"})),
+			wantTenant: acme.String(),
+			wantRoles:  []string{auth.RoleAdmin, auth.RoleViewer},
+		},
+		{
+			name:       "positive - unmapped groups grant nothing",
+			token:      idp.Sign(claims(jwt.MapClaims{"realm_access": roles("admin", "dispatcher"), "org": "acme"})),
+			wantTenant: acme.String(),
+		},
+		{
+			name:       "positive - no tenant claim falls back",
+			token:      idp.Sign(claims(nil)),
+			wantTenant: fallback.String(),
+		},
+		{name: "negative - unknown tenant", token: idp.Sign(claims(jwt.MapClaims{"org": "globex"})), wantErr: true},
+		{name: "negative - unmapped tenant uuid", token: idp.Sign(claims(jwt.MapClaims{"org": uuid.NewString()})), wantErr: true},
+		{name: "negative - mapped tenant's uuid", token: idp.Sign(claims(jwt.MapClaims{"org": acme.String()})), wantErr: true},
+		{name: "negative - wrong audience", token: idp.Sign(claims(jwt.MapClaims{"aud": "other"})), wantErr: true},
+		{name: "negative - wrong issuer", token: idp.Sign(claims(jwt.MapClaims{"iss": "https://evil.example"})), wantErr: true},
+		{name: "negative - expired", token: idp.Sign(claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), wantErr: true},
+		{name: "negative - no sub", token: idp.Sign(jwt.MapClaims{"aud": "fleet"}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Verify(context.Background(), tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "oidc:u1", got.Subject)
+			assert.Equal(t, "j1", got.ID)
+			assert.Equal(t, tt.wantTenant, got.Tenant)
+			assert.Equal(t, tt.wantRoles, got.Roles)
+			assert.Equal(t, tt.wantScopes, got.Scopes)
+		})
+	}
+
+	t.Run("negative - token signed by another provider", func(t *testing.T) {
+		other := oidctest.New()
+		defer other.Close()
+		_, err := p.Verify(context.Background(), other.Sign(claims(jwt.MapClaims{"iss": idp.Issuer()})))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewJWT_Trusted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp := oidctest.New()
+	defer idp.Close()
+	p, err := Discover(context.Background(), Config{
+		Issuer: idp.Issuer(), ClientID: "fleet", Tenant: &tenant.Default,
+		RolesClaim: "groups", RoleMap: map[string][]string{"fleet-viewers": {auth.RoleViewer}},
+	})
+	require.NoError(t, err)
+
+	secret := []byte("local")
+	r := gin.New()
+	r.GET("/me", middleware.NewJWT(middleware.JWTConfig{Secret: secret, Trusted: []middleware.TrustedIssuer{p}}),
+		middleware.RequireScope(auth.ScopeVehicleRead), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("user"))
+		})
+
+	local, err := auth.GenerateDevToken(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "alice"},
+		Roles:            []string{auth.RoleViewer},
+	}, secret, time.Minute)
+	require.NoError(t, err)
+	// HS256 with the local secret but claiming to be the IdP
+	forged, err := auth.GenerateDevToken(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "mallory", Issuer: idp.Issuer(), Audience: jwt.ClaimStrings{"fleet"}},
+		Roles:            []string{auth.RoleAdmin},
+	}, secret, time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "positive - idp token", token: idp.Sign(jwt.MapClaims{"sub": "bob", "aud": "fleet", "groups": []string{"fleet-viewers"}}), wantCode: http.StatusOK, wantBody: "oidc:bob"},
+		{name: "negative - group named like a role", token: idp.Sign(jwt.MapClaims{"sub": "bob", "aud": "fleet", "groups": []string{"viewer"}}), wantCode: http.StatusForbidden},
+		{name: "positive - local token", token: local, wantCode: http.StatusOK, wantBody: "alice"},
+		{name: "negative - idp token without roles", token: idp.Sign(jwt.MapClaims{"sub": "bob", "aud": "fleet"}), wantCode: http.StatusForbidden},
+		{name: "negative - local secret posing as idp", token: forged, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProvider_Map_NoRoleMap(t *testing.T) {
+	p := &Provider{cfg: Config{RolesClaim: "groups", Tenant: &tenant.Default}}
+	got, err := p.Map(jwt.MapClaims{"sub": "u1", "groups": []any{auth.RoleAdmin, auth.RoleDispatcher}})
+	require.NoError(t, err)
+	assert.Empty(t, got.Roles, "group names are not roles")
+}
+
+func TestProvider_Map_TenantUUID(t *testing.T) {
+	acme := uuid.New()
+	p := &Provider{cfg: Config{TenantClaim: "org"}}
+	got, err := p.Map(jwt.MapClaims{"sub": "u1", "org": acme.String()})
+	require.NoError(t, err)
+	assert.Equal(t, acme.String(), got.Tenant, "without a map the claim is a UUID")
+}
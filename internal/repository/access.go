@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type AccessRepo struct {
+	db *gorm.DB
+}
+
+func NewAccessRepo(db *gorm.DB) *AccessRepo {
+	return &AccessRepo{db}
+}
+
+func (r *AccessRepo) Create(ctx context.Context, g *model.AccessGrant) error {
+	return r.db.WithContext(ctx).Create(g).Error
+}
+
+// List returns the grants of a subject (all subjects when empty), newest first.
+func (r *AccessRepo) List(ctx context.Context, subject string) ([]model.AccessGrant, error) {
+	q := r.db.WithContext(ctx)
+	if subject != "" {
+		q = q.Where("subject = ?", subject)
+	}
+	var res []model.AccessGrant
+	err := q.Order("created_at DESC").Find(&res).Error
+	return res, err
+}
+
+// Delete removes a grant; gorm.ErrRecordNotFound when there is none.
+func (r *AccessRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&model.AccessGrant{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// VehicleIDs resolves the vehicles a subject may see at ts: directly granted
+// ones plus the members of granted groups and their descendants.
+func (r *AccessRepo) VehicleIDs(ctx context.Context, subject string, at time.Time) ([]uuid.UUID, error) {
+	var grants []model.AccessGrant
+	err := r.db.WithContext(ctx).
+		Where("subject = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", subject, at, at).
+		Find(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uuid.UUID]bool{}
+	var ids, groupIDs []uuid.UUID
+	for _, g := range grants {
+		switch {
+		case g.VehicleID != nil && !seen[*g.VehicleID]:
+			seen[*g.VehicleID] = true
+			ids = append(ids, *g.VehicleID)
+		case g.GroupID != nil:
+			groupIDs = append(groupIDs, *g.GroupID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return ids, nil
+	}
+
+	var paths []string
+	if err := r.db.WithContext(ctx).Model(&model.VehicleGroup{}).
+		Where("id IN ?", groupIDs).
+		Pluck("path", &paths).Error; err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return ids, nil
+	}
+	subtree := r.db.WithContext(ctx).Model(&model.VehicleGroup{}).Select("id")
+	cond := r.db.Where("path LIKE ?", paths[0]+"%")
+	for _, p := range paths[1:] {
+		cond = cond.Or("path LIKE ?", p+"%")
+	}
+	var members []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&model.GroupMember{}).
+		Distinct("vehicle_id").
+		Where("group_id IN (?)", subtree.Where(cond)).
+		Pluck("vehicle_id", &members).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range members {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
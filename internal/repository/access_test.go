@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAccessRepo_VehicleIDs(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAccessRepo(db)
+	groups := NewGroupRepo(db)
+	ctx := context.Background()
+
+	depot := model.VehicleGroup{ID: uuid.New(), Name: "depot"}
+	require.NoError(t, groups.Create(ctx, &depot))
+	team := model.VehicleGroup{ID: uuid.New(), Name: "team", ParentID: &depot.ID}
+	require.NoError(t, groups.Create(ctx, &team))
+
+	direct, inDepot, inTeam, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	require.NoError(t, groups.AddMembers(ctx, depot.ID, []uuid.UUID{inDepot}))
+	require.NoError(t, groups.AddMembers(ctx, team.ID, []uuid.UUID{inTeam, direct}))
+
+	t0 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(48 * time.Hour)
+	grant := func(subject string, vehicle, group *uuid.UUID, from time.Time, to *time.Time) {
+		g := model.AccessGrant{ID: uuid.New(), Subject: subject, VehicleID: vehicle, GroupID: group, From: from, To: to}
+		require.NoError(t, repo.Create(ctx, &g))
+	}
+	grant("alice", &direct, nil, t0, nil)
+	grant("alice", nil, &team.ID, t0, &t1)
+	grant("bob", nil, &depot.ID, t0, nil)
+	grant("carol", &other, nil, t1, nil)
+
+	tests := []struct {
+		name    string
+		subject string
+		at      time.Time
+		want    []uuid.UUID
+	}{
+		{name: "vehicle and group grants merged", subject: "alice", at: t0.Add(time.Hour), want: []uuid.UUID{direct, inTeam}},
+		{name: "group grant expired", subject: "alice", at: t1, want: []uuid.UUID{direct}},
+		{name: "group grant covers descendants", subject: "bob", at: t0, want: []uuid.UUID{direct, inDepot, inTeam}},
+		{name: "grant not started yet", subject: "carol", at: t0},
+		{name: "no grants", subject: "dave", at: t0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := repo.VehicleIDs(ctx, tt.subject, tt.at)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, ids)
+		})
+	}
+
+	all, err := repo.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+	mine, err := repo.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+
+	require.NoError(t, repo.Delete(ctx, mine[0].ID))
+	assert.ErrorIs(t, repo.Delete(ctx, mine[0].ID), gorm.ErrRecordNotFound)
+}
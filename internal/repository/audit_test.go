@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepo_Append(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditRepo(db)
+	acme := tenant.WithID(context.Background(), uuid.New())
+	globex := tenant.WithID(context.Background(), uuid.New())
+
+	t0 := time.Date(2025, 3, 1, 8, 0, 0, 123456789, time.UTC)
+	add := func(ctx context.Context, actor, action string) model.AuditEntry {
+		e := model.AuditEntry{ID: uuid.New(), At: t0, Actor: actor, Action: action, Resource: "/api/vehicles", Status: 200}
+		require.NoError(t, repo.Append(ctx, &e))
+		return e
+	}
+	a1 := add(acme, "alice", "POST /api/vehicles")
+	a2 := add(acme, "bob", "PATCH /api/vehicles/:id")
+	g1 := add(globex, "carol", "POST /api/vehicles")
+
+	// each tenant has its own chain
+	assert.Equal(t, int64(1), a1.Seq)
+	assert.Empty(t, a1.PrevHash)
+	assert.Equal(t, int64(2), a2.Seq)
+	assert.Equal(t, a1.Hash, a2.PrevHash)
+	assert.Equal(t, int64(1), g1.Seq)
+
+	chain, err := repo.Chain(acme, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	for _, e := range chain {
+		assert.Equal(t, e.Hash, e.ComputeHash(), "hash survives the round trip")
+	}
+
+	// rewriting history is visible
+	require.NoError(t, db.Model(&model.AuditEntry{}).Where("id = ?", a1.ID).Update("actor", "mallory").Error)
+	chain, err = repo.Chain(acme, 0, 10)
+	require.NoError(t, err)
+	assert.NotEqual(t, chain[0].Hash, chain[0].ComputeHash())
+}
+
+func TestAuditRepo_AppendConcurrent(t *testing.T) {
+	db := setupTestDB(t)
+	// one connection: every :memory: connection is a database of its own.
+	// Each Append's transaction holds it, which serialises appends the way
+	// the advisory lock does on Postgres; more appends than the old retry
+	// budget must all land.
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	repo := NewAuditRepo(db)
+	ctx := tenant.WithID(context.Background(), uuid.New())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := model.AuditEntry{ID: uuid.New(), At: time.Now(), Action: "POST /api/groups"}
+			assert.NoError(t, repo.Append(ctx, &e))
+		}()
+	}
+	wg.Wait()
+
+	chain, err := repo.Chain(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, chain, 50)
+	for i, e := range chain {
+		assert.Equal(t, int64(i+1), e.Seq)
+		if i > 0 {
+			assert.Equal(t, chain[i-1].Hash, e.PrevHash)
+		}
+	}
+}
+
+func TestAuditRepo_List(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditRepo(db)
+	ctx := tenant.WithID(context.Background(), uuid.New())
+	other := tenant.WithID(context.Background(), uuid.New())
+
+	t0 := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	vid := uuid.NewString()
+	for i, e := range []model.AuditEntry{
+		{Actor: "alice", Action: "GET /api/vehicle/status", Resource: "/api/vehicle/status", ResourceID: vid},
+		{Actor: "bob", Action: "GET /api/vehicle/history", Resource: "/api/vehicle/history", ResourceID: vid},
+		{Actor: "alice", Action: "PATCH /api/vehicles/:id", Resource: "/api/vehicles/" + vid, ResourceID: vid},
+		{Actor: "alice", Action: "POST /api/groups", Resource: "/api/groups"},
+	} {
+		e.ID, e.At = uuid.New(), t0.Add(time.Duration(i)*time.Hour)
+		require.NoError(t, repo.Append(ctx, &e))
+	}
+	e := model.AuditEntry{ID: uuid.New(), At: t0, Actor: "alice", Action: "POST /api/groups", Resource: "/api/groups"}
+	require.NoError(t, repo.Append(other, &e))
+
+	tests := []struct {
+		name    string
+		filter  AuditFilter
+		wantSeq []int64
+	}{
+		{name: "all, newest first", filter: AuditFilter{}, wantSeq: []int64{4, 3, 2, 1}},
+		{name: "actor", filter: AuditFilter{Actor: "alice"}, wantSeq: []int64{4, 3, 1}},
+		{name: "action", filter: AuditFilter{Action: "POST /api/groups"}, wantSeq: []int64{4}},
+		{name: "resource prefix", filter: AuditFilter{Resource: "/api/vehicle/"}, wantSeq: []int64{2, 1}},
+		{name: "resource prefix is literal", filter: AuditFilter{Resource: "/api/vehicle_"}, wantSeq: nil},
+		{name: "who touched a vehicle", filter: AuditFilter{ResourceID: vid}, wantSeq: []int64{3, 2, 1}},
+		{name: "time window", filter: AuditFilter{From: t0.Add(time.Hour), To: t0.Add(3 * time.Hour)}, wantSeq: []int64{3, 2}},
+		{name: "paging", filter: AuditFilter{Before: 3, Limit: 1}, wantSeq: []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+			var seqs []int64
+			for _, e := range es {
+				seqs = append(seqs, e.Seq)
+			}
+			assert.Equal(t, tt.wantSeq, seqs)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type CredentialRepo struct {
+	db *gorm.DB
+}
+
+func NewCredentialRepo(db *gorm.DB) *CredentialRepo {
+	return &CredentialRepo{db}
+}
+
+// Create stores a new credential
+func (r *CredentialRepo) Create(ctx context.Context, cred model.DeviceCredential) error {
+	return r.db.WithContext(ctx).Create(&cred).Error
+}
+
+// FindActiveByKeyID returns a non-revoked credential by its public key id
+func (r *CredentialRepo) FindActiveByKeyID(ctx context.Context, keyID string) (model.DeviceCredential, error) {
+	var cred model.DeviceCredential
+	err := r.db.WithContext(ctx).
+		First(&cred, "key_id = ? AND revoked_at IS NULL", keyID).Error
+	return cred, err
+}
+
+// ListByVehicle returns every credential bound to a vehicle, revoked ones included
+func (r *CredentialRepo) ListByVehicle(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceCredential, error) {
+	var res []model.DeviceCredential
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ?", vehicleID).
+		Order("created_at DESC").
+		Find(&res).Error
+	return res, err
+}
+
+// Revoke marks a credential unusable; it returns gorm.ErrRecordNotFound when
+// there is no active credential with that key id.
+func (r *CredentialRepo) Revoke(ctx context.Context, keyID string) error {
+	res := r.db.WithContext(ctx).
+		Model(&model.DeviceCredential{}).
+		Where("key_id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListByDevice returns every credential bound to a device, revoked ones included
+func (r *CredentialRepo) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceCredential, error) {
+	var res []model.DeviceCredential
+	err := r.db.WithContext(ctx).
+		Where("device_id = ?", deviceID).
+		Order("created_at DESC").
+		Find(&res).Error
+	return res, err
+}
+
+// ListUnsealed returns the HMAC credentials stored before signing keys were
+// sealed, whose secret_hash still holds the key in the clear.
+func (r *CredentialRepo) ListUnsealed(ctx context.Context) ([]model.DeviceCredential, error) {
+	var res []model.DeviceCredential
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND sealed_key = '' AND secret_hash <> ''", model.CredentialHMAC).
+		Find(&res).Error
+	return res, err
+}
+
+// Seal replaces a credential's clear signing key with its sealed form.
+func (r *CredentialRepo) Seal(ctx context.Context, id uuid.UUID, sealed string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.DeviceCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"sealed_key": sealed, "secret_hash": ""}).Error
+}
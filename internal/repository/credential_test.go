@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialRepo_Seal(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCredentialRepo(db)
+	acme := tenant.WithID(context.Background(), uuid.New())
+	globex := tenant.WithID(context.Background(), uuid.New())
+
+	cred := func(ctx context.Context, kind, hash, sealed string) model.DeviceCredential {
+		c := model.DeviceCredential{ID: uuid.New(), KeyID: "dk_" + uuid.NewString(), VehicleID: uuid.New(), Kind: kind, SecretHash: hash, SealedKey: sealed}
+		require.NoError(t, repo.Create(ctx, c))
+		return c
+	}
+	legacyA := cred(acme, model.CredentialHMAC, model.HashSecret("a"), "")
+	legacyG := cred(globex, model.CredentialHMAC, model.HashSecret("g"), "")
+	cred(acme, model.CredentialHMAC, "", "sealed")
+	cred(acme, model.CredentialAPIKey, model.HashSecret("k"), "")
+
+	// the server seals at start, across tenants
+	sys := context.Background()
+	got, err := repo.ListUnsealed(sys)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{legacyA.ID, legacyG.ID}, []uuid.UUID{got[0].ID, got[1].ID})
+
+	require.NoError(t, repo.Seal(sys, legacyA.ID, "sealed-a"))
+	sealed, err := repo.FindActiveByKeyID(sys, legacyA.KeyID)
+	require.NoError(t, err)
+	assert.Equal(t, "sealed-a", sealed.SealedKey)
+	assert.Empty(t, sealed.SecretHash)
+
+	got, err = repo.ListUnsealed(sys)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, legacyG.ID, got[0].ID)
+}
@@ -9,15 +9,20 @@ import (
 )
 
 func New(dsn string) (*gorm.DB, error) {
-	return gorm.Open(
+	db, err := gorm.Open(
 		postgres.Open(dsn),
 		&gorm.Config{
 			Logger:      logger.Default.LogMode(logger.Warn),
 			NowFunc:     func() time.Time { return time.Now().UTC() },
 			PrepareStmt: true,
+			// unique violations come back as gorm.ErrDuplicatedKey
+			TranslateError: true,
 			NamingStrategy: schema.NamingStrategy{
 				SingularTable: true,
 			}, // optional perf
 		})
-
+	if err != nil {
+		return nil, err
+	}
+	return db, ScopeTenants(db)
 }
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type DeviceRepo struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepo(db *gorm.DB) *DeviceRepo {
+	return &DeviceRepo{db}
+}
+
+// Create registers a device; duplicate IMEI/serial surface as gorm.ErrDuplicatedKey.
+func (r *DeviceRepo) Create(ctx context.Context, d *model.Device) error {
+	return r.db.WithContext(ctx).Create(d).Error
+}
+
+func (r *DeviceRepo) Get(ctx context.Context, id uuid.UUID) (model.Device, error) {
+	var d model.Device
+	err := r.db.WithContext(ctx).First(&d, "id = ?", id).Error
+	return d, err
+}
+
+func (r *DeviceRepo) FindByIMEI(ctx context.Context, imei string) (model.Device, error) {
+	var d model.Device
+	err := r.db.WithContext(ctx).First(&d, "imei = ?", imei).Error
+	return d, err
+}
+
+// List returns devices ordered by creation time, newest first.
+func (r *DeviceRepo) List(ctx context.Context, limit, offset int) ([]model.Device, error) {
+	var res []model.Device
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&res).Error
+	return res, err
+}
+
+// Update writes every editable column of d.
+func (r *DeviceRepo) Update(ctx context.Context, d *model.Device) error {
+	res := r.db.WithContext(ctx).Model(d).
+		Select("imei", "serial", "model", "firmware", "iccid", "label", "updated_at").
+		Updates(d)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Assign closes the device's open assignment at a.From and opens a. It fails
+// with model.ErrAssignmentOverlap if a.From is not after the start (or end)
+// of the device's latest assignment, so history can only grow forwards.
+func (r *DeviceRepo) Assign(ctx context.Context, a model.DeviceAssignment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, err := latestAssignment(tx, a.DeviceID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+		case err != nil:
+			return err
+		case !a.From.After(latest.From), latest.To != nil && latest.To.After(a.From):
+			return model.ErrAssignmentOverlap
+		case latest.To == nil:
+			if err := tx.Model(&latest).Update("valid_to", a.From).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&a).Error
+	})
+}
+
+// Unassign ends the device's open assignment at the given time. It returns
+// gorm.ErrRecordNotFound when the device is not installed anywhere.
+func (r *DeviceRepo) Unassign(ctx context.Context, deviceID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, err := latestAssignment(tx, deviceID)
+		if err != nil {
+			return err
+		}
+		if latest.To != nil {
+			return gorm.ErrRecordNotFound
+		}
+		if !at.After(latest.From) {
+			return model.ErrAssignmentOverlap
+		}
+		return tx.Model(&latest).Update("valid_to", at).Error
+	})
+}
+
+// Assignments returns a device's installation history, newest first.
+func (r *DeviceRepo) Assignments(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceAssignment, error) {
+	return r.assignments(ctx, "device_id = ?", deviceID)
+}
+
+// VehicleAssignments returns every device ever installed in a vehicle, newest first.
+func (r *DeviceRepo) VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceAssignment, error) {
+	return r.assignments(ctx, "vehicle_id = ?", vehicleID)
+}
+
+// VehicleAt resolves which vehicle the device was installed in at ts.
+func (r *DeviceRepo) VehicleAt(ctx context.Context, deviceID uuid.UUID, ts time.Time) (uuid.UUID, error) {
+	var a model.DeviceAssignment
+	err := r.db.WithContext(ctx).
+		Where("device_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", deviceID, ts, ts).
+		First(&a).Error
+	return a.VehicleID, err
+}
+
+func (r *DeviceRepo) assignments(ctx context.Context, where string, id uuid.UUID) ([]model.DeviceAssignment, error) {
+	var res []model.DeviceAssignment
+	err := r.db.WithContext(ctx).
+		Where(where, id).
+		Order("valid_from DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func latestAssignment(tx *gorm.DB, deviceID uuid.UUID) (model.DeviceAssignment, error) {
+	var a model.DeviceAssignment
+	err := tx.Where("device_id = ?", deviceID).Order("valid_from DESC").First(&a).Error
+	return a, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDeviceRepo_AssignmentHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDeviceRepo(db)
+	ctx := context.Background()
+
+	imei := "490154203237518"
+	dev := model.Device{ID: uuid.New(), IMEI: &imei}
+	require.NoError(t, repo.Create(ctx, &dev))
+
+	vanA, vanB := uuid.New(), uuid.New()
+	t0 := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	swap := t0.Add(48 * time.Hour)
+	assign := func(vehicle uuid.UUID, from time.Time) error {
+		return repo.Assign(ctx, model.DeviceAssignment{ID: uuid.New(), DeviceID: dev.ID, VehicleID: vehicle, From: from})
+	}
+
+	require.NoError(t, assign(vanA, t0))
+	require.NoError(t, assign(vanB, swap))
+	assert.ErrorIs(t, assign(vanA, swap.Add(-time.Hour)), model.ErrAssignmentOverlap, "cannot rewrite history")
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		want    uuid.UUID
+		wantErr error
+	}{
+		{name: "before first install", at: t0.Add(-time.Minute), wantErr: gorm.ErrRecordNotFound},
+		{name: "in van A", at: t0.Add(time.Hour), want: vanA},
+		{name: "swap instant belongs to van B", at: swap, want: vanB},
+		{name: "buffered fix from before the swap", at: swap.Add(-time.Second), want: vanA},
+		{name: "still in van B", at: swap.Add(1000 * time.Hour), want: vanB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.VehicleAt(ctx, dev.ID, tt.at)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	removed := swap.Add(72 * time.Hour)
+	require.NoError(t, repo.Unassign(ctx, dev.ID, removed))
+	assert.ErrorIs(t, repo.Unassign(ctx, dev.ID, removed.Add(time.Hour)), gorm.ErrRecordNotFound)
+	_, err := repo.VehicleAt(ctx, dev.ID, removed)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, assign(vanA, removed.Add(-time.Hour)), model.ErrAssignmentOverlap)
+	require.NoError(t, assign(vanA, removed))
+
+	history, err := repo.Assignments(ctx, dev.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, vanA, history[0].VehicleID)
+	assert.Nil(t, history[0].To)
+	assert.Equal(t, vanB, history[1].VehicleID)
+	require.NotNil(t, history[1].To)
+	assert.True(t, removed.Equal(*history[1].To))
+
+	inB, err := repo.VehicleAssignments(ctx, vanB)
+	require.NoError(t, err)
+	assert.Len(t, inB, 1)
+
+	found, err := repo.FindByIMEI(ctx, imei)
+	require.NoError(t, err)
+	assert.Equal(t, dev.ID, found.ID)
+}
+
+func TestDeviceRepo_CreateDuplicateIMEI(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDeviceRepo(db)
+	ctx := context.Background()
+	imei := "490154203237518"
+
+	require.NoError(t, repo.Create(ctx, &model.Device{ID: uuid.New(), IMEI: &imei}))
+	err := repo.Create(ctx, &model.Device{ID: uuid.New(), IMEI: &imei})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
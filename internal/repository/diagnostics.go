@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type DiagnosticsRepo struct {
+	db *gorm.DB
+}
+
+func NewDiagnosticsRepo(db *gorm.DB) *DiagnosticsRepo {
+	return &DiagnosticsRepo{db}
+}
+
+// Diagnostics is what one fix decoded to, for UpsertStatusAndInsertTrip.
+// Codes as for Save; Events is filled with the events written.
+type Diagnostics struct {
+	Samples []model.SignalSample
+	Codes   []string
+	Events  []model.Event
+}
+
+// Save stores decoded samples and reconciles the active trouble codes in one
+// Tx, nested in tx when that is one (ingest passes its own). codes == nil
+// leaves the active set untouched; otherwise it is the full set reported at
+// ts. Returned events are the ones written.
+func (r *DiagnosticsRepo) Save(
+	ctx context.Context,
+	vehicleID uuid.UUID,
+	ts time.Time,
+	samples []model.SignalSample,
+	codes []string,
+	tx *gorm.DB,
+) ([]model.Event, error) {
+	var events []model.Event
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(samples) > 0 {
+			if err := tx.Create(&samples).Error; err != nil {
+				return err
+			}
+		}
+		if codes == nil {
+			return nil
+		}
+
+		var active []model.ActiveDTC
+		if err := tx.Where("vehicle_id = ?", vehicleID).Find(&active).Error; err != nil {
+			return err
+		}
+		reported := make(map[string]bool, len(codes))
+		for _, c := range codes {
+			reported[c] = true
+		}
+		wasActive := make(map[string]bool, len(active))
+		for _, a := range active {
+			wasActive[a.Code] = true
+			if !reported[a.Code] {
+				if err := tx.Delete(&model.ActiveDTC{}, "vehicle_id = ? AND code = ?", vehicleID, a.Code).Error; err != nil {
+					return err
+				}
+				events = append(events, newDTCEvent(vehicleID, model.EventDTCCleared, a.Code, ts, a.FirstSeen))
+			}
+		}
+		for _, c := range codes {
+			if wasActive[c] {
+				if err := tx.Model(&model.ActiveDTC{}).
+					Where("vehicle_id = ? AND code = ?", vehicleID, c).
+					Update("last_seen", ts).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Create(&model.ActiveDTC{VehicleID: vehicleID, Code: c, FirstSeen: ts, LastSeen: ts}).Error; err != nil {
+				return err
+			}
+			events = append(events, newDTCEvent(vehicleID, model.EventDTCActive, c, ts, ts))
+		}
+		if len(events) > 0 {
+			return tx.Create(&events).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListSamples fetches samples of one signal (or all when name is empty), newest first
+func (r *DiagnosticsRepo) ListSamples(
+	ctx context.Context,
+	vehicleID uuid.UUID,
+	name string,
+	from, to time.Time,
+	limit int,
+) ([]model.SignalSample, error) {
+	q := r.db.WithContext(ctx).
+		Where("vehicle_id = ? AND timestamp >= ? AND timestamp <= ?", vehicleID, from, to)
+	if name != "" {
+		q = q.Where("name = ?", name)
+	}
+	var res []model.SignalSample
+	err := q.Order("timestamp DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+// ActiveDTCs returns the codes currently raised for a vehicle
+func (r *DiagnosticsRepo) ActiveDTCs(ctx context.Context, vehicleID uuid.UUID) ([]model.ActiveDTC, error) {
+	var res []model.ActiveDTC
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ?", vehicleID).
+		Order("first_seen").
+		Find(&res).Error
+	return res, err
+}
+
+// ListEvents fetches events of a vehicle after given duration, newest first
+func (r *DiagnosticsRepo) ListEvents(ctx context.Context, vehicleID uuid.UUID, since time.Duration) ([]model.Event, error) {
+	var res []model.Event
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ? AND timestamp >= ?", vehicleID, time.Now().Add(-since)).
+		Order("timestamp DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func newDTCEvent(vehicleID uuid.UUID, typ, code string, ts, firstSeen time.Time) model.Event {
+	details, _ := json.Marshal(map[string]any{"first_seen": firstSeen})
+	return model.Event{
+		ID:        uuid.New(),
+		VehicleID: vehicleID,
+		Type:      typ,
+		Code:      code,
+		Details:   details,
+		Timestamp: ts,
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsRepo_Save(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiagnosticsRepo(db)
+	ctx := context.Background()
+	vehicleID := uuid.New()
+	t0 := time.Now().UTC().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		ts             time.Time
+		codes          []string
+		expectedEvents map[string]string // code -> event type
+		expectedActive []string
+	}{
+		{
+			name:           "positive - new codes raise events",
+			ts:             t0,
+			codes:          []string{"P0301", "P0420"},
+			expectedEvents: map[string]string{"P0301": model.EventDTCActive, "P0420": model.EventDTCActive},
+			expectedActive: []string{"P0301", "P0420"},
+		},
+		{
+			name:           "positive - still active codes are silent",
+			ts:             t0.Add(time.Minute),
+			codes:          []string{"P0301", "P0420"},
+			expectedEvents: map[string]string{},
+			expectedActive: []string{"P0301", "P0420"},
+		},
+		{
+			name:           "positive - omitted codes leave set untouched",
+			ts:             t0.Add(2 * time.Minute),
+			codes:          nil,
+			expectedEvents: map[string]string{},
+			expectedActive: []string{"P0301", "P0420"},
+		},
+		{
+			name:           "positive - missing code is cleared",
+			ts:             t0.Add(3 * time.Minute),
+			codes:          []string{"P0420"},
+			expectedEvents: map[string]string{"P0301": model.EventDTCCleared},
+			expectedActive: []string{"P0420"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := []model.SignalSample{{
+				ID: uuid.New(), VehicleID: vehicleID, Name: "engine_rpm", Timestamp: tt.ts, Value: 1726, Unit: "rpm",
+			}}
+
+			events, err := repo.Save(ctx, vehicleID, tt.ts, samples, tt.codes, db)
+
+			require.NoError(t, err)
+			got := map[string]string{}
+			for _, e := range events {
+				got[e.Code] = e.Type
+			}
+			assert.Equal(t, tt.expectedEvents, got)
+
+			active, err := repo.ActiveDTCs(ctx, vehicleID)
+			require.NoError(t, err)
+			codes := make([]string, len(active))
+			for i, a := range active {
+				codes[i] = a.Code
+			}
+			assert.ElementsMatch(t, tt.expectedActive, codes)
+		})
+	}
+
+	samples, err := repo.ListSamples(ctx, vehicleID, "engine_rpm", t0.Add(-time.Minute), time.Now(), 100)
+	require.NoError(t, err)
+	assert.Len(t, samples, len(tests))
+
+	events, err := repo.ListEvents(ctx, vehicleID, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type DriverRepo struct {
+	db *gorm.DB
+}
+
+func NewDriverRepo(db *gorm.DB) *DriverRepo {
+	return &DriverRepo{db}
+}
+
+// Create adds a driver; duplicate licence numbers/tags surface as gorm.ErrDuplicatedKey.
+func (r *DriverRepo) Create(ctx context.Context, d *model.Driver) error {
+	return r.db.WithContext(ctx).Create(d).Error
+}
+
+func (r *DriverRepo) Get(ctx context.Context, id uuid.UUID) (model.Driver, error) {
+	var d model.Driver
+	err := r.db.WithContext(ctx).First(&d, "id = ?", id).Error
+	return d, err
+}
+
+// FindByTag looks a driver up by an already normalized tag id.
+func (r *DriverRepo) FindByTag(ctx context.Context, tag string) (model.Driver, error) {
+	var d model.Driver
+	err := r.db.WithContext(ctx).First(&d, "tag = ?", tag).Error
+	return d, err
+}
+
+// List returns drivers ordered by name.
+func (r *DriverRepo) List(ctx context.Context, limit, offset int) ([]model.Driver, error) {
+	var res []model.Driver
+	err := r.db.WithContext(ctx).
+		Order("name").
+		Limit(limit).
+		Offset(offset).
+		Find(&res).Error
+	return res, err
+}
+
+// Update writes every editable column of d.
+func (r *DriverRepo) Update(ctx context.Context, d *model.Driver) error {
+	res := r.db.WithContext(ctx).Model(d).
+		Select("name", "licence_number", "licence_expiry", "phone", "email", "tag", "updated_at").
+		Updates(d)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Assign starts shift a. Whatever shift the driver or the vehicle is on at
+// a.From ends there, so a driver change (or a driver moving to another
+// vehicle) needs no explicit unassign. It fails with model.ErrAssignmentOverlap
+// if a.From is not after the start of either one's latest shift.
+func (r *DriverRepo) Assign(ctx context.Context, a model.DriverAssignment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, cond := range []struct {
+			col string
+			id  uuid.UUID
+		}{{"driver_id", a.DriverID}, {"vehicle_id", a.VehicleID}} {
+			latest, err := latestShift(tx, cond.col, cond.id)
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+			case err != nil:
+				return err
+			case !a.From.After(latest.From):
+				return model.ErrAssignmentOverlap
+			case latest.To == nil || latest.To.After(a.From):
+				if err := tx.Model(&latest).Update("valid_to", a.From).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Create(&a).Error
+	})
+}
+
+// Unassign ends the driver's running shift at the given time. It returns
+// gorm.ErrRecordNotFound when the driver is not on shift at that time.
+func (r *DriverRepo) Unassign(ctx context.Context, driverID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, err := latestShift(tx, "driver_id", driverID)
+		if err != nil {
+			return err
+		}
+		if latest.To != nil && !latest.To.After(at) {
+			return gorm.ErrRecordNotFound
+		}
+		if !at.After(latest.From) {
+			return model.ErrAssignmentOverlap
+		}
+		return tx.Model(&latest).Update("valid_to", at).Error
+	})
+}
+
+// Assignments returns a driver's shifts, newest first.
+func (r *DriverRepo) Assignments(ctx context.Context, driverID uuid.UUID) ([]model.DriverAssignment, error) {
+	return r.shifts(ctx, "driver_id = ?", driverID)
+}
+
+// VehicleAssignments returns every shift driven in a vehicle, newest first.
+func (r *DriverRepo) VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DriverAssignment, error) {
+	return r.shifts(ctx, "vehicle_id = ?", vehicleID)
+}
+
+// DriverAt resolves who was driving the vehicle at ts.
+func (r *DriverRepo) DriverAt(ctx context.Context, vehicleID uuid.UUID, ts time.Time) (uuid.UUID, error) {
+	var a model.DriverAssignment
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", vehicleID, ts, ts).
+		First(&a).Error
+	return a.DriverID, err
+}
+
+func (r *DriverRepo) shifts(ctx context.Context, where string, id uuid.UUID) ([]model.DriverAssignment, error) {
+	var res []model.DriverAssignment
+	err := r.db.WithContext(ctx).
+		Where(where, id).
+		Order("valid_from DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func latestShift(tx *gorm.DB, col string, id uuid.UUID) (model.DriverAssignment, error) {
+	var a model.DriverAssignment
+	err := tx.Where(col+" = ?", id).Order("valid_from DESC").First(&a).Error
+	return a, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDriverRepo_Shifts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDriverRepo(db)
+	ctx := context.Background()
+
+	tag := "01000012AB34CD56"
+	alice := model.Driver{ID: uuid.New(), Name: "Alice", Tag: &tag}
+	bob := model.Driver{ID: uuid.New(), Name: "Bob"}
+	require.NoError(t, repo.Create(ctx, &alice))
+	require.NoError(t, repo.Create(ctx, &bob))
+
+	truck, van := uuid.New(), uuid.New()
+	t0 := time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC)
+	handover := t0.Add(8 * time.Hour)
+	assign := func(driver, vehicle uuid.UUID, from time.Time) error {
+		return repo.Assign(ctx, model.DriverAssignment{
+			ID: uuid.New(), DriverID: driver, VehicleID: vehicle, From: from, Source: model.ShiftManual,
+		})
+	}
+
+	require.NoError(t, assign(alice.ID, truck, t0))
+	require.NoError(t, assign(bob.ID, truck, handover), "takes over the truck, ending Alice's shift")
+	require.NoError(t, assign(alice.ID, van, handover.Add(time.Hour)))
+	assert.ErrorIs(t, assign(alice.ID, truck, t0.Add(time.Hour)), model.ErrAssignmentOverlap, "cannot rewrite history")
+
+	tests := []struct {
+		name    string
+		vehicle uuid.UUID
+		at      time.Time
+		want    uuid.UUID
+		wantErr error
+	}{
+		{name: "before the first shift", vehicle: truck, at: t0.Add(-time.Minute), wantErr: gorm.ErrRecordNotFound},
+		{name: "morning shift", vehicle: truck, at: t0.Add(time.Hour), want: alice.ID},
+		{name: "handover instant belongs to Bob", vehicle: truck, at: handover, want: bob.ID},
+		{name: "van before Alice arrives", vehicle: van, at: handover, wantErr: gorm.ErrRecordNotFound},
+		{name: "van afternoon", vehicle: van, at: handover.Add(2 * time.Hour), want: alice.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.DriverAt(ctx, tt.vehicle, tt.at)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	end := handover.Add(4 * time.Hour)
+	require.NoError(t, repo.Unassign(ctx, bob.ID, end))
+	assert.ErrorIs(t, repo.Unassign(ctx, bob.ID, end.Add(time.Hour)), gorm.ErrRecordNotFound)
+	_, err := repo.DriverAt(ctx, truck, end)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	shifts, err := repo.Assignments(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, shifts, 2)
+	assert.Equal(t, van, shifts[0].VehicleID)
+	assert.Nil(t, shifts[0].To)
+	require.NotNil(t, shifts[1].To)
+	assert.True(t, handover.Equal(*shifts[1].To))
+
+	inTruck, err := repo.VehicleAssignments(ctx, truck)
+	require.NoError(t, err)
+	assert.Len(t, inTruck, 2)
+
+	found, err := repo.FindByTag(ctx, tag)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+}
+
+func TestDriverRepo_PlannedShiftEndsEarlyOnHandover(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDriverRepo(db)
+	ctx := context.Background()
+
+	truck, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	t0 := time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC)
+	planned := t0.Add(10 * time.Hour)
+	require.NoError(t, repo.Assign(ctx, model.DriverAssignment{ID: uuid.New(), DriverID: alice, VehicleID: truck, From: t0, To: &planned}))
+
+	swap := t0.Add(6 * time.Hour)
+	require.NoError(t, repo.Assign(ctx, model.DriverAssignment{ID: uuid.New(), DriverID: bob, VehicleID: truck, From: swap}))
+
+	got, err := repo.DriverAt(ctx, truck, swap.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, bob, got)
+
+	shifts, err := repo.Assignments(ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, shifts, 1)
+	assert.True(t, swap.Equal(*shifts[0].To))
+}
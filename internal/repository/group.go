@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type GroupRepo struct {
+	db *gorm.DB
+}
+
+func NewGroupRepo(db *gorm.DB) *GroupRepo {
+	return &GroupRepo{db}
+}
+
+// Create inserts g under g.ParentID (or as a root), filling in its path.
+func (r *GroupRepo) Create(ctx context.Context, g *model.VehicleGroup) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var parent model.VehicleGroup
+		if g.ParentID != nil {
+			if err := tx.First(&parent, "id = ?", *g.ParentID).Error; err != nil {
+				return err
+			}
+			if parent.Depth() >= model.MaxGroupDepth {
+				return model.ErrGroupTooDeep
+			}
+		}
+		g.Path = parent.ChildPath(g.ID)
+		return tx.Create(g).Error
+	})
+}
+
+func (r *GroupRepo) Get(ctx context.Context, id uuid.UUID) (model.VehicleGroup, error) {
+	var g model.VehicleGroup
+	err := r.db.WithContext(ctx).First(&g, "id = ?", id).Error
+	return g, err
+}
+
+// List returns every group in tree order (parents before children).
+func (r *GroupRepo) List(ctx context.Context) ([]model.VehicleGroup, error) {
+	var res []model.VehicleGroup
+	err := r.db.WithContext(ctx).Order("path").Find(&res).Error
+	return res, err
+}
+
+// Subtree returns the group and all its descendants in tree order.
+func (r *GroupRepo) Subtree(ctx context.Context, id uuid.UUID) ([]model.VehicleGroup, error) {
+	g, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var res []model.VehicleGroup
+	err = r.db.WithContext(ctx).
+		Where("path LIKE ?", g.Path+"%").
+		Order("path").
+		Find(&res).Error
+	return res, err
+}
+
+func (r *GroupRepo) Rename(ctx context.Context, id uuid.UUID, name string) error {
+	res := r.db.WithContext(ctx).
+		Model(&model.VehicleGroup{}).
+		Where("id = ?", id).
+		Update("name", name)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Move re-parents a group (nil parent makes it a root) and rewrites the
+// paths of its whole subtree.
+func (r *GroupRepo) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var g, parent model.VehicleGroup
+		if err := tx.First(&g, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if parentID != nil {
+			if err := tx.First(&parent, "id = ?", *parentID).Error; err != nil {
+				return err
+			}
+			if strings.HasPrefix(parent.Path, g.Path) {
+				return model.ErrGroupCycle
+			}
+		}
+		newPath := parent.ChildPath(g.ID)
+		var paths []string
+		if err := tx.Model(&model.VehicleGroup{}).
+			Where("path LIKE ?", g.Path+"%").
+			Pluck("path", &paths).Error; err != nil {
+			return err
+		}
+		for _, p := range paths {
+			if parent.Depth()+(model.VehicleGroup{Path: p}).Depth()-g.Depth()+1 > model.MaxGroupDepth {
+				return model.ErrGroupTooDeep
+			}
+		}
+
+		if err := tx.Model(&model.VehicleGroup{}).
+			Where("path LIKE ?", g.Path+"%").
+			Update("path", gorm.Expr("? || SUBSTR(path, ?)", newPath, len(g.Path)+1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&g).Update("parent_id", parentID).Error
+	})
+}
+
+// Delete removes a leaf group and its memberships.
+func (r *GroupRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&model.VehicleGroup{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return model.ErrGroupNotEmpty
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupMember{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.VehicleGroup{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// AddMembers puts vehicles in a group; existing memberships are kept.
+func (r *GroupRepo) AddMembers(ctx context.Context, groupID uuid.UUID, vehicleIDs []uuid.UUID) error {
+	if len(vehicleIDs) == 0 {
+		return nil
+	}
+	rows := make([]model.GroupMember, len(vehicleIDs))
+	for i, id := range vehicleIDs {
+		rows[i] = model.GroupMember{GroupID: groupID, VehicleID: id}
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&rows).Error
+}
+
+func (r *GroupRepo) RemoveMember(ctx context.Context, groupID, vehicleID uuid.UUID) error {
+	res := r.db.WithContext(ctx).
+		Delete(&model.GroupMember{}, "group_id = ? AND vehicle_id = ?", groupID, vehicleID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// VehicleIDs returns the distinct vehicles in a group, and in every group
+// below it when recursive is set.
+func (r *GroupRepo) VehicleIDs(ctx context.Context, groupID uuid.UUID, recursive bool) ([]uuid.UUID, error) {
+	q := r.db.WithContext(ctx).Model(&model.GroupMember{}).Distinct("vehicle_id")
+	if recursive {
+		g, err := r.Get(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("group_id IN (?)",
+			r.db.WithContext(ctx).Model(&model.VehicleGroup{}).Select("id").Where("path LIKE ?", g.Path+"%"))
+	} else {
+		q = q.Where("group_id = ?", groupID)
+	}
+	var ids []uuid.UUID
+	err := q.Order("vehicle_id").Pluck("vehicle_id", &ids).Error
+	return ids, err
+}
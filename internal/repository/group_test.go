@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGroupRepo_Tree(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGroupRepo(db)
+	ctx := context.Background()
+
+	create := func(name string, parent *model.VehicleGroup) model.VehicleGroup {
+		g := model.VehicleGroup{ID: uuid.New(), Name: name}
+		if parent != nil {
+			g.ParentID = &parent.ID
+		}
+		require.NoError(t, repo.Create(ctx, &g))
+		return g
+	}
+	north := create("north", nil)
+	south := create("south", nil)
+	depot := create("depot-1", &north)
+	team := create("team-a", &depot)
+
+	assert.Equal(t, "/"+north.ID.String()+"/", north.Path)
+	assert.Equal(t, north.Path+depot.ID.String()+"/"+team.ID.String()+"/", team.Path)
+	assert.Equal(t, 3, team.Depth())
+
+	v1, v2, v3 := uuid.New(), uuid.New(), uuid.New()
+	require.NoError(t, repo.AddMembers(ctx, north.ID, []uuid.UUID{v1}))
+	require.NoError(t, repo.AddMembers(ctx, team.ID, []uuid.UUID{v2, v3}))
+	require.NoError(t, repo.AddMembers(ctx, team.ID, []uuid.UUID{v2}), "re-adding is a no-op")
+	require.NoError(t, repo.AddMembers(ctx, depot.ID, []uuid.UUID{v2}), "a vehicle may be in several groups")
+
+	tests := []struct {
+		name      string
+		group     uuid.UUID
+		recursive bool
+		want      int
+	}{
+		{name: "direct members only", group: north.ID, want: 1},
+		{name: "whole subtree, deduplicated", group: north.ID, recursive: true, want: 3},
+		{name: "mid-level subtree", group: depot.ID, recursive: true, want: 2},
+		{name: "empty group", group: south.ID, recursive: true, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := repo.VehicleIDs(ctx, tt.group, tt.recursive)
+			require.NoError(t, err)
+			assert.Len(t, ids, tt.want)
+		})
+	}
+
+	// move depot (with its team) from north to south
+	require.NoError(t, repo.Move(ctx, depot.ID, &south.ID))
+	moved, err := repo.Get(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, south.Path+depot.ID.String()+"/"+team.ID.String()+"/", moved.Path)
+	ids, err := repo.VehicleIDs(ctx, south.ID, true)
+	require.NoError(t, err)
+	assert.Len(t, ids, 2)
+
+	assert.ErrorIs(t, repo.Move(ctx, south.ID, &team.ID), model.ErrGroupCycle)
+	assert.ErrorIs(t, repo.Move(ctx, south.ID, &south.ID), model.ErrGroupCycle)
+
+	// make depot a root again
+	require.NoError(t, repo.Move(ctx, depot.ID, nil))
+	sub, err := repo.Subtree(ctx, depot.ID)
+	require.NoError(t, err)
+	require.Len(t, sub, 2)
+	assert.Nil(t, sub[0].ParentID)
+	assert.Equal(t, "/"+depot.ID.String()+"/"+team.ID.String()+"/", sub[1].Path)
+
+	assert.ErrorIs(t, repo.Delete(ctx, depot.ID), model.ErrGroupNotEmpty)
+	require.NoError(t, repo.Delete(ctx, team.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, team.ID), gorm.ErrRecordNotFound)
+	require.NoError(t, repo.Delete(ctx, depot.ID))
+}
+
+func TestGroupRepo_MaxDepth(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGroupRepo(db)
+	ctx := context.Background()
+
+	var parent *model.VehicleGroup
+	var chain []model.VehicleGroup
+	for i := 0; i < model.MaxGroupDepth; i++ {
+		g := model.VehicleGroup{ID: uuid.New(), Name: "level"}
+		if parent != nil {
+			g.ParentID = &parent.ID
+		}
+		require.NoError(t, repo.Create(ctx, &g))
+		chain = append(chain, g)
+		parent = &chain[len(chain)-1]
+	}
+
+	tooDeep := model.VehicleGroup{ID: uuid.New(), Name: "too deep", ParentID: &parent.ID}
+	assert.ErrorIs(t, repo.Create(ctx, &tooDeep), model.ErrInvalidGroup)
+
+	// moving a two-level subtree under the second-deepest node overflows too
+	other := model.VehicleGroup{ID: uuid.New(), Name: "other"}
+	require.NoError(t, repo.Create(ctx, &other))
+	child := model.VehicleGroup{ID: uuid.New(), Name: "child", ParentID: &other.ID}
+	require.NoError(t, repo.Create(ctx, &child))
+	assert.ErrorIs(t, repo.Move(ctx, other.ID, &chain[len(chain)-2].ID), model.ErrInvalidGroup)
+	require.NoError(t, repo.Move(ctx, other.ID, &chain[len(chain)-3].ID))
+}
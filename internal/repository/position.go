@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type PositionRepo struct {
+	db *gorm.DB
+}
+
+func NewPositionRepo(db *gorm.DB) *PositionRepo {
+	return &PositionRepo{db}
+}
+
+// Create appends one fix to the history
+func (r *PositionRepo) Create(ctx context.Context, p model.Position, tx *gorm.DB) error {
+	return tx.WithContext(ctx).Create(&p).Error
+}
+
+// List returns fixes of a vehicle in [from, to], newest first
+func (r *PositionRepo) List(
+	ctx context.Context,
+	id uuid.UUID,
+	from, to time.Time,
+	limit int,
+) ([]model.Position, error) {
+	var res []model.Position
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ? AND timestamp >= ? AND timestamp <= ?", id, from, to).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionRepo_ListAfterIngest(t *testing.T) {
+	db := setupTestDB(t)
+	tripRepo := NewTripRepo(db)
+	vehicleRepo := NewVehicleRepo(db, tripRepo)
+	repo := NewPositionRepo(db)
+
+	vehicleID := uuid.New()
+	heading, sats, ignition := 87.5, 9, true
+	now := time.Now().UTC()
+
+	statuses := []model.Status{
+		{Location: [2]float64{55.29, 25.27}, Speed: 10, Timestamp: now.Add(-2 * time.Hour)},
+		{
+			Location:   [2]float64{55.30, 25.28},
+			Speed:      42,
+			Timestamp:  now.Add(-10 * time.Minute),
+			Heading:    &heading,
+			Satellites: &sats,
+			Ignition:   &ignition,
+			Attributes: map[string]any{"din1": true},
+		},
+	}
+	for _, st := range statuses {
+		trip := model.Trips{ID: uuid.New(), VehicleID: vehicleID, StartTime: st.Timestamp, AvgSpeed: st.Speed}
+		_, err := vehicleRepo.UpsertStatusAndInsertTrip(context.Background(), vehicleID, "POS001", st, trip, nil)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name        string
+		from, to    time.Time
+		limit       int
+		expectedLen int
+	}{
+		{name: "positive - whole window", from: now.Add(-3 * time.Hour), to: now, limit: 100, expectedLen: 2},
+		{name: "positive - recent only", from: now.Add(-time.Hour), to: now, limit: 100, expectedLen: 1},
+		{name: "positive - limit applies", from: now.Add(-3 * time.Hour), to: now, limit: 1, expectedLen: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := repo.List(context.Background(), vehicleID, tt.from, tt.to, tt.limit)
+
+			assert.NoError(t, err)
+			assert.Len(t, res, tt.expectedLen)
+
+			// newest first, extended fields survive the round trip
+			latest := res[0].Status()
+			assert.Equal(t, statuses[1].Location, latest.Location)
+			require.NotNil(t, latest.Heading)
+			assert.Equal(t, heading, *latest.Heading)
+			require.NotNil(t, latest.Satellites)
+			assert.Equal(t, sats, *latest.Satellites)
+			assert.Equal(t, true, latest.Attributes["din1"])
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestVehicleRepo_CreateConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewVehicleRepo(db, NewTripRepo(db))
+	ctx := context.Background()
+	vin := "1HGCM82633A004352"
+	require.NoError(t, repo.Create(ctx, &model.Vehicle{ID: uuid.New(), PlateNumber: "P1", VIN: &vin}))
+
+	tests := []struct {
+		name    string
+		vehicle model.Vehicle
+		wantErr error
+	}{
+		{name: "positive - new plate, no vin", vehicle: model.Vehicle{ID: uuid.New(), PlateNumber: "P2"}},
+		{name: "positive - second vehicle without vin", vehicle: model.Vehicle{ID: uuid.New(), PlateNumber: "P3"}},
+		{name: "negative - duplicate plate", vehicle: model.Vehicle{ID: uuid.New(), PlateNumber: "P1"}, wantErr: gorm.ErrDuplicatedKey},
+		{name: "negative - duplicate vin", vehicle: model.Vehicle{ID: uuid.New(), PlateNumber: "P4", VIN: &vin}, wantErr: gorm.ErrDuplicatedKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Create(ctx, &tt.vehicle)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVehicleRepo_UpsertStatusKeepsMetadata(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewVehicleRepo(db, NewTripRepo(db))
+	ctx := context.Background()
+	id := uuid.New()
+	require.NoError(t, repo.Create(ctx, &model.Vehicle{ID: id, PlateNumber: "REG1", Make: "Volvo", Year: 2021}))
+
+	st := model.Status{Location: [2]float64{55.3, 25.2}, Speed: 40, Timestamp: time.Now().UTC()}
+	current, err := repo.UpsertStatus(ctx, id, "", st, db)
+	require.NoError(t, err)
+	assert.True(t, current)
+
+	got, err := repo.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "REG1", got.PlateNumber)
+	assert.Equal(t, "Volvo", got.Make)
+	assert.Equal(t, 2021, got.Year)
+	saved, err := got.DecodeStatus()
+	require.NoError(t, err)
+	assert.Equal(t, st.Location, saved.Location)
+}
+
+func TestVehicleRepo_UpdateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewVehicleRepo(db, NewTripRepo(db))
+	ctx := context.Background()
+
+	a := model.Vehicle{ID: uuid.New(), PlateNumber: "A", Make: "Volvo", FuelType: "diesel", Tags: []string{"north"}}
+	b := model.Vehicle{ID: uuid.New(), PlateNumber: "B", Make: "Tesla", FuelType: "electric", Tags: []string{"south"}}
+	c := model.Vehicle{ID: uuid.New(), PlateNumber: "C", Make: "Volvo", FuelType: "diesel", Tags: []string{"north", "south"}}
+	for _, v := range []*model.Vehicle{&a, &b, &c} {
+		require.NoError(t, repo.Create(ctx, v))
+	}
+	now := time.Now().UTC()
+	require.NoError(t, repo.SetArchived(ctx, c.ID, &now))
+
+	a.Make = "Scania"
+	require.NoError(t, repo.Update(ctx, &a))
+	assert.ErrorIs(t, repo.Update(ctx, &model.Vehicle{ID: uuid.New(), PlateNumber: "Z"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.SetArchived(ctx, uuid.New(), nil), gorm.ErrRecordNotFound)
+
+	tests := []struct {
+		name   string
+		filter VehicleFilter
+		want   []string
+	}{
+		{name: "all active", filter: VehicleFilter{}, want: []string{"A", "B"}},
+		{name: "include archived", filter: VehicleFilter{IncludeArchived: true}, want: []string{"A", "B", "C"}},
+		{name: "by tag", filter: VehicleFilter{Tag: "south", IncludeArchived: true}, want: []string{"B", "C"}},
+		{name: "by make, case-insensitive", filter: VehicleFilter{Make: "scania"}, want: []string{"A"}},
+		{name: "by fuel", filter: VehicleFilter{FuelType: "electric"}, want: []string{"B"}},
+		{name: "paged", filter: VehicleFilter{IncludeArchived: true, Limit: 1, Offset: 1}, want: []string{"B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+			var plates []string
+			for _, v := range res {
+				plates = append(plates, v.PlateNumber)
+			}
+			assert.Equal(t, tt.want, plates)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+// SessionRepo stores refresh tokens. Refresh requests carry no access token,
+// so Get and Use run unscoped and rely on the token's own TenantID.
+type SessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepo(db *gorm.DB) *SessionRepo {
+	return &SessionRepo{db}
+}
+
+func (r *SessionRepo) Create(ctx context.Context, t *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+func (r *SessionRepo) Get(ctx context.Context, id uuid.UUID) (model.RefreshToken, error) {
+	var t model.RefreshToken
+	err := r.db.WithContext(ctx).First(&t, "id = ?", id).Error
+	return t, err
+}
+
+// Use marks the token used; false when another request got there first.
+func (r *SessionRepo) Use(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+// RevokeFamily ends one session.
+func (r *SessionRepo) RevokeFamily(ctx context.Context, family uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", at).Error
+}
+
+// RevokeSubject ends every session of subject.
+func (r *SessionRepo) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("subject = ? AND revoked_at IS NULL", subject).
+		Update("revoked_at", at).Error
+}
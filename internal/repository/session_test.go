@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepo(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepo(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	newToken := func(subject string, family uuid.UUID) model.RefreshToken {
+		tok := model.RefreshToken{
+			ID: uuid.New(), Family: family, Subject: subject,
+			Roles: []string{"viewer"}, ExpiresAt: now.Add(time.Hour),
+		}
+		require.NoError(t, repo.Create(ctx, &tok))
+		return tok
+	}
+	s1, s2 := uuid.New(), uuid.New()
+	first := newToken("alice", s1)
+	second := newToken("alice", s2)
+	bob := newToken("bob", uuid.New())
+
+	got, err := repo.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"viewer"}, []string(got.Roles))
+	assert.True(t, got.Active(now))
+
+	ok, err := repo.Use(ctx, first.ID, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.Use(ctx, first.ID, now)
+	require.NoError(t, err)
+	assert.False(t, ok, "a refresh token is used once")
+
+	require.NoError(t, repo.RevokeFamily(ctx, s2, now))
+	ok, err = repo.Use(ctx, second.ID, now)
+	require.NoError(t, err)
+	assert.False(t, ok, "revoked session")
+
+	require.NoError(t, repo.RevokeSubject(ctx, "bob", now))
+	got, err = repo.Get(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Active(now))
+	assert.False(t, model.RefreshToken{ExpiresAt: now}.Active(now), "expired")
+}
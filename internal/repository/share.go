@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+// ShareRepo stores share links. The public endpoint has no tenant yet when
+// it calls Get, so that lookup runs unscoped and the link's TenantID scopes
+// what follows.
+type ShareRepo struct {
+	db *gorm.DB
+}
+
+func NewShareRepo(db *gorm.DB) *ShareRepo {
+	return &ShareRepo{db}
+}
+
+func (r *ShareRepo) Create(ctx context.Context, l *model.ShareLink) error {
+	return r.db.WithContext(ctx).Create(l).Error
+}
+
+func (r *ShareRepo) Get(ctx context.Context, id uuid.UUID) (model.ShareLink, error) {
+	var l model.ShareLink
+	err := r.db.WithContext(ctx).First(&l, "id = ?", id).Error
+	return l, err
+}
+
+// List returns a vehicle's links, newest first.
+func (r *ShareRepo) List(ctx context.Context, vehicleID uuid.UUID) ([]model.ShareLink, error) {
+	var res []model.ShareLink
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id = ?", vehicleID).
+		Order("created_at DESC").
+		Find(&res).Error
+	return res, err
+}
+
+// Revoke ends a link of the vehicle; gorm.ErrRecordNotFound when there is no
+// such link. Revoking twice keeps the first time.
+func (r *ShareRepo) Revoke(ctx context.Context, vehicleID, id uuid.UUID, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&model.ShareLink{}).
+		Where("id = ? AND vehicle_id = ?", id, vehicleID).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
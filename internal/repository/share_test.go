@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestShareRepo(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewShareRepo(db)
+	acme := tenant.WithID(context.Background(), uuid.New())
+	globex := tenant.WithID(context.Background(), uuid.New())
+
+	vehicle := uuid.New()
+	t0 := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	link := func(ctx context.Context, created time.Time) model.ShareLink {
+		l := model.ShareLink{ID: uuid.New(), VehicleID: vehicle, From: t0, To: t0.Add(time.Hour), CreatedAt: created}
+		require.NoError(t, repo.Create(ctx, &l))
+		return l
+	}
+	first := link(acme, t0)
+	second := link(acme, t0.Add(time.Minute))
+	foreign := link(globex, t0)
+
+	ls, err := repo.List(acme, vehicle)
+	require.NoError(t, err)
+	require.Len(t, ls, 2)
+	assert.Equal(t, []uuid.UUID{second.ID, first.ID}, []uuid.UUID{ls[0].ID, ls[1].ID})
+
+	// the public endpoint looks links up before it knows the tenant
+	got, err := repo.Get(context.Background(), foreign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, vehicle, got.VehicleID)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		vehicle uuid.UUID
+		id      uuid.UUID
+		wantErr error
+	}{
+		{name: "positive - revoke", ctx: acme, vehicle: vehicle, id: first.ID},
+		{name: "positive - revoke again keeps the first time", ctx: acme, vehicle: vehicle, id: first.ID},
+		{name: "negative - other vehicle", ctx: acme, vehicle: uuid.New(), id: second.ID, wantErr: gorm.ErrRecordNotFound},
+		{name: "negative - other tenant", ctx: acme, vehicle: vehicle, id: foreign.ID, wantErr: gorm.ErrRecordNotFound},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Revoke(tt.ctx, tt.vehicle, tt.id, t0.Add(time.Duration(i)*time.Minute))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			l, err := repo.Get(tt.ctx, tt.id)
+			require.NoError(t, err)
+			require.NotNil(t, l.RevokedAt)
+			assert.True(t, l.RevokedAt.Equal(t0), "revoked at %s", l.RevokedAt)
+		})
+	}
+
+	l, err := repo.Get(acme, second.ID)
+	require.NoError(t, err)
+	assert.Nil(t, l.RevokedAt)
+}
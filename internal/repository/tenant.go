@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+)
+
+const tenantColumn = "tenant_id"
+
+// ScopeTenants registers callbacks that confine every statement run with a
+// tenant-scoped context (see package tenant) to that tenant's rows: reads,
+// updates and deletes get a tenant_id condition and inserts are stamped with
+// the tenant. Rows of another tenant therefore look like missing rows.
+// Statements on system contexts and tables without tenant_id run unscoped.
+func ScopeTenants(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tenant:create", stampTenant); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", whereTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", whereTenant); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", whereTenant); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("tenant:row", whereTenant)
+}
+
+func whereTenant(db *gorm.DB) {
+	id, ok := tenant.FromContext(db.Statement.Context)
+	if !ok || !hasTenantColumn(db) {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: tenantColumn}, Value: id},
+	}})
+}
+
+func stampTenant(db *gorm.DB) {
+	id, ok := tenant.FromContext(db.Statement.Context)
+	if !ok || !hasTenantColumn(db) {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantColumn)
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(rv.Index(i)), id); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, rv, id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
+
+func hasTenantColumn(db *gorm.DB) bool {
+	if db.Statement.Schema == nil {
+		return false
+	}
+	_, ok := db.Statement.Schema.FieldsByDBName[tenantColumn]
+	return ok
+}
+
+// TenantRepo manages the tenants table itself, which is never scoped.
+type TenantRepo struct {
+	db *gorm.DB
+}
+
+func NewTenantRepo(db *gorm.DB) *TenantRepo {
+	return &TenantRepo{db}
+}
+
+// Create adds a tenant; a duplicate name surfaces as gorm.ErrDuplicatedKey.
+func (r *TenantRepo) Create(ctx context.Context, t *model.Tenant) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+func (r *TenantRepo) Get(ctx context.Context, id uuid.UUID) (model.Tenant, error) {
+	var t model.Tenant
+	err := r.db.WithContext(ctx).First(&t, "id = ?", id).Error
+	return t, err
+}
+
+func (r *TenantRepo) List(ctx context.Context) ([]model.Tenant, error) {
+	var res []model.Tenant
+	err := r.db.WithContext(ctx).Order("name").Find(&res).Error
+	return res, err
+}
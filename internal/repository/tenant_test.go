@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTenantIsolation_Vehicles(t *testing.T) {
+	db := setupTestDB(t)
+	tripRepo := NewTripRepo(db)
+	repo := NewVehicleRepo(db, tripRepo)
+	ctxA := tenant.WithID(context.Background(), uuid.New())
+	ctxB := tenant.WithID(context.Background(), uuid.New())
+
+	vin := "1HGCM82633A004352"
+	mine := model.Vehicle{ID: uuid.New(), PlateNumber: "DXB-1", VIN: &vin}
+	require.NoError(t, repo.Create(ctxA, &mine))
+	theirs := model.Vehicle{ID: uuid.New(), PlateNumber: "DXB-1", VIN: &vin}
+	require.NoError(t, repo.Create(ctxB, &theirs), "plates and VINs are unique per tenant only")
+
+	stored, err := repo.Get(context.Background(), mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, mine.TenantID, stored.TenantID, "create stamps the tenant")
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "get", run: func() error { _, err := repo.Get(ctxB, mine.ID); return err }},
+		{name: "update", run: func() error { v := mine; v.Make = "x"; return repo.Update(ctxB, &v) }},
+		{name: "archive", run: func() error { now := time.Now(); return repo.SetArchived(ctxB, mine.ID, &now) }},
+		{name: "ingest into foreign vehicle id", run: func() error {
+			st := model.Status{Location: [2]float64{55.27, 25.19}, Timestamp: time.Now()}
+			trip := model.Trips{ID: uuid.New(), VehicleID: mine.ID, StartTime: st.Timestamp}
+			_, err := repo.UpsertStatusAndInsertTrip(ctxB, mine.ID, "", st, trip, nil)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.run(), gorm.ErrRecordNotFound)
+		})
+	}
+
+	stored, err = repo.Get(ctxA, mine.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.LastStatus, "foreign ingest must not touch the row")
+	assert.Empty(t, stored.Make)
+	assert.Nil(t, stored.ArchivedAt)
+
+	trips, err := tripRepo.ListRecent(context.Background(), mine.ID, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, trips, "the rejected ingest is rolled back")
+
+	list, err := repo.List(ctxA, VehicleFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, mine.ID, list[0].ID)
+
+	byIDs, err := repo.ListByIDs(ctxB, []uuid.UUID{mine.ID, theirs.ID})
+	require.NoError(t, err)
+	require.Len(t, byIDs, 1)
+	assert.Equal(t, theirs.ID, byIDs[0].ID)
+}
+
+func TestTenantIsolation_History(t *testing.T) {
+	db := setupTestDB(t)
+	tripRepo := NewTripRepo(db)
+	repo := NewVehicleRepo(db, tripRepo)
+	positions := NewPositionRepo(db)
+	ctxA := tenant.WithID(context.Background(), uuid.New())
+	ctxB := tenant.WithID(context.Background(), uuid.New())
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	st := model.Status{Location: [2]float64{55.27, 25.19}, Timestamp: now}
+	_, err := repo.UpsertStatusAndInsertTrip(ctxA, id, "DXB-2", st,
+		model.Trips{ID: uuid.New(), VehicleID: id, StartTime: now}, nil)
+	require.NoError(t, err)
+
+	trips, err := tripRepo.ListRecent(ctxA, id, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, trips, 1)
+	trips, err = tripRepo.ListRecent(ctxB, id, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, trips)
+
+	rows, err := positions.List(ctxB, id, now.Add(-time.Hour), now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func TestTenantIsolation_GroupsAndDevices(t *testing.T) {
+	db := setupTestDB(t)
+	groups := NewGroupRepo(db)
+	devices := NewDeviceRepo(db)
+	ctxA := tenant.WithID(context.Background(), uuid.New())
+	ctxB := tenant.WithID(context.Background(), uuid.New())
+
+	g := model.VehicleGroup{ID: uuid.New(), Name: "depot"}
+	require.NoError(t, groups.Create(ctxA, &g))
+	require.NoError(t, groups.AddMembers(ctxA, g.ID, []uuid.UUID{uuid.New()}))
+
+	_, err := groups.VehicleIDs(ctxB, g.ID, true)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, groups.Delete(ctxB, g.ID), gorm.ErrRecordNotFound)
+	child := model.VehicleGroup{ID: uuid.New(), Name: "team", ParentID: &g.ID}
+	assert.ErrorIs(t, groups.Create(ctxB, &child), gorm.ErrRecordNotFound, "cannot hang a group under another tenant's")
+	all, err := groups.List(ctxB)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	imei := "490154203237518"
+	require.NoError(t, devices.Create(ctxA, &model.Device{ID: uuid.New(), IMEI: &imei}))
+	require.NoError(t, devices.Create(ctxB, &model.Device{ID: uuid.New(), IMEI: &imei}), "IMEIs are unique per tenant only")
+	dA, err := devices.FindByIMEI(ctxA, imei)
+	require.NoError(t, err)
+	dB, err := devices.FindByIMEI(ctxB, imei)
+	require.NoError(t, err)
+	assert.NotEqual(t, dA.ID, dB.ID)
+	_, err = devices.Get(ctxB, dA.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -38,3 +38,34 @@ func (r *TripRepo) ListRecent(
 		Find(&res).Error
 	return res, err
 }
+
+// ListRecentForVehicles is ListRecent across several vehicles, newest first.
+func (r *TripRepo) ListRecentForVehicles(
+	ctx context.Context,
+	ids []uuid.UUID,
+	since time.Duration,
+) ([]model.Trips, error) {
+	var res []model.Trips
+	if len(ids) == 0 {
+		return res, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("vehicle_id IN ? AND start_time >= ?", ids, time.Now().Add(-since)).
+		Order("start_time DESC").
+		Find(&res).Error
+	return res, err
+}
+
+// ListRecentForDriver returns the trips attributed to a driver, newest first.
+func (r *TripRepo) ListRecentForDriver(
+	ctx context.Context,
+	driverID uuid.UUID,
+	since time.Duration,
+) ([]model.Trips, error) {
+	var res []model.Trips
+	err := r.db.WithContext(ctx).
+		Where("driver_id = ? AND start_time >= ?", driverID, time.Now().Add(-since)).
+		Order("start_time DESC").
+		Find(&res).Error
+	return res, err
+}
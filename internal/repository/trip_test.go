@@ -16,13 +16,24 @@ import (
 
 func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
 	})
 	require.NoError(t, err)
 
 	// Auto migrate the models
-	err = db.AutoMigrate(&model.Vehicle{}, &model.Trips{})
+	err = db.AutoMigrate(
+		&model.Vehicle{}, &model.Trips{}, &model.Position{},
+		&model.SignalSample{}, &model.ActiveDTC{}, &model.Event{},
+		&model.Device{}, &model.DeviceAssignment{},
+		&model.Driver{}, &model.DriverAssignment{},
+		&model.VehicleGroup{}, &model.GroupMember{},
+		&model.Tenant{}, &model.AccessGrant{}, &model.RefreshToken{},
+		&model.User{}, &model.PasswordReset{}, &model.AuditEntry{}, &model.ShareLink{},
+		&model.DeviceCredential{},
+	)
 	require.NoError(t, err)
+	require.NoError(t, ScopeTenants(db))
 
 	return db
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+)
+
+type UserRepo struct {
+	db *gorm.DB
+}
+
+func NewUserRepo(db *gorm.DB) *UserRepo {
+	return &UserRepo{db}
+}
+
+// Create stores a user; a taken email surfaces as gorm.ErrDuplicatedKey.
+func (r *UserRepo) Create(ctx context.Context, u *model.User) error {
+	return r.db.WithContext(ctx).Create(u).Error
+}
+
+func (r *UserRepo) Get(ctx context.Context, id uuid.UUID) (model.User, error) {
+	var u model.User
+	err := r.db.WithContext(ctx).First(&u, "id = ?", id).Error
+	return u, err
+}
+
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	var u model.User
+	err := r.db.WithContext(ctx).First(&u, "email = ?", email).Error
+	return u, err
+}
+
+func (r *UserRepo) List(ctx context.Context) ([]model.User, error) {
+	var res []model.User
+	err := r.db.WithContext(ctx).Order("email").Find(&res).Error
+	return res, err
+}
+
+// Update writes profile, grants, password and TOTP enrolment; the login
+// counters are left to the methods below.
+func (r *UserRepo) Update(ctx context.Context, u *model.User) error {
+	return r.db.WithContext(ctx).Model(u).
+		Select("email", "name", "password_hash", "roles", "scopes", "restricted",
+			"totp_secret", "totp_enabled", "updated_at").
+		Updates(u).Error
+}
+
+// RecordFailure counts a failed login and returns the new count.
+func (r *UserRepo) RecordFailure(ctx context.Context, id uuid.UUID) (int, error) {
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Pluck("failed_logins", &n).Error
+	return n, err
+}
+
+// Lock refuses logins until the given time and restarts the failure count.
+func (r *UserRepo) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"locked_until": until, "failed_logins": 0}).Error
+}
+
+// RecordLogin clears the failure count and any lock.
+func (r *UserRepo) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"failed_logins": 0, "locked_until": nil, "last_login_at": at}).Error
+}
+
+// Unlock clears the failure count and any lock, e.g. after a password reset.
+func (r *UserRepo) Unlock(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"failed_logins": 0, "locked_until": nil}).Error
+}
+
+// UseTOTPStep records that the code of step was used; false if that step (or
+// a later one) was already used, i.e. the code is being replayed.
+func (r *UserRepo) UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		UpdateColumn("totp_last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *UserRepo) CreateReset(ctx context.Context, p *model.PasswordReset) error {
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+// GetReset runs unscoped when called from the public reset endpoint; the
+// token carries its own TenantID.
+func (r *UserRepo) GetReset(ctx context.Context, id uuid.UUID) (model.PasswordReset, error) {
+	var p model.PasswordReset
+	err := r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	return p, err
+}
+
+// UseReset marks a reset token used; false when it already was.
+func (r *UserRepo) UseReset(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUserRepo_Login(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepo(db)
+	ctx := tenant.WithID(context.Background(), tenant.Default) // system contexts would also see other tenants' alice
+	now := time.Now().UTC()
+
+	u := model.User{ID: uuid.New(), Email: "alice@example.com", Roles: []string{"viewer"}}
+	require.NoError(t, repo.Create(ctx, &u))
+	dup := model.User{ID: uuid.New(), Email: "alice@example.com"}
+	assert.ErrorIs(t, repo.Create(ctx, &dup), gorm.ErrDuplicatedKey)
+	other := model.User{ID: uuid.New(), Email: "alice@example.com"}
+	assert.NoError(t, repo.Create(tenant.WithID(ctx, uuid.New()), &other), "emails are unique per tenant")
+
+	for want := 1; want <= 3; want++ {
+		n, err := repo.RecordFailure(ctx, u.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	require.NoError(t, repo.Lock(ctx, u.ID, now.Add(time.Minute)))
+	got, err := repo.FindByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, got.LockedAt(now))
+	assert.Zero(t, got.FailedLogins)
+
+	require.NoError(t, repo.RecordLogin(ctx, u.ID, now))
+	got, err = repo.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, got.LockedAt(now))
+	assert.NotNil(t, got.LastLoginAt)
+
+	ok, err := repo.UseTOTPStep(ctx, u.ID, 100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.UseTOTPStep(ctx, u.ID, 100)
+	require.NoError(t, err)
+	assert.False(t, ok, "replayed code")
+	ok, err = repo.UseTOTPStep(ctx, u.ID, 99)
+	require.NoError(t, err)
+	assert.False(t, ok, "older code")
+
+	reset := model.PasswordReset{ID: uuid.New(), UserID: u.ID, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, repo.CreateReset(ctx, &reset))
+	ok, err = repo.UseReset(ctx, reset.ID, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.UseReset(ctx, reset.ID, now)
+	require.NoError(t, err)
+	assert.False(t, ok, "single use")
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -13,12 +15,14 @@ import (
 )
 
 type VehicleRepo struct {
-	db       *gorm.DB
-	tripRepo *TripRepo // needed for the composite Tx
+	db          *gorm.DB
+	tripRepo    *TripRepo        // needed for the composite Tx
+	positions   *PositionRepo    // history row written in the same Tx
+	diagnostics *DiagnosticsRepo // and the fix's diagnostics
 }
 
 func NewVehicleRepo(db *gorm.DB, tripRepo *TripRepo) *VehicleRepo {
-	return &VehicleRepo{db: db, tripRepo: tripRepo}
+	return &VehicleRepo{db: db, tripRepo: tripRepo, positions: NewPositionRepo(db), diagnostics: NewDiagnosticsRepo(db)}
 }
 
 // Get returns the current vehicle
@@ -28,37 +32,201 @@ func (r *VehicleRepo) Get(ctx context.Context, id uuid.UUID) (model.Vehicle, err
 	return v, err
 }
 
-// UpsertStatus updates status of a vehicle
+// ListByIDs returns the vehicles that exist among ids, ordered by plate.
+func (r *VehicleRepo) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Vehicle, error) {
+	var res []model.Vehicle
+	if len(ids) == 0 {
+		return res, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("plate_number").
+		Find(&res).Error
+	return res, err
+}
+
+// UpsertStatus updates status of a vehicle. On conflict only last_status
+// (and the plate, when one was sent) is written, so registry metadata survives,
+// and only when st is newer than the stored status: late and buffered fixes
+// never move the current status backwards. current reports whether st is the
+// vehicle's status afterwards. A vehicle id owned by another tenant is left
+// alone and reported as gorm.ErrRecordNotFound.
 func (r *VehicleRepo) UpsertStatus(
 	ctx context.Context,
 	id uuid.UUID, plate string,
 	st model.Status,
 	tx *gorm.DB,
-) error {
+) (current bool, err error) {
 	b, _ := json.Marshal(st)
-	return tx.WithContext(ctx).
-		Clauses(clause.OnConflict{UpdateAll: true}).
+	newer := fmt.Sprintf("vehicles.last_status IS NULL OR %s < %s",
+		statusTime(tx, "vehicles"), statusTime(tx, "excluded"))
+	set := clause.Set{ifNewer("last_status", newer)}
+	if plate != "" {
+		set = append(set, ifNewer("plate_number", newer))
+	}
+	res := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: set,
+			Where: clause.Where{Exprs: []clause.Expression{clause.Eq{
+				Column: clause.Column{Table: clause.CurrentTable, Name: "tenant_id"},
+				Value:  clause.Column{Table: "excluded", Name: "tenant_id"},
+			}}},
+		}).
 		Create(&model.Vehicle{
 			ID:          id,
 			PlateNumber: plate,
 			LastStatus:  datatypes.JSON(b),
-		}).Error
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+
+	// the upsert holds the row lock, so this sees what stays committed
+	var v model.Vehicle
+	if err := tx.WithContext(ctx).Select("last_status").First(&v, "id = ?", id).Error; err != nil {
+		return false, err
+	}
+	saved, err := v.DecodeStatus()
+	if err != nil {
+		return false, err
+	}
+	return !saved.Timestamp.After(st.Timestamp), nil
+}
+
+// ifNewer assigns the incoming value of column only when newer holds.
+func ifNewer(column, newer string) clause.Assignment {
+	return clause.Assignment{
+		Column: clause.Column{Name: column},
+		Value: clause.Expr{SQL: fmt.Sprintf("CASE WHEN %s THEN excluded.%s ELSE vehicles.%s END",
+			newer, column, column)},
+	}
 }
 
-// UpsertStatusAndInsertTrip insert data in both trip and vehicle status(used in ingest)
+// statusTime is the timestamp of table's last_status as a comparable SQL
+// value. The JSON holds RFC 3339 text, which doesn't sort as a string.
+func statusTime(tx *gorm.DB, table string) string {
+	if tx.Dialector.Name() == "sqlite" { // tests
+		return fmt.Sprintf("julianday(%s.last_status->>'timestamp')", table)
+	}
+	return fmt.Sprintf("(%s.last_status->>'timestamp')::timestamptz", table)
+}
+
+// UpsertStatusAndInsertTrip insert data in trip, position history, vehicle
+// status and, when diag is set, diagnostics in one Tx (used in ingest), so a
+// failed fix can be retried without duplicating rows. The trip, position and
+// sample rows are written even for a fix older than the current status, but
+// such a fix leaves the active trouble codes alone; current is as for
+// UpsertStatus.
 func (r *VehicleRepo) UpsertStatusAndInsertTrip(
 	ctx context.Context,
 	id uuid.UUID, plate string,
 	st model.Status,
 	trip model.Trips,
-) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := r.UpsertStatus(ctx, id, plate, st, tx); err != nil {
+	diag *Diagnostics,
+) (current bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if current, err = r.UpsertStatus(ctx, id, plate, st, tx); err != nil {
 			return err
 		}
 		if err := r.tripRepo.Create(ctx, trip, tx); err != nil {
 			return err
 		}
-		return nil
+		if err := r.positions.Create(ctx, model.NewPosition(id, st), tx); err != nil {
+			return err
+		}
+		if diag == nil {
+			return nil
+		}
+		codes := diag.Codes
+		if !current {
+			codes = nil
+		}
+		diag.Events, err = r.diagnostics.Save(ctx, id, st.Timestamp, diag.Samples, codes, tx)
+		return err
 	})
+	return current, err
+}
+
+// metadataColumns are the registry fields written by Update.
+var metadataColumns = []string{
+	"plate_number", "vin", "make", "model", "year", "fuel_type", "capacity_kg",
+	"tank_liters", "odometer_base_km", "attributes", "tags", "updated_at",
+}
+
+// VehicleFilter narrows List; zero values match everything.
+type VehicleFilter struct {
+	Tag             string
+	Make            string
+	FuelType        string
+	IncludeArchived bool
+	// IDs, when non-nil, limits the result to these vehicles; an empty
+	// slice matches nothing.
+	IDs    []uuid.UUID
+	Limit  int
+	Offset int
+}
+
+// Create registers a new vehicle; duplicates surface as gorm.ErrDuplicatedKey.
+func (r *VehicleRepo) Create(ctx context.Context, v *model.Vehicle) error {
+	return r.db.WithContext(ctx).Create(v).Error
+}
+
+// Update writes the registry metadata of v, leaving last_status alone.
+func (r *VehicleRepo) Update(ctx context.Context, v *model.Vehicle) error {
+	res := r.db.WithContext(ctx).Model(v).Select(metadataColumns).Updates(v)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// List returns registered vehicles ordered by plate.
+func (r *VehicleRepo) List(ctx context.Context, f VehicleFilter) ([]model.Vehicle, error) {
+	q := r.db.WithContext(ctx).Order("plate_number")
+	if !f.IncludeArchived {
+		q = q.Where("archived_at IS NULL")
+	}
+	if f.Tag != "" {
+		q = q.Where(datatypes.JSONArrayQuery("tags").Contains(f.Tag))
+	}
+	if f.Make != "" {
+		q = q.Where("LOWER(make) = LOWER(?)", f.Make)
+	}
+	if f.FuelType != "" {
+		q = q.Where("fuel_type = ?", f.FuelType)
+	}
+	if f.IDs != nil {
+		q = q.Where("id IN ?", f.IDs)
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	var res []model.Vehicle
+	err := q.Find(&res).Error
+	return res, err
+}
+
+// SetArchived archives (at != nil) or restores a vehicle.
+func (r *VehicleRepo) SetArchived(ctx context.Context, id uuid.UUID, at *time.Time) error {
+	res := r.db.WithContext(ctx).
+		Model(&model.Vehicle{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"archived_at": at, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
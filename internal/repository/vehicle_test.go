@@ -9,6 +9,7 @@ import (
 	"github.com/aditi2420/fleet-tracker/internal/model"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVehicleRepo_Get(t *testing.T) {
@@ -139,7 +140,7 @@ func TestVehicleRepo_UpsertStatusAndInsertTrip(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			vehicleID, plate, status, trip := tt.setupData()
 
-			err := repo.UpsertStatusAndInsertTrip(context.Background(), vehicleID, plate, status, trip)
+			_, err := repo.UpsertStatusAndInsertTrip(context.Background(), vehicleID, plate, status, trip, nil)
 
 			if tt.expectedErr {
 				assert.Error(t, err)
@@ -169,3 +170,92 @@ func TestVehicleRepo_UpsertStatusAndInsertTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestVehicleRepo_UpsertStatusKeepsNewest(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewVehicleRepo(db, NewTripRepo(db))
+	ctx := context.Background()
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	ingest := func(plate string, ts time.Time, speed float64) bool {
+		st := model.Status{Location: [2]float64{55.3, 25.2}, Speed: speed, Timestamp: ts}
+		current, err := repo.UpsertStatusAndInsertTrip(ctx, id, plate, st,
+			model.Trips{ID: uuid.New(), VehicleID: id, StartTime: ts, AvgSpeed: speed}, nil)
+		require.NoError(t, err)
+		return current
+	}
+	assert.True(t, ingest("NEW", now, 50), "first fix")
+	assert.False(t, ingest("OLD", now.Add(-time.Minute), 10), "late fix")
+	assert.True(t, ingest("", now, 50), "same fix again")
+
+	v, err := repo.Get(ctx, id)
+	require.NoError(t, err)
+	st, err := v.DecodeStatus()
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, st.Speed, "late fix doesn't move the status backwards")
+	assert.Equal(t, "NEW", v.PlateNumber)
+
+	var trips, positions int64
+	require.NoError(t, db.Model(&model.Trips{}).Where("vehicle_id = ?", id).Count(&trips).Error)
+	require.NoError(t, db.Model(&model.Position{}).Where("vehicle_id = ?", id).Count(&positions).Error)
+	assert.EqualValues(t, 3, trips, "history keeps the late fix")
+	assert.EqualValues(t, 3, positions)
+}
+
+func TestVehicleRepo_UpsertStatusAndInsertTrip_Diagnostics(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewVehicleRepo(db, NewTripRepo(db))
+	diagRepo := NewDiagnosticsRepo(db)
+	ctx := context.Background()
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	ingest := func(ts time.Time, codes ...string) (*Diagnostics, error) {
+		diag := &Diagnostics{
+			Samples: []model.SignalSample{{ID: uuid.New(), VehicleID: id, Name: "engine_rpm", Timestamp: ts, Value: 900, Unit: "rpm"}},
+			Codes:   codes,
+		}
+		st := model.Status{Location: [2]float64{55.3, 25.2}, Timestamp: ts}
+		_, err := repo.UpsertStatusAndInsertTrip(ctx, id, "", st, model.Trips{ID: uuid.New(), VehicleID: id, StartTime: ts}, diag)
+		return diag, err
+	}
+	activeCodes := func() []string {
+		active, err := diagRepo.ActiveDTCs(ctx, id)
+		require.NoError(t, err)
+		var res []string
+		for _, a := range active {
+			res = append(res, a.Code)
+		}
+		return res
+	}
+
+	diag, err := ingest(now, "P0301")
+	require.NoError(t, err)
+	assert.Len(t, diag.Events, 1, "events come back to the caller")
+
+	_, err = ingest(now.Add(-time.Minute), "P0420")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"P0301"}, activeCodes(), "a late fix doesn't rewrite the active set")
+	samples, err := diagRepo.ListSamples(ctx, id, "engine_rpm", now.Add(-time.Hour), now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, samples, 2, "its samples are still stored")
+
+	t.Run("diagnostics failure rolls the fix back", func(t *testing.T) {
+		require.NoError(t, db.Migrator().DropTable(&model.SignalSample{}))
+		_, err := ingest(now.Add(time.Minute), "P0420")
+		require.Error(t, err)
+
+		v, err := repo.Get(ctx, id)
+		require.NoError(t, err)
+		st, err := v.DecodeStatus()
+		require.NoError(t, err)
+		assert.True(t, st.Timestamp.Equal(now), "status unchanged")
+		var trips, positions int64
+		require.NoError(t, db.Model(&model.Trips{}).Where("vehicle_id = ?", id).Count(&trips).Error)
+		require.NoError(t, db.Model(&model.Position{}).Where("vehicle_id = ?", id).Count(&positions).Error)
+		assert.EqualValues(t, 2, trips, "no trip row to duplicate on retry")
+		assert.EqualValues(t, 2, positions)
+		assert.Equal(t, []string{"P0301"}, activeCodes())
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// AccessService manages the grants that decide which vehicles restricted
+// users can see.
+type AccessService interface {
+	Grant(ctx context.Context, g model.AccessGrant) (model.AccessGrant, error)
+	// List returns the grants of one subject, or of everybody when empty.
+	List(ctx context.Context, subject string) ([]model.AccessGrant, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type accessService struct {
+	grants   *repository.AccessRepo
+	vehicles *repository.VehicleRepo
+	groups   *repository.GroupRepo
+}
+
+func NewAccess(a *repository.AccessRepo, v *repository.VehicleRepo, g *repository.GroupRepo) AccessService {
+	return &accessService{grants: a, vehicles: v, groups: g}
+}
+
+// Grant stores g after checking its target exists; a zero From means now.
+func (s *accessService) Grant(ctx context.Context, g model.AccessGrant) (model.AccessGrant, error) {
+	g.ID = uuid.New()
+	if g.From.IsZero() {
+		g.From = time.Now()
+	}
+	g.From = g.From.UTC()
+	if g.To != nil {
+		to := g.To.UTC()
+		g.To = &to
+	}
+	if err := g.Validate(); err != nil {
+		return model.AccessGrant{}, err
+	}
+	var err error
+	if g.VehicleID != nil {
+		_, err = s.vehicles.Get(ctx, *g.VehicleID)
+	} else {
+		_, err = s.groups.Get(ctx, *g.GroupID)
+	}
+	if err != nil {
+		return model.AccessGrant{}, notFound(err)
+	}
+	if err := s.grants.Create(ctx, &g); err != nil {
+		return model.AccessGrant{}, notFound(err)
+	}
+	return g, nil
+}
+
+func (s *accessService) List(ctx context.Context, subject string) ([]model.AccessGrant, error) {
+	return s.grants.List(ctx, subject)
+}
+
+func (s *accessService) Revoke(ctx context.Context, id uuid.UUID) error {
+	return notFound(s.grants.Delete(ctx, id))
+}
+
+// visibleVehicles returns the vehicles the caller may see. ok is false for
+// unrestricted callers (and system contexts), which see everything.
+func visibleVehicles(ctx context.Context, grants *repository.AccessRepo) (ids []uuid.UUID, ok bool, err error) {
+	p, found := auth.PrincipalFrom(ctx)
+	if !found || !p.Restricted {
+		return nil, false, nil
+	}
+	ids, err = grants.VehicleIDs(ctx, p.Subject, time.Now())
+	if ids == nil {
+		ids = []uuid.UUID{} // restricted without grants sees nothing
+	}
+	return ids, true, err
+}
+
+// checkVehicle returns ErrNotFound when a restricted caller has no grant
+// covering the vehicle, so it looks the same as a vehicle that doesn't exist.
+func checkVehicle(ctx context.Context, grants *repository.AccessRepo, id uuid.UUID) error {
+	ids, restricted, err := visibleVehicles(ctx, grants)
+	if err != nil || !restricted {
+		return err
+	}
+	for _, v := range ids {
+		if v == id {
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// filterVisible drops the ids a restricted caller may not see.
+func filterVisible(ctx context.Context, grants *repository.AccessRepo, ids []uuid.UUID) ([]uuid.UUID, error) {
+	allowed, restricted, err := visibleVehicles(ctx, grants)
+	if err != nil || !restricted {
+		return ids, err
+	}
+	set := make(map[uuid.UUID]bool, len(allowed))
+	for _, id := range allowed {
+		set[id] = true
+	}
+	res := ids[:0:0]
+	for _, id := range ids {
+		if set[id] {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// verifyBatch is how many entries Verify reads at a time.
+const verifyBatch = 1000
+
+// AuditReport is the outcome of walking a tenant's audit chain. BrokenAt is
+// the Seq of the first entry that doesn't link up, 0 when the chain is intact.
+type AuditReport struct {
+	OK       bool   `json:"ok"`
+	Entries  int64  `json:"entries"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditService keeps the tamper-evident record of API calls.
+type AuditService interface {
+	Record(ctx context.Context, e model.AuditEntry) error
+	Query(ctx context.Context, f repository.AuditFilter) ([]model.AuditEntry, error)
+	Verify(ctx context.Context) (AuditReport, error)
+}
+
+type auditService struct {
+	log *repository.AuditRepo
+}
+
+func NewAudit(log *repository.AuditRepo) AuditService {
+	return &auditService{log: log}
+}
+
+func (s *auditService) Record(ctx context.Context, e model.AuditEntry) error {
+	e.ID = uuid.New()
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	return s.log.Append(ctx, &e)
+}
+
+func (s *auditService) Query(ctx context.Context, f repository.AuditFilter) ([]model.AuditEntry, error) {
+	return s.log.List(ctx, f)
+}
+
+// Verify recomputes every hash of the context tenant's chain and checks the
+// links and sequence numbers between them.
+func (s *auditService) Verify(ctx context.Context) (AuditReport, error) {
+	var (
+		rep  AuditReport
+		prev string
+		seq  int64
+	)
+	for {
+		batch, err := s.log.Chain(ctx, seq, verifyBatch)
+		if err != nil {
+			return AuditReport{}, err
+		}
+		for _, e := range batch {
+			switch {
+			case e.Seq != seq+1:
+				return broken(rep, seq+1, "entry missing"), nil
+			case e.PrevHash != prev:
+				return broken(rep, e.Seq, "prev_hash does not match the previous entry"), nil
+			case e.ComputeHash() != e.Hash:
+				return broken(rep, e.Seq, "content does not match its hash"), nil
+			}
+			prev, seq = e.Hash, e.Seq
+			rep.Entries++
+		}
+		if len(batch) < verifyBatch {
+			rep.OK = true
+			return rep, nil
+		}
+	}
+}
+
+func broken(rep AuditReport, seq int64, reason string) AuditReport {
+	rep.BrokenAt, rep.Reason = seq, reason
+	return rep
+}
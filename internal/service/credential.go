@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCredentialKind is returned for kinds other than api_key and hmac.
+var ErrInvalidCredentialKind = errors.New("kind must be api_key or hmac")
+
+// ErrInvalidCredentialBinding is returned unless exactly one of vehicle and
+// device is given.
+var ErrInvalidCredentialBinding = errors.New("exactly one of vehicle_id or device_id is required")
+
+// ErrHMACDisabled is returned for hmac credentials when the server has no key
+// to seal their signing keys with.
+var ErrHMACDisabled = errors.New("hmac credentials are not enabled on this server")
+
+// CredentialService manages per-device credentials.
+type CredentialService interface {
+	// Issue creates a credential bound to vehicleID, or to deviceID when that
+	// is set instead. The returned secret is shown exactly once; only its
+	// hash (api_key) or the sealed signing key (hmac) is kept.
+	Issue(ctx context.Context, vehicleID uuid.UUID, deviceID *uuid.UUID, kind, label string) (model.DeviceCredential, string, error)
+	List(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceCredential, error)
+	ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceCredential, error)
+	Revoke(ctx context.Context, keyID string) error
+	// SealLegacy seals the signing keys of hmac credentials stored in the
+	// clear by earlier versions and reports how many it sealed.
+	SealLegacy(ctx context.Context) (int, error)
+}
+
+type credentialService struct {
+	repo      *repository.CredentialRepo
+	serverKey []byte
+}
+
+// NewCredentials seals hmac signing keys with serverKey; without one only
+// api_key credentials can be issued.
+func NewCredentials(r *repository.CredentialRepo, serverKey []byte) CredentialService {
+	return &credentialService{repo: r, serverKey: serverKey}
+}
+
+func (s *credentialService) Issue(ctx context.Context, vehicleID uuid.UUID, deviceID *uuid.UUID, kind, label string) (model.DeviceCredential, string, error) {
+	if (vehicleID == uuid.Nil) == (deviceID == nil) {
+		return model.DeviceCredential{}, "", ErrInvalidCredentialBinding
+	}
+	if kind == "" {
+		kind = model.CredentialAPIKey
+	}
+	if kind != model.CredentialAPIKey && kind != model.CredentialHMAC {
+		return model.DeviceCredential{}, "", ErrInvalidCredentialKind
+	}
+	if kind == model.CredentialHMAC && len(s.serverKey) == 0 {
+		return model.DeviceCredential{}, "", ErrHMACDisabled
+	}
+
+	keyID, err := randomToken(8, hex.EncodeToString)
+	if err != nil {
+		return model.DeviceCredential{}, "", err
+	}
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return model.DeviceCredential{}, "", err
+	}
+
+	cred := model.DeviceCredential{
+		ID:        uuid.New(),
+		KeyID:     "dk_" + keyID,
+		VehicleID: vehicleID,
+		DeviceID:  deviceID,
+		Kind:      kind,
+		Label:     label,
+		CreatedAt: time.Now().UTC(),
+	}
+	if kind == model.CredentialHMAC {
+		if cred.SealedKey, err = model.SealKey(s.serverKey, model.SigningKey(secret)); err != nil {
+			return model.DeviceCredential{}, "", err
+		}
+	} else {
+		cred.SecretHash = model.HashSecret(secret)
+	}
+	if err := s.repo.Create(ctx, cred); err != nil {
+		return model.DeviceCredential{}, "", err
+	}
+	return cred, secret, nil
+}
+
+func (s *credentialService) List(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceCredential, error) {
+	return s.repo.ListByVehicle(ctx, vehicleID)
+}
+
+func (s *credentialService) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceCredential, error) {
+	return s.repo.ListByDevice(ctx, deviceID)
+}
+
+func (s *credentialService) Revoke(ctx context.Context, keyID string) error {
+	return notFound(s.repo.Revoke(ctx, keyID))
+}
+
+func (s *credentialService) SealLegacy(ctx context.Context) (int, error) {
+	if len(s.serverKey) == 0 {
+		return 0, ErrHMACDisabled
+	}
+	creds, err := s.repo.ListUnsealed(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, cred := range creds {
+		key, err := hex.DecodeString(cred.SecretHash)
+		if err != nil {
+			return i, err
+		}
+		sealed, err := model.SealKey(s.serverKey, key)
+		if err != nil {
+			return i, err
+		}
+		if err := s.repo.Seal(ctx, cred.ID, sealed); err != nil {
+			return i, err
+		}
+	}
+	return len(creds), nil
+}
+
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DeviceService manages trackers and their installation history.
+type DeviceService interface {
+	Create(ctx context.Context, p model.DevicePatch) (model.Device, error)
+	Get(ctx context.Context, id uuid.UUID) (model.Device, error)
+	List(ctx context.Context, limit, offset int) ([]model.Device, error)
+	Update(ctx context.Context, id uuid.UUID, p model.DevicePatch) (model.Device, error)
+
+	// Assign installs the device in a vehicle from the given time (now when
+	// zero), ending its previous assignment.
+	Assign(ctx context.Context, deviceID, vehicleID uuid.UUID, from time.Time) (model.DeviceAssignment, error)
+	// Unassign removes the device from its vehicle at the given time (now when zero).
+	Unassign(ctx context.Context, deviceID uuid.UUID, at time.Time) error
+	Assignments(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceAssignment, error)
+	VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceAssignment, error)
+}
+
+type deviceService struct {
+	devices  *repository.DeviceRepo
+	vehicles *repository.VehicleRepo
+}
+
+func NewDevices(d *repository.DeviceRepo, v *repository.VehicleRepo) DeviceService {
+	return &deviceService{devices: d, vehicles: v}
+}
+
+func (s *deviceService) Create(ctx context.Context, p model.DevicePatch) (model.Device, error) {
+	d := model.Device{ID: uuid.New()}
+	p.Apply(&d)
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		return model.Device{}, err
+	}
+	if err := s.devices.Create(ctx, &d); err != nil {
+		return model.Device{}, notFound(err)
+	}
+	return d, nil
+}
+
+func (s *deviceService) Get(ctx context.Context, id uuid.UUID) (model.Device, error) {
+	d, err := s.devices.Get(ctx, id)
+	return d, notFound(err)
+}
+
+func (s *deviceService) List(ctx context.Context, limit, offset int) ([]model.Device, error) {
+	return s.devices.List(ctx, limit, offset)
+}
+
+func (s *deviceService) Update(ctx context.Context, id uuid.UUID, p model.DevicePatch) (model.Device, error) {
+	d, err := s.devices.Get(ctx, id)
+	if err != nil {
+		return model.Device{}, notFound(err)
+	}
+	p.Apply(&d)
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		return model.Device{}, err
+	}
+	if err := s.devices.Update(ctx, &d); err != nil {
+		return model.Device{}, notFound(err)
+	}
+	return d, nil
+}
+
+func (s *deviceService) Assign(ctx context.Context, deviceID, vehicleID uuid.UUID, from time.Time) (model.DeviceAssignment, error) {
+	if _, err := s.devices.Get(ctx, deviceID); err != nil {
+		return model.DeviceAssignment{}, notFound(err)
+	}
+	if _, err := s.vehicles.Get(ctx, vehicleID); err != nil {
+		return model.DeviceAssignment{}, notFound(err)
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	a := model.DeviceAssignment{
+		ID:        uuid.New(),
+		DeviceID:  deviceID,
+		VehicleID: vehicleID,
+		From:      from.UTC(),
+	}
+	if err := s.devices.Assign(ctx, a); err != nil {
+		return model.DeviceAssignment{}, assignmentError(err)
+	}
+	return a, nil
+}
+
+func (s *deviceService) Unassign(ctx context.Context, deviceID uuid.UUID, at time.Time) error {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return assignmentError(s.devices.Unassign(ctx, deviceID, at.UTC()))
+}
+
+func (s *deviceService) Assignments(ctx context.Context, deviceID uuid.UUID) ([]model.DeviceAssignment, error) {
+	return s.devices.Assignments(ctx, deviceID)
+}
+
+func (s *deviceService) VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DeviceAssignment, error) {
+	return s.devices.VehicleAssignments(ctx, vehicleID)
+}
+
+func assignmentError(err error) error {
+	if errors.Is(err, model.ErrAssignmentOverlap) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return notFound(err)
+}
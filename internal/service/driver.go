@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DriverService manages drivers and their shifts on vehicles.
+type DriverService interface {
+	Create(ctx context.Context, p model.DriverPatch) (model.Driver, error)
+	Get(ctx context.Context, id uuid.UUID) (model.Driver, error)
+	List(ctx context.Context, limit, offset int) ([]model.Driver, error)
+	Update(ctx context.Context, id uuid.UUID, p model.DriverPatch) (model.Driver, error)
+
+	// Assign puts the driver on a vehicle from the given time (now when zero)
+	// until to (open-ended when nil), ending whatever shift either was on.
+	Assign(ctx context.Context, driverID, vehicleID uuid.UUID, from time.Time, to *time.Time) (model.DriverAssignment, error)
+	// Unassign ends the driver's shift at the given time (now when zero).
+	Unassign(ctx context.Context, driverID uuid.UUID, at time.Time) error
+	Assignments(ctx context.Context, driverID uuid.UUID) ([]model.DriverAssignment, error)
+	VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DriverAssignment, error)
+	Trips(ctx context.Context, driverID uuid.UUID, since time.Duration) ([]model.Trips, error)
+}
+
+type driverService struct {
+	drivers  *repository.DriverRepo
+	vehicles *repository.VehicleRepo
+	trips    *repository.TripRepo
+}
+
+func NewDrivers(d *repository.DriverRepo, v *repository.VehicleRepo, t *repository.TripRepo) DriverService {
+	return &driverService{drivers: d, vehicles: v, trips: t}
+}
+
+func (s *driverService) Create(ctx context.Context, p model.DriverPatch) (model.Driver, error) {
+	d := model.Driver{ID: uuid.New()}
+	p.Apply(&d)
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		return model.Driver{}, err
+	}
+	if err := s.drivers.Create(ctx, &d); err != nil {
+		return model.Driver{}, notFound(err)
+	}
+	return d, nil
+}
+
+func (s *driverService) Get(ctx context.Context, id uuid.UUID) (model.Driver, error) {
+	d, err := s.drivers.Get(ctx, id)
+	return d, notFound(err)
+}
+
+func (s *driverService) List(ctx context.Context, limit, offset int) ([]model.Driver, error) {
+	return s.drivers.List(ctx, limit, offset)
+}
+
+func (s *driverService) Update(ctx context.Context, id uuid.UUID, p model.DriverPatch) (model.Driver, error) {
+	d, err := s.drivers.Get(ctx, id)
+	if err != nil {
+		return model.Driver{}, notFound(err)
+	}
+	p.Apply(&d)
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		return model.Driver{}, err
+	}
+	if err := s.drivers.Update(ctx, &d); err != nil {
+		return model.Driver{}, notFound(err)
+	}
+	return d, nil
+}
+
+func (s *driverService) Assign(ctx context.Context, driverID, vehicleID uuid.UUID, from time.Time, to *time.Time) (model.DriverAssignment, error) {
+	d, err := s.drivers.Get(ctx, driverID)
+	if err != nil {
+		return model.DriverAssignment{}, notFound(err)
+	}
+	if _, err := s.vehicles.Get(ctx, vehicleID); err != nil {
+		return model.DriverAssignment{}, notFound(err)
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if !d.LicenceValidAt(from) {
+		return model.DriverAssignment{}, model.ErrLicenceExpired
+	}
+	a := model.DriverAssignment{
+		ID:        uuid.New(),
+		DriverID:  driverID,
+		VehicleID: vehicleID,
+		From:      from.UTC(),
+		Source:    model.ShiftManual,
+	}
+	if to != nil {
+		if !to.After(from) {
+			return model.DriverAssignment{}, fmt.Errorf("%w: shift must end after it starts", model.ErrInvalidDriver)
+		}
+		end := to.UTC()
+		a.To = &end
+	}
+	if err := s.drivers.Assign(ctx, a); err != nil {
+		return model.DriverAssignment{}, assignmentError(err)
+	}
+	return a, nil
+}
+
+func (s *driverService) Unassign(ctx context.Context, driverID uuid.UUID, at time.Time) error {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return assignmentError(s.drivers.Unassign(ctx, driverID, at.UTC()))
+}
+
+func (s *driverService) Assignments(ctx context.Context, driverID uuid.UUID) ([]model.DriverAssignment, error) {
+	return s.drivers.Assignments(ctx, driverID)
+}
+
+func (s *driverService) VehicleAssignments(ctx context.Context, vehicleID uuid.UUID) ([]model.DriverAssignment, error) {
+	return s.drivers.VehicleAssignments(ctx, vehicleID)
+}
+
+func (s *driverService) Trips(ctx context.Context, driverID uuid.UUID, since time.Duration) ([]model.Trips, error) {
+	return s.trips.ListRecentForDriver(ctx, driverID, since)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned instead of storage-specific "no rows" errors so
+// handlers can answer 404 without importing GORM.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when a write clashes with existing data, such as a
+// duplicate plate or VIN.
+var ErrConflict = errors.New("conflict")
+
+// Ingest errors for payloads that identify a device rather than a vehicle.
+var (
+	ErrUnknownDevice    = errors.New("unknown device")
+	ErrDeviceUnassigned = errors.New("device is not assigned to a vehicle at the fix timestamp")
+	ErrDeviceMismatch   = errors.New("vehicle_id does not match the device's assignment")
+)
+
+func notFound(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrConflict
+	}
+	return err
+}
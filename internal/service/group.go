@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/google/uuid"
+)
+
+// GroupService manages the fleet tree and group membership.
+type GroupService interface {
+	Create(ctx context.Context, name string, parentID *uuid.UUID) (model.VehicleGroup, error)
+	Get(ctx context.Context, id uuid.UUID) (model.VehicleGroup, error)
+	List(ctx context.Context) ([]model.VehicleGroup, error)
+	// Subtree returns the group followed by all of its descendants.
+	Subtree(ctx context.Context, id uuid.UUID) ([]model.VehicleGroup, error)
+	Rename(ctx context.Context, id uuid.UUID, name string) (model.VehicleGroup, error)
+	// Move re-parents a group; a nil parent makes it a root.
+	Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) (model.VehicleGroup, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	AddVehicles(ctx context.Context, id uuid.UUID, vehicleIDs []uuid.UUID) error
+	RemoveVehicle(ctx context.Context, id, vehicleID uuid.UUID) error
+	// Vehicles lists members, including those of descendant groups when recursive.
+	Vehicles(ctx context.Context, id uuid.UUID, recursive bool) ([]model.Vehicle, error)
+}
+
+type groupService struct {
+	groups   *repository.GroupRepo
+	vehicles *repository.VehicleRepo
+}
+
+func NewGroups(g *repository.GroupRepo, v *repository.VehicleRepo) GroupService {
+	return &groupService{groups: g, vehicles: v}
+}
+
+func (s *groupService) Create(ctx context.Context, name string, parentID *uuid.UUID) (model.VehicleGroup, error) {
+	g := model.VehicleGroup{ID: uuid.New(), ParentID: parentID, Name: strings.TrimSpace(name)}
+	if err := g.Validate(); err != nil {
+		return model.VehicleGroup{}, err
+	}
+	if err := s.groups.Create(ctx, &g); err != nil {
+		return model.VehicleGroup{}, notFound(err)
+	}
+	return g, nil
+}
+
+func (s *groupService) Get(ctx context.Context, id uuid.UUID) (model.VehicleGroup, error) {
+	g, err := s.groups.Get(ctx, id)
+	return g, notFound(err)
+}
+
+func (s *groupService) List(ctx context.Context) ([]model.VehicleGroup, error) {
+	return s.groups.List(ctx)
+}
+
+func (s *groupService) Subtree(ctx context.Context, id uuid.UUID) ([]model.VehicleGroup, error) {
+	gs, err := s.groups.Subtree(ctx, id)
+	return gs, notFound(err)
+}
+
+func (s *groupService) Rename(ctx context.Context, id uuid.UUID, name string) (model.VehicleGroup, error) {
+	name = strings.TrimSpace(name)
+	if err := (model.VehicleGroup{Name: name}).Validate(); err != nil {
+		return model.VehicleGroup{}, err
+	}
+	if err := s.groups.Rename(ctx, id, name); err != nil {
+		return model.VehicleGroup{}, notFound(err)
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *groupService) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) (model.VehicleGroup, error) {
+	if err := s.groups.Move(ctx, id, parentID); err != nil {
+		if errors.Is(err, model.ErrGroupCycle) {
+			return model.VehicleGroup{}, fmt.Errorf("%w: %w", ErrConflict, err)
+		}
+		return model.VehicleGroup{}, notFound(err)
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *groupService) Delete(ctx context.Context, id uuid.UUID) error {
+	err := s.groups.Delete(ctx, id)
+	if errors.Is(err, model.ErrGroupNotEmpty) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return notFound(err)
+}
+
+func (s *groupService) AddVehicles(ctx context.Context, id uuid.UUID, vehicleIDs []uuid.UUID) error {
+	if _, err := s.groups.Get(ctx, id); err != nil {
+		return notFound(err)
+	}
+	found, err := s.vehicles.ListByIDs(ctx, vehicleIDs)
+	if err != nil {
+		return err
+	}
+	if len(found) != len(uniqueIDs(vehicleIDs)) {
+		return fmt.Errorf("%w: unknown vehicle in list", ErrNotFound)
+	}
+	return s.groups.AddMembers(ctx, id, vehicleIDs)
+}
+
+func (s *groupService) RemoveVehicle(ctx context.Context, id, vehicleID uuid.UUID) error {
+	return notFound(s.groups.RemoveMember(ctx, id, vehicleID))
+}
+
+func (s *groupService) Vehicles(ctx context.Context, id uuid.UUID, recursive bool) ([]model.Vehicle, error) {
+	if _, err := s.groups.Get(ctx, id); err != nil {
+		return nil, notFound(err)
+	}
+	ids, err := s.groups.VehicleIDs(ctx, id, recursive)
+	if err != nil {
+		return nil, err
+	}
+	return s.vehicles.ListByIDs(ctx, ids)
+}
+
+func uniqueIDs(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
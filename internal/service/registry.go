@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/vin"
+	"github.com/google/uuid"
+)
+
+// VehicleRegistry manages vehicle metadata. Telemetry stays with VehicleService.
+type VehicleRegistry interface {
+	// Create registers a vehicle; id may be uuid.Nil to have one generated.
+	Create(ctx context.Context, id uuid.UUID, p model.VehiclePatch) (model.Vehicle, error)
+	Get(ctx context.Context, id uuid.UUID) (model.Vehicle, error)
+	List(ctx context.Context, f repository.VehicleFilter) ([]model.Vehicle, error)
+	Update(ctx context.Context, id uuid.UUID, p model.VehiclePatch) (model.Vehicle, error)
+	Archive(ctx context.Context, id uuid.UUID) (model.Vehicle, error)
+	Restore(ctx context.Context, id uuid.UUID) (model.Vehicle, error)
+}
+
+type registry struct {
+	repo   *repository.VehicleRepo
+	grants *repository.AccessRepo
+}
+
+// NewRegistry returns a registry whose reads are limited to the vehicles a
+// restricted caller has been granted.
+func NewRegistry(r *repository.VehicleRepo, a *repository.AccessRepo) VehicleRegistry {
+	return &registry{repo: r, grants: a}
+}
+
+func (s *registry) Create(ctx context.Context, id uuid.UUID, p model.VehiclePatch) (model.Vehicle, error) {
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+	v := model.Vehicle{ID: id}
+	p.Apply(&v)
+	v.Normalize()
+	if err := v.Validate(); err != nil {
+		return model.Vehicle{}, err
+	}
+	applyVIN(&v)
+	if err := s.repo.Create(ctx, &v); err != nil {
+		return model.Vehicle{}, notFound(err)
+	}
+	return v, nil
+}
+
+func (s *registry) Get(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	if err := checkVehicle(ctx, s.grants, id); err != nil {
+		return model.Vehicle{}, err
+	}
+	v, err := s.repo.Get(ctx, id)
+	return v, notFound(err)
+}
+
+func (s *registry) List(ctx context.Context, f repository.VehicleFilter) ([]model.Vehicle, error) {
+	ids, restricted, err := visibleVehicles(ctx, s.grants)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		f.IDs = ids
+	}
+	return s.repo.List(ctx, f)
+}
+
+// Update applies p to the stored vehicle; archived vehicles are read-only.
+func (s *registry) Update(ctx context.Context, id uuid.UUID, p model.VehiclePatch) (model.Vehicle, error) {
+	v, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return model.Vehicle{}, notFound(err)
+	}
+	if v.ArchivedAt != nil {
+		return model.Vehicle{}, fmt.Errorf("%w: vehicle is archived", ErrConflict)
+	}
+	p.Apply(&v)
+	v.Normalize()
+	if err := v.Validate(); err != nil {
+		return model.Vehicle{}, err
+	}
+	applyVIN(&v)
+	if err := s.repo.Update(ctx, &v); err != nil {
+		return model.Vehicle{}, notFound(err)
+	}
+	return v, nil
+}
+
+func (s *registry) Archive(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	now := time.Now().UTC()
+	return s.setArchived(ctx, id, &now)
+}
+
+func (s *registry) Restore(ctx context.Context, id uuid.UUID) (model.Vehicle, error) {
+	return s.setArchived(ctx, id, nil)
+}
+
+func (s *registry) setArchived(ctx context.Context, id uuid.UUID, at *time.Time) (model.Vehicle, error) {
+	if err := s.repo.SetArchived(ctx, id, at); err != nil {
+		return model.Vehicle{}, notFound(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// applyVIN pre-fills make and year from the decoded VIN and warns where the
+// entered values disagree with it. v must already be validated.
+func applyVIN(v *model.Vehicle) {
+	if v.VIN == nil {
+		return
+	}
+	info, err := vin.Decode(*v.VIN)
+	if err != nil {
+		return
+	}
+	if !info.CheckDigitOK {
+		v.Warnings = append(v.Warnings, fmt.Sprintf("vin check digit %c does not match", (*v.VIN)[8]))
+	}
+	if info.Manufacturer != "" {
+		switch {
+		case v.Make == "":
+			v.Make = info.Manufacturer
+		case !strings.EqualFold(v.Make, info.Manufacturer):
+			v.Warnings = append(v.Warnings, fmt.Sprintf("make %q does not match vin manufacturer %q", v.Make, info.Manufacturer))
+		}
+	}
+	if info.ModelYear != 0 {
+		switch {
+		case v.Year == 0:
+			v.Year = info.ModelYear
+		case v.Year != info.ModelYear:
+			v.Warnings = append(v.Warnings, fmt.Sprintf("year %d does not match vin model year %d", v.Year, info.ModelYear))
+		}
+	}
+}
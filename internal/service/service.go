@@ -2,64 +2,418 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
 	"time"
 
 	"github.com/aditi2420/fleet-tracker/internal/cache"
 	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/obd"
 	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
+// xfetchBeta scales how eagerly refreshEarly reloads; above 1 favours
+// earlier reloads.
+const xfetchBeta = 1.0
+
 // VehicleService is consumed by HTTP handlers (and the stream processor).
 type VehicleService interface {
 	CurrentStatus(ctx context.Context, vehicleID uuid.UUID) (model.Status, error)
 	ListTrips(ctx context.Context, vehicleID uuid.UUID, since time.Duration) ([]model.Trips, error)
-	Ingest(ctx context.Context, vehicleID uuid.UUID, plate string, s model.Status) error
+	History(ctx context.Context, vehicleID uuid.UUID, from, to time.Time, limit int) ([]model.Status, error)
+	Signals(ctx context.Context, vehicleID uuid.UUID, name string, from, to time.Time, limit int) ([]model.SignalSample, error)
+	ActiveDTCs(ctx context.Context, vehicleID uuid.UUID) ([]model.ActiveDTC, error)
+	Events(ctx context.Context, vehicleID uuid.UUID, since time.Duration) ([]model.Event, error)
+	Ingest(ctx context.Context, p model.InputRequestPayload) error
+
+	// GroupStatuses and GroupTrips cover every vehicle in the group's subtree.
+	GroupStatuses(ctx context.Context, groupID uuid.UUID) ([]model.VehicleStatus, error)
+	GroupTrips(ctx context.Context, groupID uuid.UUID, since time.Duration) ([]model.Trips, error)
 }
 
 type service struct {
 	vehRepo  *repository.VehicleRepo
 	tripRepo *repository.TripRepo
+	posRepo  *repository.PositionRepo
+	diagRepo *repository.DiagnosticsRepo
+	devRepo  *repository.DeviceRepo
+	drvRepo  *repository.DriverRepo
+	grpRepo  *repository.GroupRepo
+	accRepo  *repository.AccessRepo
 	cache    cache.VehicleCache
+
+	// status loads: one database read per vehicle at a time, unknown
+	// vehicles remembered briefly, and the recent load time for XFetch
+	loads    singleflight.Group
+	misses   *cache.Misses
+	loadTime atomic.Int64
 }
 
-func New(v *repository.VehicleRepo, t *repository.TripRepo, c cache.VehicleCache) VehicleService {
-	return &service{vehRepo: v, tripRepo: t, cache: c}
+func New(
+	v *repository.VehicleRepo,
+	t *repository.TripRepo,
+	p *repository.PositionRepo,
+	d *repository.DiagnosticsRepo,
+	dev *repository.DeviceRepo,
+	drv *repository.DriverRepo,
+	g *repository.GroupRepo,
+	a *repository.AccessRepo,
+	c cache.VehicleCache,
+) VehicleService {
+	return &service{vehRepo: v, tripRepo: t, posRepo: p, diagRepo: d, devRepo: dev, drvRepo: drv, grpRepo: g, accRepo: a, cache: c,
+		misses: cache.NewMisses(0, 0)}
 }
 
+// Reads below answer ErrNotFound for vehicles a restricted caller has no
+// grant for; group reads silently leave them out.
+
+// CurrentStatus serves from the cache and falls back to the database when
+// the cache misses or fails. Entries close to expiring are occasionally
+// reloaded early (see refreshEarly) so a hot vehicle's entry doesn't expire
+// under every request at once.
 func (s *service) CurrentStatus(ctx context.Context, id uuid.UUID) (model.Status, error) {
-	//check in cache
-	if st, _ := s.cache.GetStatus(ctx, id); st != nil {
-		return *st, nil
-	}
-	veh, err := s.vehRepo.Get(ctx, id)
-	if err != nil {
+	if err := checkVehicle(ctx, s.accRepo, id); err != nil {
 		return model.Status{}, err
 	}
-	st, err := veh.DecodeStatus()
+	st, left, err := s.cache.GetStatusTTL(ctx, id)
 	if err != nil {
-		return model.Status{}, err
+		slog.Warn("status cache read failed", "vehicle", id, "err", err)
+	}
+	if st != nil {
+		if s.refreshEarly(left) {
+			if fresh, err := s.loadStatus(ctx, id); err == nil {
+				return fresh, nil
+			}
+		}
+		return *st, nil // a failed early reload still has the cached answer
+	}
+	if s.misses.Has(ctx, id) {
+		return model.Status{}, ErrNotFound
 	}
+	return s.loadStatus(ctx, id)
+}
 
-	_ = s.cache.SetStatus(ctx, id, st) // fire‑and‑forget
-	return st, nil
+// loadStatus reads the status from the database and caches it. Concurrent
+// calls for the same vehicle share one read; a caller whose ctx ends stops
+// waiting for it without cancelling it for the others.
+func (s *service) loadStatus(ctx context.Context, id uuid.UUID) (model.Status, error) {
+	tid, _ := tenant.FromContext(ctx)
+	ch := s.loads.DoChan(tid.String()+"/"+id.String(), func() (any, error) {
+		// every waiter gets this result, so the first one leaving must not cancel it
+		ctx := context.WithoutCancel(ctx)
+		start := time.Now()
+		veh, err := s.vehRepo.Get(ctx, id)
+		s.observeLoad(time.Since(start))
+		if err != nil {
+			err = notFound(err)
+			if errors.Is(err, ErrNotFound) {
+				s.misses.Add(ctx, id)
+			}
+			return nil, err
+		}
+		if len(veh.LastStatus) == 0 {
+			s.misses.Add(ctx, id)
+			return nil, ErrNotFound // registered but never reported
+		}
+		st, err := veh.DecodeStatus()
+		if err != nil {
+			return nil, err
+		}
+		// an Ingest that finished during the read has cached a newer fix;
+		// an early reload must not put the row's older one back
+		if cur, err := s.cache.GetStatus(ctx, id); err == nil && cur != nil && cur.Timestamp.After(st.Timestamp) {
+			return *cur, nil
+		}
+		if err := s.cache.SetStatus(ctx, id, st); err != nil {
+			slog.Warn("status cache write failed", "vehicle", id, "err", err)
+		}
+		return st, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return model.Status{}, res.Err
+		}
+		return res.Val.(model.Status), nil
+	case <-ctx.Done():
+		return model.Status{}, ctx.Err()
+	}
+}
+
+// refreshEarly is XFetch (Vattani et al., "Optimal Probabilistic Cache
+// Stampede Prevention"): a request reloads an entry with left to live when
+// loadTime·β·-ln(rand) ≥ left, so reloads become likely only within a few
+// load times of expiry and usually just one request does it.
+func (s *service) refreshEarly(left time.Duration) bool {
+	delta := s.loadTime.Load()
+	if delta == 0 || left <= 0 {
+		return false
+	}
+	return float64(delta)*xfetchBeta*-math.Log(rand.Float64()) >= float64(left)
+}
+
+// observeLoad keeps a moving average of database load times.
+func (s *service) observeLoad(d time.Duration) {
+	old := s.loadTime.Load()
+	if old == 0 {
+		s.loadTime.Store(int64(d))
+		return
+	}
+	s.loadTime.Store(old + (int64(d)-old)/8)
+}
+
+// readable returns ErrNotFound unless the vehicle exists in the caller's
+// tenant and is visible to the caller. Tenant scoping alone would answer
+// another tenant's vehicle with empty lists.
+func (s *service) readable(ctx context.Context, id uuid.UUID) error {
+	if err := checkVehicle(ctx, s.accRepo, id); err != nil {
+		return err
+	}
+	_, err := s.vehRepo.Get(ctx, id)
+	return notFound(err)
 }
 
 func (s *service) ListTrips(ctx context.Context, id uuid.UUID, since time.Duration) ([]model.Trips, error) {
+	if err := s.readable(ctx, id); err != nil {
+		return nil, err
+	}
 	return s.tripRepo.ListRecent(ctx, id, since)
 }
 
-func (s *service) Ingest(ctx context.Context, id uuid.UUID, plate string, st model.Status) error {
+func (s *service) GroupStatuses(ctx context.Context, groupID uuid.UUID) ([]model.VehicleStatus, error) {
+	ids, err := s.grpRepo.VehicleIDs(ctx, groupID, true)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if ids, err = filterVisible(ctx, s.accRepo, ids); err != nil {
+		return nil, err
+	}
+	vehicles, err := s.vehRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.VehicleStatus, 0, len(vehicles))
+	for _, v := range vehicles {
+		if len(v.LastStatus) == 0 {
+			continue // registered, never reported
+		}
+		st, err := v.DecodeStatus()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, model.VehicleStatus{VehicleID: v.ID, PlateNumber: v.PlateNumber, Status: st})
+	}
+	return res, nil
+}
+
+func (s *service) GroupTrips(ctx context.Context, groupID uuid.UUID, since time.Duration) ([]model.Trips, error) {
+	ids, err := s.grpRepo.VehicleIDs(ctx, groupID, true)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if ids, err = filterVisible(ctx, s.accRepo, ids); err != nil {
+		return nil, err
+	}
+	return s.tripRepo.ListRecentForVehicles(ctx, ids, since)
+}
+
+func (s *service) History(ctx context.Context, id uuid.UUID, from, to time.Time, limit int) ([]model.Status, error) {
+	if err := s.readable(ctx, id); err != nil {
+		return nil, err
+	}
+	rows, err := s.posRepo.List(ctx, id, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Status, len(rows))
+	for i, p := range rows {
+		res[i] = p.Status()
+	}
+	return res, nil
+}
+
+func (s *service) Signals(ctx context.Context, id uuid.UUID, name string, from, to time.Time, limit int) ([]model.SignalSample, error) {
+	if err := s.readable(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.diagRepo.ListSamples(ctx, id, name, from, to, limit)
+}
+
+func (s *service) ActiveDTCs(ctx context.Context, id uuid.UUID) ([]model.ActiveDTC, error) {
+	if err := s.readable(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.diagRepo.ActiveDTCs(ctx, id)
+}
+
+func (s *service) Events(ctx context.Context, id uuid.UUID, since time.Duration) ([]model.Event, error) {
+	if err := s.readable(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.diagRepo.ListEvents(ctx, id, since)
+}
+
+// Ingest validates and stores one fix and, when present, its decoded
+// diagnostics, in one transaction. Diagnostics are decoded before anything
+// is written so a malformed frame rejects the whole payload.
+func (s *service) Ingest(ctx context.Context, p model.InputRequestPayload) error {
+	id, plate, st := p.VehicleID, p.PlateNumber, p.Status
+	if err := st.Validate(); err != nil {
+		return err
+	}
+	if p.DeviceID != nil || p.IMEI != "" {
+		vid, err := s.resolveDevice(ctx, p.DeviceID, p.IMEI, st.Timestamp)
+		if err != nil {
+			return err
+		}
+		if id != uuid.Nil && id != vid {
+			return ErrDeviceMismatch
+		}
+		id = vid
+	}
+	var diag *repository.Diagnostics
+	if p.Diagnostics != nil {
+		res, err := obd.Decode(*p.Diagnostics)
+		if err != nil {
+			return err
+		}
+		diag = diagnostics(id, st.Timestamp, res)
+	}
+
+	driver, tagged, err := s.resolveDriver(ctx, id, p.DriverTag, st.Timestamp)
+	if err != nil {
+		return err
+	}
+
 	trip := model.Trips{
 		ID:        uuid.New(),
 		VehicleID: id,
+		DriverID:  driver,
 		StartTime: st.Timestamp,
 		AvgSpeed:  st.Speed,
 	}
 
-	if err := s.vehRepo.UpsertStatusAndInsertTrip(ctx, id, plate, st, trip); err != nil {
-		return err
+	current, err := s.vehRepo.UpsertStatusAndInsertTrip(ctx, id, plate, st, trip, diag)
+	if err != nil {
+		return notFound(err) // ErrNotFound: the id belongs to another tenant
+	}
+	if diag != nil {
+		logDTCEvents(id, diag.Events)
+	}
+	if tagged {
+		s.startTagShift(ctx, *driver, id, st.Timestamp)
 	}
+	s.misses.Forget(ctx, id)
+	if current { // a late fix only goes to history
+		if err := s.cache.SetStatus(ctx, id, st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveDevice finds the vehicle a device was installed in when the fix was
+// taken, so late or buffered fixes land on the right vehicle after a swap.
+func (s *service) resolveDevice(ctx context.Context, deviceID *uuid.UUID, imei string, ts time.Time) (uuid.UUID, error) {
+	if deviceID == nil {
+		d, err := s.devRepo.FindByIMEI(ctx, imei)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrUnknownDevice
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+		deviceID = &d.ID
+	}
+	vid, err := s.devRepo.VehicleAt(ctx, *deviceID, ts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, ErrDeviceUnassigned
+	}
+	return vid, err
+}
+
+// resolveDriver returns the driver to attribute a fix to: the owner of the
+// presented tag, or else whoever was on shift in the vehicle at ts. tagged is
+// true when the tag names a driver other than the one on shift, i.e. a new
+// shift should start. Unknown tags are logged rather than rejected so the
+// position is not lost.
+func (s *service) resolveDriver(ctx context.Context, vehicleID uuid.UUID, tag string, ts time.Time) (*uuid.UUID, bool, error) {
+	onShift, err := s.drvRepo.DriverAt(ctx, vehicleID, ts)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		onShift = uuid.Nil
+	case err != nil:
+		return nil, false, err
+	}
+	if tag = model.NormalizeTag(tag); tag != "" {
+		d, err := s.drvRepo.FindByTag(ctx, tag)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			slog.Warn("unknown driver tag", slog.String("vehicle_id", vehicleID.String()), slog.String("tag", tag))
+		case err != nil:
+			return nil, false, err
+		case d.ID != onShift:
+			if !d.LicenceValidAt(ts) {
+				slog.Warn("driver licence expired", slog.String("driver_id", d.ID.String()))
+			}
+			return &d.ID, true, nil
+		}
+	}
+	if onShift == uuid.Nil {
+		return nil, false, nil
+	}
+	return &onShift, false, nil
+}
+
+// startTagShift records the shift a tag read began. A buffered fix older than
+// the vehicle's latest shift cannot rewrite history; the trip keeps the tag's
+// driver but no shift is added.
+func (s *service) startTagShift(ctx context.Context, driverID, vehicleID uuid.UUID, ts time.Time) {
+	err := s.drvRepo.Assign(ctx, model.DriverAssignment{
+		ID:        uuid.New(),
+		DriverID:  driverID,
+		VehicleID: vehicleID,
+		From:      ts.UTC(),
+		Source:    model.ShiftTag,
+	})
+	if err != nil {
+		slog.Warn("driver shift not recorded",
+			slog.String("driver_id", driverID.String()),
+			slog.String("vehicle_id", vehicleID.String()),
+			slog.String("err", err.Error()),
+		)
+	}
+}
 
-	return s.cache.SetStatus(ctx, id, st)
+// diagnostics turns decoded values into the samples of the fix at ts.
+func diagnostics(id uuid.UUID, ts time.Time, res obd.Result) *repository.Diagnostics {
+	samples := make([]model.SignalSample, len(res.Values))
+	for i, v := range res.Values {
+		samples[i] = model.SignalSample{
+			ID:        uuid.New(),
+			VehicleID: id,
+			Name:      v.Name,
+			Timestamp: ts,
+			Value:     v.Value,
+			Unit:      v.Unit,
+		}
+	}
+	return &repository.Diagnostics{Samples: samples, Codes: res.DTCs}
+}
+
+func logDTCEvents(id uuid.UUID, events []model.Event) {
+	for _, e := range events {
+		slog.Warn("diagnostic trouble code changed",
+			slog.String("vehicle_id", id.String()),
+			slog.String("type", e.Type),
+			slog.String("code", e.Code),
+		)
+	}
 }
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // one :memory: database shared by every goroutine
+
+	require.NoError(t, db.AutoMigrate(
+		&model.Vehicle{}, &model.Trips{}, &model.Position{},
+		&model.SignalSample{}, &model.ActiveDTC{}, &model.Event{},
+		&model.Device{}, &model.DeviceAssignment{},
+		&model.Driver{}, &model.DriverAssignment{},
+		&model.VehicleGroup{}, &model.GroupMember{}, &model.AccessGrant{},
+		&model.RefreshToken{}, &model.User{}, &model.PasswordReset{},
+	))
+	require.NoError(t, repository.ScopeTenants(db))
+	return db
+}
+
+func newTestService(t *testing.T, db *gorm.DB, c cache.VehicleCache) *service {
+	trips := repository.NewTripRepo(db)
+	return New(
+		repository.NewVehicleRepo(db, trips), trips, repository.NewPositionRepo(db),
+		repository.NewDiagnosticsRepo(db), repository.NewDeviceRepo(db), repository.NewDriverRepo(db),
+		repository.NewGroupRepo(db), repository.NewAccessRepo(db), c,
+	).(*service)
+}
+
+func fix(ts time.Time, speed float64) model.Status {
+	return model.Status{Location: [2]float64{55.27, 25.2}, Speed: speed, Timestamp: ts}
+}
+
+func TestService_OtherTenantsVehicle(t *testing.T) {
+	svc := newTestService(t, setupTestDB(t), cache.NewMemory(100, time.Minute))
+	acme := tenant.WithID(context.Background(), uuid.New())
+	globex := tenant.WithID(context.Background(), uuid.New())
+
+	id := uuid.New()
+	require.NoError(t, svc.Ingest(acme, model.InputRequestPayload{VehicleID: id, PlateNumber: "A-1", Status: fix(time.Now().UTC(), 40)}))
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	tests := []struct {
+		name string
+		read func(ctx context.Context) error
+	}{
+		{name: "status", read: func(ctx context.Context) error { _, err := svc.CurrentStatus(ctx, id); return err }},
+		{name: "trips", read: func(ctx context.Context) error { _, err := svc.ListTrips(ctx, id, time.Hour); return err }},
+		{name: "history", read: func(ctx context.Context) error { _, err := svc.History(ctx, id, from, to, 10); return err }},
+		{name: "signals", read: func(ctx context.Context) error { _, err := svc.Signals(ctx, id, "", from, to, 10); return err }},
+		{name: "dtcs", read: func(ctx context.Context) error { _, err := svc.ActiveDTCs(ctx, id); return err }},
+		{name: "events", read: func(ctx context.Context) error { _, err := svc.Events(ctx, id, time.Hour); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.read(acme))
+			assert.ErrorIs(t, tt.read(globex), ErrNotFound)
+		})
+	}
+}
+
+// vehicleReads counts the queries on vehicles, holding each back by delay so
+// concurrent loads overlap.
+type vehicleReads struct {
+	n     atomic.Int32
+	delay atomic.Int64
+}
+
+func countVehicleReads(t *testing.T, db *gorm.DB) *vehicleReads {
+	r := &vehicleReads{}
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:count_vehicles", func(tx *gorm.DB) {
+		if tx.Statement.Table == "vehicles" {
+			r.n.Add(1)
+			time.Sleep(time.Duration(r.delay.Load()))
+		}
+	}))
+	return r
+}
+
+func TestService_LoadStatus(t *testing.T) {
+	db := setupTestDB(t)
+	reads := countVehicleReads(t, db)
+	ctx := tenant.WithID(context.Background(), uuid.New())
+	id := uuid.New()
+	at := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, newTestService(t, db, cache.NewMemory(100, time.Minute)).
+		Ingest(ctx, model.InputRequestPayload{VehicleID: id, PlateNumber: "A-1", Status: fix(at, 40)}))
+
+	t.Run("concurrent misses share one read", func(t *testing.T) {
+		svc := newTestService(t, db, cache.NewMemory(100, time.Minute))
+		reads.n.Store(0)
+		reads.delay.Store(int64(50 * time.Millisecond))
+		defer reads.delay.Store(0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				st, err := svc.CurrentStatus(ctx, id)
+				assert.NoError(t, err)
+				assert.Equal(t, 40.0, st.Speed)
+			}()
+		}
+		wg.Wait()
+		assert.EqualValues(t, 1, reads.n.Load())
+	})
+
+	t.Run("unknown vehicle is remembered", func(t *testing.T) {
+		svc := newTestService(t, db, cache.NewMemory(100, time.Minute))
+		reads.n.Store(0)
+		unknown := uuid.New()
+		for i := 0; i < 3; i++ {
+			_, err := svc.CurrentStatus(ctx, unknown)
+			assert.ErrorIs(t, err, ErrNotFound)
+		}
+		assert.EqualValues(t, 1, reads.n.Load())
+	})
+
+	t.Run("entry close to expiry is reloaded early", func(t *testing.T) {
+		c := cache.NewMemory(100, time.Second)
+		svc := newTestService(t, db, c)
+		require.NoError(t, c.SetStatus(ctx, id, fix(at.Add(-time.Minute), 10)))
+		svc.loadTime.Store(int64(time.Hour)) // a 1s entry is "close" to a 1h load
+		reads.n.Store(0)
+
+		st, err := svc.CurrentStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 40.0, st.Speed)
+		assert.EqualValues(t, 1, reads.n.Load())
+		cached, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 40.0, cached.Speed)
+	})
+
+	t.Run("reload keeps a fresher cached fix", func(t *testing.T) {
+		c := cache.NewMemory(100, time.Minute)
+		svc := newTestService(t, db, c)
+		require.NoError(t, c.SetStatus(ctx, id, fix(at.Add(time.Minute), 70))) // ingested during the read
+
+		st, err := svc.loadStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 70.0, st.Speed)
+		cached, err := c.GetStatus(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, 70.0, cached.Speed)
+	})
+
+	t.Run("waiter gives up when its context ends", func(t *testing.T) {
+		svc := newTestService(t, db, cache.NewMemory(100, time.Minute))
+		reads.delay.Store(int64(200 * time.Millisecond))
+		defer reads.delay.Store(0)
+
+		short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := svc.CurrentStatus(short, id)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+		st, err := svc.CurrentStatus(ctx, id) // joins or follows the abandoned load
+		require.NoError(t, err)
+		assert.Equal(t, 40.0, st.Speed)
+	})
+}
+
+func TestService_IngestLateFix(t *testing.T) {
+	c := cache.NewMemory(100, time.Minute)
+	svc := newTestService(t, setupTestDB(t), c)
+	ctx := tenant.WithID(context.Background(), uuid.New())
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	require.NoError(t, svc.Ingest(ctx, model.InputRequestPayload{VehicleID: id, Status: fix(now, 50)}))
+	require.NoError(t, svc.Ingest(ctx, model.InputRequestPayload{VehicleID: id, Status: fix(now.Add(-time.Minute), 10)}))
+
+	cached, err := c.GetStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, cached.Speed, "cache keeps the newer fix")
+	svc.cache = cache.NewMemory(100, time.Minute) // force a database read
+	st, err := svc.CurrentStatus(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, st.Speed, "so does the database")
+
+	hist, err := svc.History(ctx, id, now.Add(-time.Hour), now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, hist, 2, "the late fix is in the history")
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRefreshToken covers unknown, expired, revoked and reused
+// refresh tokens alike.
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// ErrNotSessionSource is returned by Start for tokens that must not open a
+// session of their own: session access tokens (refresh instead) and tokens
+// of other issuers such as an OIDC provider.
+var ErrNotSessionSource = errors.New("only long-lived API tokens can start a session")
+
+// TokenPair is what a login or refresh returns.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // seconds
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SessionConfig sets how session tokens are signed and how long they live.
+type SessionConfig struct {
+	Signer     auth.Signer
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// SessionService issues short-lived access tokens backed by rotating refresh
+// tokens, and revokes them.
+type SessionService interface {
+	// Start opens a session with the subject, tenant and grants of claims,
+	// which must be those of a token the API minted outside a session
+	// (cmd/token) or of a login.
+	Start(ctx context.Context, claims auth.Claims) (TokenPair, error)
+	// Refresh exchanges a refresh token for a new pair; the old one is used up.
+	Refresh(ctx context.Context, refreshToken string) (TokenPair, error)
+	// Logout revokes the calling access token and the session it came from.
+	Logout(ctx context.Context, claims auth.Claims) error
+	// RevokeAll ends every session of subject and rejects all of its access
+	// tokens issued so far, however they were minted.
+	RevokeAll(ctx context.Context, subject string) error
+}
+
+type sessionService struct {
+	repo    *repository.SessionRepo
+	revoked cache.RevocationList
+	cfg     SessionConfig
+}
+
+func NewSessions(r *repository.SessionRepo, revoked cache.RevocationList, cfg SessionConfig) SessionService {
+	return &sessionService{repo: r, revoked: revoked, cfg: cfg}
+}
+
+func (s *sessionService) Start(ctx context.Context, claims auth.Claims) (TokenPair, error) {
+	// a stolen session token would otherwise buy a session that outlives the
+	// one it came from; a login's claims carry no issuer
+	if claims.Session != "" || claims.Issuer != "" && claims.Issuer != s.cfg.Signer.Issuer {
+		return TokenPair{}, ErrNotSessionSource
+	}
+	tid, err := tenant.Parse(claims.Tenant)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	t := model.RefreshToken{
+		TenantID:   tid,
+		Family:     uuid.New(),
+		Subject:    claims.Subject,
+		Roles:      claims.Roles,
+		Scopes:     claims.Scopes,
+		Restricted: claims.Restricted,
+	}
+	return s.issue(tenant.WithID(ctx, tid), t)
+}
+
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	idPart, secret, _ := strings.Cut(refreshToken, ".")
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	t, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(notFound(err), ErrNotFound) {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, err
+	}
+	tenant.Record(ctx, t.TenantID)
+	if subtle.ConstantTimeCompare([]byte(t.SecretHash), []byte(model.HashSecret(secret))) != 1 {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	now := time.Now().UTC()
+	if t.UsedAt != nil && t.RevokedAt == nil {
+		return TokenPair{}, s.reused(ctx, t, now)
+	}
+	if !t.Active(now) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	ok, err := s.repo.Use(ctx, t.ID, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !ok {
+		return TokenPair{}, s.reused(ctx, t, now) // lost a race with another refresh
+	}
+	t.ID, t.UsedAt, t.CreatedAt = uuid.UUID{}, nil, time.Time{}
+	return s.issue(tenant.WithID(ctx, t.TenantID), t)
+}
+
+// reused ends a session whose refresh token was presented twice: one of the
+// two holders stole it, and we can't tell which.
+func (s *sessionService) reused(ctx context.Context, t model.RefreshToken, now time.Time) error {
+	if err := s.repo.RevokeFamily(ctx, t.Family, now); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+func (s *sessionService) Logout(ctx context.Context, claims auth.Claims) error {
+	if claims.ExpiresAt != nil {
+		if err := s.revoked.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	family, err := uuid.Parse(claims.Session)
+	if err != nil {
+		return nil // a token minted outside a session
+	}
+	return s.repo.RevokeFamily(ctx, family, time.Now().UTC())
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, subject string) error {
+	now := time.Now().UTC()
+	if err := s.repo.RevokeSubject(ctx, subject, now); err != nil {
+		return err
+	}
+	return s.revoked.RevokeSubject(ctx, subject, now)
+}
+
+// issue stores t as the next refresh token of its family and signs an access
+// token for the same grants.
+func (s *sessionService) issue(ctx context.Context, t model.RefreshToken) (TokenPair, error) {
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	t.ID = uuid.New()
+	t.SecretHash = model.HashSecret(secret)
+	t.ExpiresAt = time.Now().UTC().Add(s.cfg.RefreshTTL)
+	if err := s.repo.Create(ctx, &t); err != nil {
+		return TokenPair{}, err
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: t.Subject},
+		Roles:            t.Roles,
+		Scopes:           t.Scopes,
+		Restricted:       t.Restricted,
+		Session:          t.Family.String(),
+	}
+	if t.TenantID != tenant.Default {
+		claims.Tenant = t.TenantID.String()
+	}
+	access, err := s.cfg.Signer.Sign(claims, s.cfg.AccessTTL)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return TokenPair{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.cfg.AccessTTL.Seconds()),
+		RefreshToken: t.ID.String() + "." + secret,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionService(t *testing.T) {
+	secret := []byte("test-secret")
+	svc := NewSessions(repository.NewSessionRepo(setupTestDB(t)), cache.NewMemoryRevocationList(), SessionConfig{
+		Signer:     auth.Signer{Secret: secret, Issuer: "fleet"},
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	ctx := context.Background()
+	acme := uuid.New()
+	apiToken := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "alice", Issuer: "fleet"},
+		Tenant:           acme.String(),
+		Roles:            []string{auth.RoleDispatcher},
+	}
+	access := func(t *testing.T, pair TokenPair) auth.Claims {
+		var c auth.Claims
+		_, err := jwt.ParseWithClaims(pair.AccessToken, &c, func(*jwt.Token) (any, error) { return secret, nil })
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("start copies the grants into a new session", func(t *testing.T) {
+		pair, err := svc.Start(ctx, apiToken)
+		require.NoError(t, err)
+		c := access(t, pair)
+		assert.Equal(t, "alice", c.Subject)
+		assert.Equal(t, acme.String(), c.Tenant)
+		assert.Equal(t, []string{auth.RoleDispatcher}, c.Roles)
+		assert.NotEmpty(t, c.Session)
+		assert.NotEmpty(t, pair.RefreshToken)
+	})
+
+	t.Run("start refuses session and foreign tokens", func(t *testing.T) {
+		pair, err := svc.Start(ctx, apiToken)
+		require.NoError(t, err)
+		_, err = svc.Start(ctx, access(t, pair))
+		assert.ErrorIs(t, err, ErrNotSessionSource, "session access token")
+
+		sso := apiToken
+		sso.Issuer = "https://idp.example.com"
+		_, err = svc.Start(ctx, sso)
+		assert.ErrorIs(t, err, ErrNotSessionSource, "OIDC token")
+	})
+
+	t.Run("refresh rotates within the session", func(t *testing.T) {
+		first, err := svc.Start(ctx, apiToken)
+		require.NoError(t, err)
+		second, err := svc.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+		assert.Equal(t, access(t, first).Session, access(t, second).Session)
+		assert.Equal(t, acme.String(), access(t, second).Tenant)
+
+		_, err = svc.Refresh(ctx, second.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reuse ends the session", func(t *testing.T) {
+		first, err := svc.Start(ctx, apiToken)
+		require.NoError(t, err)
+		second, err := svc.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = svc.Refresh(ctx, first.RefreshToken)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken, "used token presented again")
+		_, err = svc.Refresh(ctx, second.RefreshToken)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken, "the rest of the family is revoked")
+	})
+
+	t.Run("bad tokens", func(t *testing.T) {
+		for _, tok := range []string{"", "nope", uuid.NewString() + ".secret"} {
+			_, err := svc.Refresh(ctx, tok)
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken, tok)
+		}
+		pair, err := svc.Start(ctx, apiToken)
+		require.NoError(t, err)
+		id, _, _ := strings.Cut(pair.RefreshToken, ".")
+		_, err = svc.Refresh(ctx, id+".wrong")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken, "wrong secret")
+	})
+}
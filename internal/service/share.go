@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/google/uuid"
+)
+
+// Share link states the public endpoint reports.
+var (
+	ErrShareLinkGone       = errors.New("share link expired or revoked")
+	ErrShareLinkNotStarted = errors.New("share link not active yet")
+)
+
+// ShareConfig holds the token signing secret and how much of the track a
+// link shows.
+type ShareConfig struct {
+	Secret          []byte
+	Breadcrumb      time.Duration // how far back the breadcrumb reaches
+	BreadcrumbLimit int           // most recent fixes at most
+}
+
+// ShareService issues links that let anybody follow one vehicle for a while.
+type ShareService interface {
+	// Create stores the link and returns it with its token, which is shown
+	// only this once. A zero From means now.
+	Create(ctx context.Context, vehicleID uuid.UUID, l model.ShareLink) (model.ShareLink, string, error)
+	List(ctx context.Context, vehicleID uuid.UUID) ([]model.ShareLink, error)
+	Revoke(ctx context.Context, vehicleID, id uuid.UUID) error
+	// View serves a token without any other authentication. Malformed or
+	// forged tokens are ErrNotFound.
+	View(ctx context.Context, token string) (model.SharedView, error)
+}
+
+type shareService struct {
+	links    *repository.ShareRepo
+	vehRepo  *repository.VehicleRepo
+	vehicles VehicleService
+	cfg      ShareConfig
+	now      func() time.Time
+}
+
+func NewShares(l *repository.ShareRepo, v *repository.VehicleRepo, vehicles VehicleService, cfg ShareConfig) ShareService {
+	return &shareService{links: l, vehRepo: v, vehicles: vehicles, cfg: cfg, now: time.Now}
+}
+
+func (s *shareService) Create(ctx context.Context, vehicleID uuid.UUID, l model.ShareLink) (model.ShareLink, string, error) {
+	if _, err := s.vehRepo.Get(ctx, vehicleID); err != nil {
+		return model.ShareLink{}, "", notFound(err)
+	}
+	l.ID, l.VehicleID = uuid.New(), vehicleID
+	if l.From.IsZero() {
+		l.From = s.now()
+	}
+	// the token carries whole seconds
+	l.From, l.To = l.From.UTC().Truncate(time.Second), l.To.UTC().Truncate(time.Second)
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		l.CreatedBy = p.Subject
+	}
+	if err := l.Validate(); err != nil {
+		return model.ShareLink{}, "", err
+	}
+	if err := s.links.Create(ctx, &l); err != nil {
+		return model.ShareLink{}, "", err
+	}
+	return l, model.ShareToken(l.ID, l.To, s.cfg.Secret), nil
+}
+
+func (s *shareService) List(ctx context.Context, vehicleID uuid.UUID) ([]model.ShareLink, error) {
+	return s.links.List(ctx, vehicleID)
+}
+
+func (s *shareService) Revoke(ctx context.Context, vehicleID, id uuid.UUID) error {
+	return notFound(s.links.Revoke(ctx, vehicleID, id, s.now()))
+}
+
+func (s *shareService) View(ctx context.Context, token string) (model.SharedView, error) {
+	id, expires, err := model.ParseShareToken(token, s.cfg.Secret)
+	if err != nil {
+		return model.SharedView{}, ErrNotFound
+	}
+	now := s.now()
+	if !now.Before(expires) {
+		return model.SharedView{}, ErrShareLinkGone
+	}
+	l, err := s.links.Get(ctx, id)
+	if err != nil {
+		return model.SharedView{}, notFound(err)
+	}
+	switch {
+	case l.RevokedAt != nil || !now.Before(l.To):
+		return model.SharedView{}, ErrShareLinkGone
+	case now.Before(l.From):
+		return model.SharedView{}, ErrShareLinkNotStarted
+	}
+
+	// from here on act inside the link's tenant, as a system caller
+	ctx = tenant.WithID(ctx, l.TenantID)
+	view := model.SharedView{Label: l.Label, ExpiresAt: l.To, Breadcrumb: []model.SharedFix{}}
+
+	st, err := s.vehicles.CurrentStatus(ctx, l.VehicleID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return model.SharedView{}, err
+	case !st.Timestamp.Before(l.From) && st.Timestamp.Before(l.To):
+		f := l.Share(st)
+		view.Status = &f
+	}
+
+	from := now.Add(-s.cfg.Breadcrumb)
+	if from.Before(l.From) {
+		from = l.From
+	}
+	fixes, err := s.vehicles.History(ctx, l.VehicleID, from, now, s.cfg.BreadcrumbLimit)
+	if err != nil {
+		return model.SharedView{}, err
+	}
+	for i := len(fixes) - 1; i >= 0; i-- { // newest first → oldest first
+		view.Breadcrumb = append(view.Breadcrumb, l.Share(fixes[i]))
+	}
+	return view, nil
+}
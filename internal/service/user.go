@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/aditi2420/fleet-tracker/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Login and account errors. Unknown emails and wrong passwords share
+// ErrInvalidLogin so the API doesn't reveal which accounts exist.
+var (
+	ErrInvalidLogin      = errors.New("invalid email or password")
+	ErrTOTPRequired      = errors.New("totp code required")
+	ErrInvalidTOTP       = errors.New("invalid totp code")
+	ErrTOTPNotEnrolled   = errors.New("totp enrolment not started")
+	ErrAccountLocked     = errors.New("account locked after repeated failed logins")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+// UserConfig tunes lockout and password resets.
+type UserConfig struct {
+	MaxFailures int           // consecutive failed logins before the account locks
+	LockFor     time.Duration // how long it stays locked
+	ResetTTL    time.Duration // validity of password reset tokens
+	TOTPIssuer  string        // shown by authenticator apps
+}
+
+// TOTPEnrolment is shown once when a user starts setting up a second factor.
+type TOTPEnrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// UserService manages local accounts and password/TOTP login.
+type UserService interface {
+	Create(ctx context.Context, p model.UserPatch, password string) (model.User, error)
+	Get(ctx context.Context, id uuid.UUID) (model.User, error)
+	List(ctx context.Context) ([]model.User, error)
+	// Update changes profile and grants; changed grants end the user's sessions.
+	Update(ctx context.Context, id uuid.UUID, p model.UserPatch) (model.User, error)
+
+	// Login checks the password and, once enrolled, the TOTP code, then
+	// opens a session. ctx must carry the tenant the email belongs to.
+	Login(ctx context.Context, email, password, code string) (TokenPair, error)
+
+	// IssueReset returns a single-use token for setting a new password.
+	IssueReset(ctx context.Context, id uuid.UUID) (token string, expires time.Time, err error)
+	// ResetPassword consumes a reset token, unlocks the account and ends
+	// every session of the user.
+	ResetPassword(ctx context.Context, token, password string) error
+
+	// SetupTOTP starts (or restarts) enrolment; EnableTOTP confirms it with a
+	// first code. DisableTOTP needs a current code too.
+	SetupTOTP(ctx context.Context, id uuid.UUID) (TOTPEnrolment, error)
+	EnableTOTP(ctx context.Context, id uuid.UUID, code string) error
+	DisableTOTP(ctx context.Context, id uuid.UUID, code string) error
+}
+
+type userService struct {
+	users    *repository.UserRepo
+	sessions SessionService
+	cfg      UserConfig
+}
+
+func NewUsers(u *repository.UserRepo, sessions SessionService, cfg UserConfig) UserService {
+	return &userService{users: u, sessions: sessions, cfg: cfg}
+}
+
+func (s *userService) Create(ctx context.Context, p model.UserPatch, password string) (model.User, error) {
+	u := model.User{ID: uuid.New()}
+	p.Apply(&u)
+	if err := s.check(&u); err != nil {
+		return model.User{}, err
+	}
+	if err := s.setPassword(&u, password); err != nil {
+		return model.User{}, err
+	}
+	if err := s.users.Create(ctx, &u); err != nil {
+		return model.User{}, notFound(err)
+	}
+	return u, nil
+}
+
+func (s *userService) Get(ctx context.Context, id uuid.UUID) (model.User, error) {
+	u, err := s.users.Get(ctx, id)
+	return u, notFound(err)
+}
+
+func (s *userService) List(ctx context.Context) ([]model.User, error) {
+	return s.users.List(ctx)
+}
+
+func (s *userService) Update(ctx context.Context, id uuid.UUID, p model.UserPatch) (model.User, error) {
+	u, err := s.users.Get(ctx, id)
+	if err != nil {
+		return model.User{}, notFound(err)
+	}
+	before := u
+	p.Apply(&u)
+	if err := s.check(&u); err != nil {
+		return model.User{}, err
+	}
+	if err := s.users.Update(ctx, &u); err != nil {
+		return model.User{}, notFound(err)
+	}
+	if !slices.Equal(before.Roles, u.Roles) || !slices.Equal(before.Scopes, u.Scopes) || before.Restricted != u.Restricted {
+		// refresh tokens carry the old grants
+		if err := s.sessions.RevokeAll(ctx, u.Subject()); err != nil {
+			return model.User{}, err
+		}
+	}
+	return u, nil
+}
+
+func (s *userService) check(u *model.User) error {
+	u.Normalize()
+	if err := u.Validate(); err != nil {
+		return err
+	}
+	if err := auth.Validate(u.Roles, u.Scopes); err != nil {
+		return fmt.Errorf("%w: %v", model.ErrInvalidUser, err)
+	}
+	return nil
+}
+
+func (s *userService) setPassword(u *model.User, password string) error {
+	if err := model.ValidatePassword(password); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash []byte
+)
+
+// burnPasswordCheck spends as long as a real bcrypt comparison, so unknown
+// emails can't be told apart by response time.
+func burnPasswordCheck(password string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+func (s *userService) Login(ctx context.Context, email, password, code string) (TokenPair, error) {
+	now := time.Now().UTC()
+	u, err := s.users.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(notFound(err), ErrNotFound) {
+			burnPasswordCheck(password)
+			return TokenPair{}, ErrInvalidLogin
+		}
+		return TokenPair{}, err
+	}
+	// the lock is only revealed to someone who knows the password, so it
+	// can't be used to probe which accounts exist
+	locked := u.LockedAt(now)
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		if locked {
+			return TokenPair{}, ErrInvalidLogin
+		}
+		return TokenPair{}, s.failed(ctx, u, now, ErrInvalidLogin)
+	}
+	if locked {
+		return TokenPair{}, ErrAccountLocked
+	}
+	if u.TOTPEnabled {
+		if strings.TrimSpace(code) == "" {
+			return TokenPair{}, ErrTOTPRequired
+		}
+		if err := s.verifyTOTP(ctx, u, code, now); err != nil {
+			if errors.Is(err, ErrInvalidTOTP) {
+				return TokenPair{}, s.failed(ctx, u, now, err)
+			}
+			return TokenPair{}, err
+		}
+	}
+	if err := s.users.RecordLogin(ctx, u.ID, now); err != nil {
+		return TokenPair{}, err
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: u.Subject()},
+		Roles:            u.Roles,
+		Scopes:           u.Scopes,
+		Restricted:       u.Restricted,
+	}
+	if u.TenantID != tenant.Default {
+		claims.Tenant = u.TenantID.String()
+	}
+	return s.sessions.Start(ctx, claims)
+}
+
+// failed counts a failed attempt, locks the account at the threshold, and
+// returns cause.
+func (s *userService) failed(ctx context.Context, u model.User, now time.Time, cause error) error {
+	n, err := s.users.RecordFailure(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if s.cfg.MaxFailures > 0 && n >= s.cfg.MaxFailures {
+		if err := s.users.Lock(ctx, u.ID, now.Add(s.cfg.LockFor)); err != nil {
+			return err
+		}
+	}
+	return cause
+}
+
+func (s *userService) verifyTOTP(ctx context.Context, u model.User, code string, now time.Time) error {
+	step, ok := totp.Verify(u.TOTPSecret, code, now)
+	if !ok {
+		return ErrInvalidTOTP
+	}
+	fresh, err := s.users.UseTOTPStep(ctx, u.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidTOTP // replayed
+	}
+	return nil
+}
+
+func (s *userService) IssueReset(ctx context.Context, id uuid.UUID) (string, time.Time, error) {
+	u, err := s.users.Get(ctx, id)
+	if err != nil {
+		return "", time.Time{}, notFound(err)
+	}
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	p := model.PasswordReset{
+		ID:         uuid.New(),
+		TenantID:   u.TenantID,
+		UserID:     u.ID,
+		SecretHash: model.HashSecret(secret),
+		ExpiresAt:  time.Now().UTC().Add(s.cfg.ResetTTL),
+	}
+	if err := s.users.CreateReset(ctx, &p); err != nil {
+		return "", time.Time{}, err
+	}
+	return p.ID.String() + "." + secret, p.ExpiresAt, nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, token, password string) error {
+	if err := model.ValidatePassword(password); err != nil {
+		return err
+	}
+	idPart, secret, _ := strings.Cut(token, ".")
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	p, err := s.users.GetReset(ctx, id)
+	if err != nil {
+		if errors.Is(notFound(err), ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	tenant.Record(ctx, p.TenantID)
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(p.SecretHash), []byte(model.HashSecret(secret))) != 1 ||
+		p.UsedAt != nil || !now.Before(p.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	if ok, err := s.users.UseReset(ctx, p.ID, now); err != nil || !ok {
+		if err == nil {
+			err = ErrInvalidResetToken
+		}
+		return err
+	}
+
+	ctx = tenant.WithID(ctx, p.TenantID)
+	u, err := s.users.Get(ctx, p.UserID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := s.setPassword(&u, password); err != nil {
+		return err
+	}
+	if err := s.users.Update(ctx, &u); err != nil {
+		return err
+	}
+	if err := s.users.Unlock(ctx, u.ID); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, u.Subject())
+}
+
+func (s *userService) SetupTOTP(ctx context.Context, id uuid.UUID) (TOTPEnrolment, error) {
+	u, err := s.users.Get(ctx, id)
+	if err != nil {
+		return TOTPEnrolment{}, notFound(err)
+	}
+	if u.TOTPEnabled {
+		return TOTPEnrolment{}, fmt.Errorf("%w: totp is already enabled", ErrConflict)
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return TOTPEnrolment{}, err
+	}
+	u.TOTPSecret = secret
+	if err := s.users.Update(ctx, &u); err != nil {
+		return TOTPEnrolment{}, err
+	}
+	return TOTPEnrolment{Secret: secret, URI: totp.URI(s.cfg.TOTPIssuer, u.Email, secret)}, nil
+}
+
+func (s *userService) EnableTOTP(ctx context.Context, id uuid.UUID, code string) error {
+	return s.setTOTP(ctx, id, code, true)
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, id uuid.UUID, code string) error {
+	return s.setTOTP(ctx, id, code, false)
+}
+
+func (s *userService) setTOTP(ctx context.Context, id uuid.UUID, code string, enabled bool) error {
+	u, err := s.users.Get(ctx, id)
+	if err != nil {
+		return notFound(err)
+	}
+	if u.TOTPSecret == "" {
+		return ErrTOTPNotEnrolled
+	}
+	if u.TOTPEnabled == enabled {
+		return nil
+	}
+	if err := s.verifyTOTP(ctx, u, code, time.Now().UTC()); err != nil {
+		return err
+	}
+	u.TOTPEnabled = enabled
+	if !enabled {
+		u.TOTPSecret = ""
+	}
+	return s.users.Update(ctx, &u)
+}
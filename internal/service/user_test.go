@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/auth"
+	"github.com/aditi2420/fleet-tracker/internal/cache"
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/repository"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_LoginLockout(t *testing.T) {
+	db := setupTestDB(t)
+	sessions := NewSessions(repository.NewSessionRepo(db), cache.NewMemoryRevocationList(), SessionConfig{
+		Signer: auth.Signer{Secret: []byte("test-secret")}, AccessTTL: time.Minute, RefreshTTL: time.Hour,
+	})
+	svc := NewUsers(repository.NewUserRepo(db), sessions, UserConfig{MaxFailures: 2, LockFor: time.Hour})
+	ctx := tenant.WithID(context.Background(), tenant.Default)
+
+	email, roles := "alice@example.com", []string{auth.RoleViewer}
+	_, err := svc.Create(ctx, model.UserPatch{Email: &email, Roles: &roles}, "correct horse")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = svc.Login(ctx, email, "wrong password", "")
+		assert.ErrorIs(t, err, ErrInvalidLogin)
+	}
+
+	_, err = svc.Login(ctx, email, "wrong password", "")
+	assert.ErrorIs(t, err, ErrInvalidLogin, "a wrong password doesn't reveal the lock")
+	_, err = svc.Login(ctx, "bob@example.com", "wrong password", "")
+	assert.ErrorIs(t, err, ErrInvalidLogin, "same answer as an unknown email")
+	_, err = svc.Login(ctx, email, "correct horse", "")
+	assert.ErrorIs(t, err, ErrAccountLocked, "the right password learns of the lock")
+}
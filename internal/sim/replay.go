@@ -0,0 +1,304 @@
+package sim
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/stream"
+	"github.com/google/uuid"
+)
+
+// ReplayOptions control how a recorded track is fed back into ingest.
+type ReplayOptions struct {
+	// Speed is the playback factor: 1 is real time, 10 and 100 accelerate,
+	// 0 sends as fast as the sink accepts.
+	Speed float64
+	// RemapToNow shifts every timestamp so the first fix happens now.
+	RemapToNow bool
+	// VehicleID and Plate, when set, replace whatever the track contains.
+	VehicleID uuid.UUID
+	Plate     string
+}
+
+// LoadTrack reads a .gpx or .csv file into fixes ordered by time.
+//
+// CSV needs a header with at least a time and a position; recognised columns
+// are timestamp|time, lat|latitude, lon|lng|longitude, speed (km/h),
+// heading|course, altitude|ele, vehicle_id and plate.
+func LoadTrack(path string) ([]stream.Payload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fixes []stream.Payload
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gpx":
+		fixes, err = readGPX(f)
+	case ".csv":
+		fixes, err = readCSV(f)
+	default:
+		return nil, fmt.Errorf("track %s: unsupported format (want .gpx or .csv)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("track %s: %w", path, err)
+	}
+	if len(fixes) == 0 {
+		return nil, fmt.Errorf("track %s: no fixes", path)
+	}
+	sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].Timestamp.Before(fixes[j].Timestamp) })
+	fillDerived(fixes)
+	return fixes, nil
+}
+
+// Replay sends fixes to sink, pacing them by their original spacing divided
+// by opts.Speed.
+func Replay(ctx context.Context, fixes []stream.Payload, sink Sink, opts ReplayOptions) error {
+	if len(fixes) == 0 {
+		return nil
+	}
+	if opts.VehicleID == uuid.Nil {
+		for _, p := range fixes {
+			if p.VehicleID == uuid.Nil {
+				return errors.New("track has fixes without vehicle_id; set a vehicle to replay as")
+			}
+		}
+	}
+	var shift time.Duration
+	if opts.RemapToNow {
+		shift = time.Now().UTC().Sub(fixes[0].Timestamp)
+	}
+
+	start := time.Now()
+	for _, p := range fixes {
+		if opts.Speed > 0 {
+			due := start.Add(time.Duration(float64(p.Timestamp.Sub(fixes[0].Timestamp)) / opts.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		p.Timestamp = p.Timestamp.Add(shift)
+		if opts.VehicleID != uuid.Nil {
+			p.VehicleID = opts.VehicleID
+		}
+		if opts.Plate != "" {
+			p.Plate = opts.Plate
+		}
+		if p.Plate == "" {
+			p.Plate = p.VehicleID.String()
+		}
+		if err := sink.Send(ctx, p); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSpeed accepts "x10", "10", "1" or "max".
+func ParseSpeed(s string) (float64, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "x")
+	if s == "max" || s == "0" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("bad replay speed %q", s)
+	}
+	return f, nil
+}
+
+type gpxDoc struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []struct {
+				Lat    float64  `xml:"lat,attr"`
+				Lon    float64  `xml:"lon,attr"`
+				Ele    *float64 `xml:"ele"`
+				Time   string   `xml:"time"`
+				Speed  *float64 `xml:"speed"`  // GPX 1.0, m/s
+				Course *float64 `xml:"course"` // GPX 1.0, degrees
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+func readGPX(r io.Reader) ([]stream.Payload, error) {
+	var doc gpxDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var fixes []stream.Payload
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				ts, err := time.Parse(time.RFC3339, pt.Time)
+				if err != nil {
+					return nil, fmt.Errorf("trkpt %v,%v: %w", pt.Lat, pt.Lon, err)
+				}
+				st := model.Status{
+					Location:  [2]float64{pt.Lon, pt.Lat},
+					Timestamp: ts.UTC(),
+					Altitude:  pt.Ele,
+					Heading:   pt.Course,
+					Speed:     -1, // derived later unless given
+				}
+				if pt.Speed != nil {
+					st.Speed = *pt.Speed * 3.6
+				}
+				fixes = append(fixes, stream.Payload{Status: st})
+			}
+		}
+	}
+	return fixes, nil
+}
+
+func readCSV(r io.Reader) ([]stream.Payload, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "timestamp", "time":
+			col["time"] = i
+		case "lat", "latitude":
+			col["lat"] = i
+		case "lon", "lng", "longitude":
+			col["lon"] = i
+		case "speed":
+			col["speed"] = i
+		case "heading", "course":
+			col["heading"] = i
+		case "altitude", "ele":
+			col["altitude"] = i
+		case "vehicle_id":
+			col["vehicle_id"] = i
+		case "plate", "plate_number":
+			col["plate"] = i
+		}
+	}
+	for _, need := range []string{"time", "lat", "lon"} {
+		if _, ok := col[need]; !ok {
+			return nil, fmt.Errorf("csv header is missing a %s column", need)
+		}
+	}
+
+	var fixes []stream.Payload
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		ts, err := parseTime(get("time"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		lat, err1 := strconv.ParseFloat(get("lat"), 64)
+		lon, err2 := strconv.ParseFloat(get("lon"), 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("line %d: bad position", line)
+		}
+		p := stream.Payload{Plate: get("plate"), Status: model.Status{
+			Location:  [2]float64{lon, lat},
+			Timestamp: ts,
+			Speed:     -1,
+		}}
+		if v := get("speed"); v != "" {
+			if p.Speed, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("line %d: bad speed", line)
+			}
+		}
+		if v := get("heading"); v != "" {
+			h, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad heading", line)
+			}
+			p.Heading = &h
+		}
+		if v := get("altitude"); v != "" {
+			a, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad altitude", line)
+			}
+			p.Altitude = &a
+		}
+		if v := get("vehicle_id"); v != "" {
+			if p.VehicleID, err = uuid.Parse(v); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		fixes = append(fixes, p)
+	}
+	return fixes, nil
+}
+
+// parseTime accepts RFC 3339 or unix seconds.
+func parseTime(s string) (time.Time, error) {
+	if unix, err := strconv.ParseFloat(s, 64); err == nil {
+		sec := int64(unix)
+		return time.Unix(sec, int64((unix-float64(sec))*1e9)).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t.UTC(), err
+}
+
+// fillDerived computes speed and heading from consecutive fixes where the
+// recording didn't carry them.
+func fillDerived(fixes []stream.Payload) {
+	for i := range fixes {
+		p := &fixes[i]
+		if i == 0 {
+			if p.Speed < 0 {
+				p.Speed = 0
+			}
+			continue
+		}
+		prev := fixes[i-1]
+		if p.Speed < 0 {
+			p.Speed = 0
+			if dt := p.Timestamp.Sub(prev.Timestamp).Seconds(); dt > 0 {
+				p.Speed = haversine(prev.Location, p.Location) / dt * 3.6
+			}
+		}
+		if p.Heading == nil && prev.Location != p.Location {
+			h := bearing(prev.Location, p.Location)
+			p.Heading = &h
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package sim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/stream"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><trkseg>
+    <trkpt lat="25.1972" lon="55.2744"><ele>5</ele><time>2025-06-26T03:00:00Z</time></trkpt>
+    <trkpt lat="25.1981" lon="55.2744"><ele>6</ele><time>2025-06-26T03:00:10Z</time></trkpt>
+    <trkpt lat="25.1990" lon="55.2744"><time>2025-06-26T03:00:20Z</time></trkpt>
+  </trkseg></trk>
+</gpx>`
+
+const testCSV = `timestamp,latitude,longitude,speed,heading,vehicle_id
+2025-06-26T03:00:10Z,25.1981,55.2744,36,0,d9c1b442-fb2f-412a-9d2a-a3ab499cd91c
+1750906800,25.1972,55.2744,0,,d9c1b442-fb2f-412a-9d2a-a3ab499cd91c
+`
+
+type collectSink struct{ got []stream.Payload }
+
+func (s *collectSink) Send(_ context.Context, p stream.Payload) error {
+	s.got = append(s.got, p)
+	return nil
+}
+
+func writeTrack(t *testing.T, name, body string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+	return path
+}
+
+func TestLoadTrack(t *testing.T) {
+	t.Run("positive - gpx derives speed and heading", func(t *testing.T) {
+		fixes, err := LoadTrack(writeTrack(t, "t.gpx", testGPX))
+
+		require.NoError(t, err)
+		require.Len(t, fixes, 3)
+		assert.Equal(t, [2]float64{55.2744, 25.1972}, fixes[0].Location)
+		assert.Equal(t, 5.0, *fixes[0].Altitude)
+		// 0.0009 deg of latitude (~100 m) in 10 s is ~36 km/h due north
+		assert.InDelta(t, 36, fixes[1].Speed, 0.5)
+		assert.Equal(t, 0.0, *fixes[1].Heading)
+		for _, f := range fixes {
+			assert.NoError(t, f.Status.Validate())
+		}
+	})
+
+	t.Run("positive - csv is sorted by time", func(t *testing.T) {
+		fixes, err := LoadTrack(writeTrack(t, "t.csv", testCSV))
+
+		require.NoError(t, err)
+		require.Len(t, fixes, 2)
+		assert.Equal(t, time.Date(2025, 6, 26, 3, 0, 0, 0, time.UTC), fixes[0].Timestamp)
+		assert.Equal(t, 36.0, fixes[1].Speed)
+		assert.Equal(t, uuid.MustParse("d9c1b442-fb2f-412a-9d2a-a3ab499cd91c"), fixes[0].VehicleID)
+	})
+
+	t.Run("negative - csv without position", func(t *testing.T) {
+		_, err := LoadTrack(writeTrack(t, "t.csv", "time,speed\n2025-06-26T03:00:00Z,1\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestReplay(t *testing.T) {
+	fixes, err := LoadTrack(writeTrack(t, "t.gpx", testGPX))
+	require.NoError(t, err)
+
+	t.Run("positive - remap to now and override vehicle", func(t *testing.T) {
+		sink := &collectSink{}
+		id := uuid.New()
+		before := time.Now().UTC()
+
+		err := Replay(context.Background(), fixes, sink, ReplayOptions{Speed: 0, RemapToNow: true, VehicleID: id})
+
+		require.NoError(t, err)
+		require.Len(t, sink.got, 3)
+		assert.WithinDuration(t, before, sink.got[0].Timestamp, time.Second)
+		assert.Equal(t, 20*time.Second, sink.got[2].Timestamp.Sub(sink.got[0].Timestamp))
+		assert.Equal(t, id, sink.got[2].VehicleID)
+		assert.Equal(t, id.String(), sink.got[2].Plate)
+	})
+
+	t.Run("positive - accelerated playback keeps spacing", func(t *testing.T) {
+		sink := &collectSink{}
+		start := time.Now()
+
+		// 20 s of track at x1000 should take ~20 ms
+		err := Replay(context.Background(), fixes, sink, ReplayOptions{Speed: 1000, VehicleID: uuid.New()})
+
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.Equal(t, fixes[0].Timestamp, sink.got[0].Timestamp)
+	})
+
+	t.Run("negative - track without vehicle", func(t *testing.T) {
+		err := Replay(context.Background(), fixes, &collectSink{}, ReplayOptions{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParseSpeed(t *testing.T) {
+	for in, expected := range map[string]float64{"1": 1, "x10": 10, "X100": 100, "max": 0, "2.5": 2.5} {
+		got, err := ParseSpeed(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, got, in)
+	}
+	_, err := ParseSpeed("-3")
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Package sim drives simulated vehicles along scripted routes and emits
+// stream payloads, for demos, development and load tests.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is loaded from YAML or JSON (JSON is valid YAML).
+type Scenario struct {
+	Seed     int64         `yaml:"seed"`
+	Interval Duration      `yaml:"interval"` // time between fixes, default 2s
+	Duration Duration      `yaml:"duration"` // 0 runs until cancelled
+	Vehicles []VehicleSpec `yaml:"vehicles"`
+}
+
+// VehicleSpec describes one vehicle, or Count identical ones sharing a route.
+type VehicleSpec struct {
+	ID       string       `yaml:"id"`    // fixed UUID; random (from the seed) when empty
+	Plate    string       `yaml:"plate"` // "%d" is replaced by the copy index
+	Count    int          `yaml:"count"`
+	Route    [][2]float64 `yaml:"route"` // waypoints as [long, lat]
+	Loop     bool         `yaml:"loop"`
+	Speed    SpeedProfile `yaml:"speed"`
+	Stops    []Stop       `yaml:"stops"`
+	GPSNoise float64      `yaml:"gps_noise"` // 1-sigma position error, metres
+	// Stagger delays each copy's departure by this much times its index.
+	Stagger Duration `yaml:"stagger"`
+}
+
+// SpeedProfile is in km/h; Accel is km/h gained or lost per second.
+type SpeedProfile struct {
+	Cruise float64 `yaml:"cruise"`
+	Min    float64 `yaml:"min"`
+	Max    float64 `yaml:"max"`
+	Accel  float64 `yaml:"accel"`
+}
+
+// Stop halts the vehicle on reaching waypoint At.
+type Stop struct {
+	At          int      `yaml:"at"`
+	Duration    Duration `yaml:"duration"`
+	IgnitionOff bool     `yaml:"ignition_off"`
+}
+
+// Duration accepts Go duration strings ("90s", "5m") in scenario files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	var s string
+	if err := n.Decode(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Load reads and validates a scenario file.
+func Load(path string) (Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	var sc Scenario
+	if err := yaml.Unmarshal(b, &sc); err != nil {
+		return Scenario{}, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return Scenario{}, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// Validate fills defaults and rejects scenarios that cannot be driven.
+func (sc *Scenario) Validate() error {
+	if sc.Interval <= 0 {
+		sc.Interval = Duration(2 * time.Second)
+	}
+	if len(sc.Vehicles) == 0 {
+		return errors.New("no vehicles")
+	}
+	for i := range sc.Vehicles {
+		v := &sc.Vehicles[i]
+		if v.Count <= 0 {
+			v.Count = 1
+		}
+		if len(v.Route) < 2 {
+			return fmt.Errorf("vehicle %d: route needs at least two waypoints", i)
+		}
+		length := 0.0
+		for j, p := range v.Route {
+			if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+				return fmt.Errorf("vehicle %d: waypoint %v out of range", i, p)
+			}
+			if j > 0 {
+				length += haversine(v.Route[j-1], p)
+			}
+		}
+		// the vehicle would never get anywhere (a looped one never stops trying)
+		if length == 0 {
+			return fmt.Errorf("vehicle %d: route has zero length", i)
+		}
+		if v.ID != "" {
+			if v.Count > 1 {
+				return fmt.Errorf("vehicle %d: a fixed id needs count 1", i)
+			}
+			if _, err := uuid.Parse(v.ID); err != nil {
+				return fmt.Errorf("vehicle %d: %w", i, err)
+			}
+		}
+		if v.Speed.Cruise <= 0 {
+			v.Speed.Cruise = 50
+		}
+		if v.Speed.Min <= 0 || v.Speed.Min > v.Speed.Cruise {
+			v.Speed.Min = v.Speed.Cruise * 0.8
+		}
+		if v.Speed.Max < v.Speed.Cruise {
+			v.Speed.Max = v.Speed.Cruise * 1.2
+		}
+		if v.Speed.Accel <= 0 {
+			v.Speed.Accel = 5
+		}
+		for _, st := range v.Stops {
+			if st.At < 0 || st.At >= len(v.Route) {
+				return fmt.Errorf("vehicle %d: stop at waypoint %d outside route", i, st.At)
+			}
+		}
+	}
+	return nil
+}
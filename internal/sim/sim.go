@@ -0,0 +1,257 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/stream"
+	"github.com/google/uuid"
+)
+
+const earthRadius = 6371000.0 // metres
+
+// Simulator advances every vehicle of a scenario in lock-step. Given the same
+// seed and the same sequence of Step calls it produces identical output.
+type Simulator struct {
+	sc       Scenario
+	vehicles []*vehicle
+}
+
+type vehicle struct {
+	id    uuid.UUID
+	plate string
+	spec  *VehicleSpec
+	rng   *rand.Rand
+
+	leg      int     // route segment currently driven: Route[leg] -> Route[leg+1]
+	onLeg    float64 // metres travelled along the leg
+	speed    float64 // km/h
+	odometer float64 // km
+	ignition bool
+	parked   bool
+	stopLeft time.Duration // remaining stop time, >0 while stopped
+	delay    time.Duration // remaining departure stagger
+}
+
+func New(sc Scenario) (*Simulator, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	master := rand.New(rand.NewSource(sc.Seed))
+	s := &Simulator{sc: sc}
+	for i := range sc.Vehicles {
+		spec := &sc.Vehicles[i]
+		for n := 0; n < spec.Count; n++ {
+			v := &vehicle{
+				spec:     spec,
+				rng:      rand.New(rand.NewSource(master.Int63())),
+				ignition: true,
+				delay:    time.Duration(spec.Stagger) * time.Duration(n),
+			}
+			if spec.ID != "" {
+				v.id = uuid.MustParse(spec.ID)
+			} else {
+				v.id = uuidFrom(master)
+			}
+			v.plate = v.id.String()
+			if spec.Plate != "" {
+				v.plate = spec.Plate
+				if strings.Contains(spec.Plate, "%d") {
+					v.plate = fmt.Sprintf(spec.Plate, n+1)
+				}
+			}
+			s.vehicles = append(s.vehicles, v)
+		}
+	}
+	return s, nil
+}
+
+// Interval is the scenario's time between fixes.
+func (s *Simulator) Interval() time.Duration { return time.Duration(s.sc.Interval) }
+
+// Step advances every vehicle by dt and returns one fix per vehicle stamped now.
+func (s *Simulator) Step(now time.Time, dt time.Duration) []stream.Payload {
+	out := make([]stream.Payload, 0, len(s.vehicles))
+	for _, v := range s.vehicles {
+		v.advance(dt)
+		out = append(out, stream.Payload{VehicleID: v.id, Plate: v.plate, Status: v.status(now)})
+	}
+	return out
+}
+
+// Run emits a fix per vehicle every interval until ctx ends or the scenario
+// duration is reached. Sink failures are logged and the run carries on, the
+// same way a real tracker keeps reporting through a flaky uplink.
+func (s *Simulator) Run(ctx context.Context, sink Sink) error {
+	interval := s.Interval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var deadline <-chan time.Time
+	if s.sc.Duration > 0 {
+		timer := time.NewTimer(time.Duration(s.sc.Duration))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return nil
+		case t := <-ticker.C:
+			for _, p := range s.Step(t.UTC(), interval) {
+				if err := sink.Send(ctx, p); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					slog.Warn("simulator send failed", "vehicle_id", p.VehicleID, "err", err)
+				}
+			}
+		}
+	}
+}
+
+func (v *vehicle) advance(dt time.Duration) {
+	if v.parked {
+		return
+	}
+	if v.delay > 0 {
+		v.delay -= dt
+		return
+	}
+	if v.stopLeft > 0 {
+		v.stopLeft -= dt
+		if v.stopLeft <= 0 {
+			v.ignition = true
+		}
+		return
+	}
+
+	p := v.spec.Speed
+	target := p.Cruise + v.rng.NormFloat64()*(p.Max-p.Min)/4
+	target = math.Max(p.Min, math.Min(p.Max, target))
+	step := p.Accel * dt.Seconds()
+	switch {
+	case v.speed < target:
+		v.speed = math.Min(target, v.speed+step)
+	case v.speed > target:
+		v.speed = math.Max(target, v.speed-step)
+	}
+
+	dist := v.speed / 3.6 * dt.Seconds()
+	v.odometer += dist / 1000
+	route := v.spec.Route
+	for dist > 0 {
+		legLen := haversine(route[v.leg], route[v.leg+1])
+		if v.onLeg+dist < legLen {
+			v.onLeg += dist
+			return
+		}
+		dist -= legLen - v.onLeg
+		v.leg++
+		v.onLeg = 0
+		if v.leg == len(route)-1 {
+			if !v.spec.Loop {
+				v.leg--
+				v.onLeg = legLen
+				v.park()
+				return
+			}
+			v.leg = 0
+		}
+		if st, ok := v.stopAt(v.leg); ok {
+			v.speed = 0
+			v.stopLeft = time.Duration(st.Duration)
+			v.ignition = !st.IgnitionOff
+			return
+		}
+	}
+}
+
+func (v *vehicle) park() {
+	v.parked = true
+	v.speed = 0
+	v.ignition = false
+}
+
+func (v *vehicle) stopAt(waypoint int) (Stop, bool) {
+	for _, st := range v.spec.Stops {
+		if st.At == waypoint {
+			return st, true
+		}
+	}
+	return Stop{}, false
+}
+
+func (v *vehicle) status(now time.Time) model.Status {
+	a, b := v.spec.Route[v.leg], v.spec.Route[v.leg+1]
+	frac := 0.0
+	if l := haversine(a, b); l > 0 {
+		frac = v.onLeg / l
+	}
+	lon := a[0] + (b[0]-a[0])*frac
+	lat := a[1] + (b[1]-a[1])*frac
+
+	if n := v.spec.GPSNoise; n > 0 {
+		lat += v.rng.NormFloat64() * n / earthRadius * 180 / math.Pi
+		lon += v.rng.NormFloat64() * n / (earthRadius * math.Cos(lat*math.Pi/180)) * 180 / math.Pi
+	}
+
+	heading := bearing(a, b)
+	hdop := 0.7 + v.rng.Float64()*0.8
+	sats := 7 + v.rng.Intn(6)
+	odometer := math.Round(v.odometer*1000) / 1000
+	ignition := v.ignition
+	voltage := 12.4
+	if ignition {
+		voltage = 13.6 + v.rng.Float64()*0.6
+	}
+	return model.Status{
+		Location:   [2]float64{lon, lat},
+		Speed:      math.Round(v.speed*10) / 10,
+		Timestamp:  now,
+		Heading:    &heading,
+		HDOP:       &hdop,
+		Satellites: &sats,
+		Odometer:   &odometer,
+		Ignition:   &ignition,
+		ExtVoltage: &voltage,
+	}
+}
+
+// haversine returns the great-circle distance between [long, lat] points in metres.
+func haversine(a, b [2]float64) float64 {
+	la1, la2 := a[1]*math.Pi/180, b[1]*math.Pi/180
+	dLat := la2 - la1
+	dLon := (b[0] - a[0]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(la1)*math.Cos(la2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// bearing returns the initial course from a to b in degrees [0, 360).
+func bearing(a, b [2]float64) float64 {
+	la1, la2 := a[1]*math.Pi/180, b[1]*math.Pi/180
+	dLon := (b[0] - a[0]) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(la2)
+	x := math.Cos(la1)*math.Sin(la2) - math.Sin(la1)*math.Cos(la2)*math.Cos(dLon)
+	deg := math.Round(math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)*10) / 10
+	if deg >= 360 {
+		deg = 0
+	}
+	return deg
+}
+
+func uuidFrom(r *rand.Rand) uuid.UUID {
+	var id uuid.UUID
+	r.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return id
+}
@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testScenario() Scenario {
+	return Scenario{
+		Seed:     7,
+		Interval: Duration(time.Second),
+		Vehicles: []VehicleSpec{{
+			Plate:    "SIM-%d",
+			Count:    2,
+			Route:    [][2]float64{{55.27, 25.19}, {55.28, 25.19}, {55.28, 25.20}},
+			Speed:    SpeedProfile{Cruise: 72, Min: 60, Max: 80, Accel: 100},
+			Stops:    []Stop{{At: 1, Duration: Duration(5 * time.Second), IgnitionOff: true}},
+			GPSNoise: 3,
+		}},
+	}
+}
+
+func TestSimulator_Deterministic(t *testing.T) {
+	a, err := New(testScenario())
+	require.NoError(t, err)
+	b, err := New(testScenario())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 26, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 120; i++ {
+		ts := now.Add(time.Duration(i) * time.Second)
+		assert.Equal(t, a.Step(ts, time.Second), b.Step(ts, time.Second))
+	}
+
+	other := testScenario()
+	other.Seed = 8
+	c, err := New(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, a.vehicles[0].id, c.vehicles[0].id)
+}
+
+func TestSimulator_StopsAndParking(t *testing.T) {
+	s, err := New(testScenario())
+	require.NoError(t, err)
+	assert.Equal(t, "SIM-1", s.vehicles[0].plate)
+	assert.Equal(t, "SIM-2", s.vehicles[1].plate)
+
+	var sawStop, sawParked bool
+	now := time.Now().UTC()
+	for i := 0; i < 300; i++ {
+		p := s.Step(now.Add(time.Duration(i)*time.Second), time.Second)[0]
+		require.NoError(t, p.Status.Validate())
+		v := s.vehicles[0]
+		if v.stopLeft > 0 {
+			sawStop = true
+			assert.Zero(t, p.Status.Speed)
+			assert.False(t, *p.Status.Ignition)
+		}
+		if v.parked {
+			sawParked = true
+			assert.Zero(t, p.Status.Speed)
+			assert.InDelta(t, 25.20, p.Status.Location[1], 0.001)
+		}
+	}
+	assert.True(t, sawStop, "vehicle never stopped at waypoint 1")
+	assert.True(t, sawParked, "vehicle never reached the end of its route")
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"seed": 1, "interval": "500ms",
+		"vehicles": [{"route": [[55.1, 25.1], [55.2, 25.2]], "stops": [{"at": 1, "duration": "1m"}]}]
+	}`), 0o644))
+
+	sc, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Duration(500*time.Millisecond), sc.Interval)
+	assert.Equal(t, 1, sc.Vehicles[0].Count)
+	assert.Equal(t, 50.0, sc.Vehicles[0].Speed.Cruise)
+
+	for _, bad := range []string{
+		`{"vehicles": [{"route": [[55.1, 25.1]]}]}`,
+		`{"vehicles": [{"route": [[55.1, 25.1], [55.1, 25.1], [55.1, 25.1]], "loop": true}]}`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(bad), 0o644))
+		_, err = Load(path)
+		assert.Error(t, err, bad)
+	}
+}
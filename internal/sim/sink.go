@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/stream"
+)
+
+// Sink receives generated payloads.
+type Sink interface {
+	Send(ctx context.Context, p stream.Payload) error
+}
+
+// ChannelSink writes into the in-process stream channel.
+type ChannelSink chan<- stream.Payload
+
+func (s ChannelSink) Send(ctx context.Context, p stream.Payload) error {
+	select {
+	case s <- p:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HTTPSink posts each payload to the ingest endpoint. Header is sent as-is,
+// e.g. Authorization or X-Device-Key.
+type HTTPSink struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+func NewHTTPSink(url string, header http.Header) *HTTPSink {
+	return &HTTPSink{URL: url, Header: header, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSink) Send(ctx context.Context, p stream.Payload) error {
+	body, err := json.Marshal(model.InputRequestPayload{
+		VehicleID:   p.VehicleID,
+		PlateNumber: p.Plate,
+		Status:      p.Status,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range s.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ingest: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// WriterSink prints one ingest payload per line, handy for dry runs and for
+// diffing two runs with the same seed.
+type WriterSink struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (s *WriterSink) Send(_ context.Context, p stream.Payload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.W).Encode(model.InputRequestPayload{
+		VehicleID:   p.VehicleID,
+		PlateNumber: p.Plate,
+		Status:      p.Status,
+	})
+}
@@ -2,35 +2,56 @@ package stream
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+
 	"github.com/aditi2420/fleet-tracker/internal/model"
 	"github.com/aditi2420/fleet-tracker/internal/service"
 	"github.com/google/uuid"
-	"log/slog"
 )
 
-// Consumer calls service layer internally
-func Consumer(ctx context.Context, in <-chan Payload, svc service.VehicleService) {
+// Consume feeds the pool from a channel until ctx is cancelled or in is closed.
+func (p *Pool) Consume(ctx context.Context, in <-chan Payload) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case payload := <-in:
-			if err := svc.Ingest(ctx, payload.VehicleID, payload.Plate, payload.Status); err != nil {
-				slog.Error("ingest api failed for vehicle id", "vehicle", payload.VehicleID, "err", err)
-				continue
+		case payload, ok := <-in:
+			if !ok {
+				return
+			}
+			if err := p.Submit(ctx, payload); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				slog.Warn("stream payload not queued", "vehicle", payload.VehicleID, "err", err)
 			}
-
-			slog.Info("successfully ingested vehicleID",
-				slog.String("vehicle_id", payload.VehicleID.String()),
-				slog.Float64("lat", payload.Status.Location[1]),
-				slog.Float64("long", payload.Status.Location[0]),
-			)
 		}
 	}
 }
 
+// ingest calls service layer internally
+func ingest(ctx context.Context, svc service.VehicleService, payload Payload) error {
+	if err := svc.Ingest(ctx, model.InputRequestPayload{
+		VehicleID:   payload.VehicleID,
+		PlateNumber: payload.Plate,
+		Status:      payload.Status,
+	}); err != nil {
+		slog.Error("ingest api failed for vehicle id", "vehicle", payload.VehicleID, "err", err)
+		return err
+	}
+
+	slog.Info("successfully ingested vehicleID",
+		slog.String("vehicle_id", payload.VehicleID.String()),
+		slog.Float64("lat", payload.Status.Location[1]),
+		slog.Float64("long", payload.Status.Location[0]),
+	)
+	return nil
+}
+
 // Payload holds the channel input/output.
 type Payload struct {
+	Tenant    uuid.UUID // zero: default tenant
 	VehicleID uuid.UUID
 	Plate     string
 	model.Status
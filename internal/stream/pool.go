@@ -0,0 +1,264 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/aditi2420/fleet-tracker/internal/tenant"
+)
+
+// Policy decides what Submit does when a shard's queue is full.
+type Policy string
+
+const (
+	PolicyBlock Policy = "block" // wait for room (backpressure to the producer)
+	PolicyDrop  Policy = "drop"  // reject with ErrQueueFull
+	PolicySpill Policy = "spill" // append to an on-disk overflow file
+)
+
+var (
+	ErrQueueFull  = errors.New("stream: queue full, payload dropped")
+	ErrPoolClosed = errors.New("stream: pool is draining")
+)
+
+var (
+	queueDepth   = expvar.NewInt("stream_queue_depth")
+	spillDepth   = expvar.NewInt("stream_spill_depth")
+	processed    = expvar.NewInt("stream_processed")
+	failed       = expvar.NewInt("stream_failed")
+	dropped      = expvar.NewInt("stream_dropped")
+	ingestLagMs  = expvar.NewInt("stream_lag_ms") // fix timestamp -> ingested, last seen
+	maxIngestLag = expvar.NewInt("stream_lag_max_ms")
+)
+
+// PoolConfig sizes the pool. QueueSize is per worker.
+type PoolConfig struct {
+	Workers   int
+	QueueSize int
+	Policy    Policy
+	SpillDir  string // required for PolicySpill
+}
+
+// Pool ingests payloads on a fixed set of workers. Payloads are sharded by
+// vehicle ID so fixes of one vehicle are always ingested in arrival order.
+type Pool struct {
+	cfg    PoolConfig
+	svc    service.VehicleService
+	shards []*shard
+	wg     sync.WaitGroup
+}
+
+func NewPool(cfg PoolConfig, svc service.VehicleService) (*Pool, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1
+	}
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = PolicyBlock
+	case PolicyBlock, PolicyDrop:
+	case PolicySpill:
+		if cfg.SpillDir == "" {
+			return nil, errors.New("stream: spill policy needs a spill directory")
+		}
+		if err := os.MkdirAll(cfg.SpillDir, 0o755); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("stream: unknown policy %q", cfg.Policy)
+	}
+
+	p := &Pool{cfg: cfg, svc: svc, shards: make([]*shard, cfg.Workers)}
+	for i := range p.shards {
+		sh := newShard(cfg.QueueSize)
+		if cfg.Policy == PolicySpill {
+			sp, err := openSpill(filepath.Join(cfg.SpillDir, fmt.Sprintf("shard-%d.jsonl", i)))
+			if err != nil {
+				return nil, err
+			}
+			sh.spill = sp
+			spillDepth.Add(int64(sp.pending)) // left over from an earlier run
+		}
+		p.shards[i] = sh
+	}
+	return p, nil
+}
+
+// Start launches the workers. Ingest runs on its own context so that
+// payloads accepted before shutdown can still be written while draining.
+func (p *Pool) Start() {
+	for _, sh := range p.shards {
+		p.wg.Add(1)
+		go func(sh *shard) {
+			defer p.wg.Done()
+			for {
+				payload, ok := sh.pop()
+				if !ok {
+					return
+				}
+				p.ingest(payload)
+			}
+		}(sh)
+	}
+}
+
+// Submit queues one payload on its vehicle's shard according to the policy.
+func (p *Pool) Submit(ctx context.Context, payload Payload) error {
+	return p.shardFor(payload).push(ctx, payload, p.cfg.Policy)
+}
+
+// Drain stops accepting payloads and waits until the queued ones (including
+// spilled ones) are ingested or ctx expires.
+func (p *Pool) Drain(ctx context.Context) error {
+	for _, sh := range p.shards {
+		sh.close()
+	}
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		slog.Warn("stream drain timed out", "queued", queueDepth.Value(), "spilled", spillDepth.Value())
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) shardFor(payload Payload) *shard {
+	h := fnv.New32a()
+	h.Write(payload.VehicleID[:])
+	return p.shards[h.Sum32()%uint32(len(p.shards))]
+}
+
+func (p *Pool) ingest(payload Payload) {
+	if err := ingest(tenant.WithID(context.Background(), payload.Tenant), p.svc, payload); err != nil {
+		failed.Add(1)
+		return
+	}
+	processed.Add(1)
+	if !payload.Timestamp.IsZero() {
+		lag := time.Since(payload.Timestamp).Milliseconds()
+		ingestLagMs.Set(lag)
+		if lag > maxIngestLag.Value() {
+			maxIngestLag.Set(lag)
+		}
+	}
+}
+
+// shard is one worker's FIFO. Once anything has spilled, new payloads go to
+// the spill file too until it is drained, which keeps per-vehicle order.
+type shard struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	buf      []Payload
+	size     int
+	spill    *spill
+	closed   bool
+}
+
+func newShard(size int) *shard {
+	s := &shard{size: size, buf: make([]Payload, 0, size)}
+	s.notEmpty = sync.NewCond(&s.mu)
+	s.notFull = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *shard) push(ctx context.Context, payload Payload, policy Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if policy == PolicyBlock {
+		stop := context.AfterFunc(ctx, func() {
+			s.mu.Lock()
+			s.notFull.Broadcast()
+			s.mu.Unlock()
+		})
+		defer stop()
+		for len(s.buf) >= s.size && !s.closed && ctx.Err() == nil {
+			s.notFull.Wait()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if s.closed {
+		return ErrPoolClosed
+	}
+
+	switch {
+	case s.spill != nil && s.spill.pending > 0, len(s.buf) >= s.size && policy == PolicySpill:
+		if err := s.spill.write(payload); err != nil {
+			return err
+		}
+		spillDepth.Add(1)
+	case len(s.buf) < s.size:
+		s.buf = append(s.buf, payload)
+		queueDepth.Add(1)
+	default:
+		dropped.Add(1)
+		return ErrQueueFull
+	}
+	s.notEmpty.Signal()
+	return nil
+}
+
+// pop blocks until a payload is available; ok is false once the shard is
+// closed and empty.
+func (s *shard) pop() (Payload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.buf) == 0 {
+		for len(s.buf) == 0 && !s.hasSpilled() && !s.closed {
+			s.notEmpty.Wait()
+		}
+		if !s.hasSpilled() {
+			break
+		}
+		before := s.spill.pending
+		refill, err := s.spill.read(s.size)
+		if err != nil {
+			slog.Error("stream spill unreadable, discarding", "err", err)
+		}
+		if len(refill) == 0 || s.spill.pending == 0 {
+			s.spill.discard() // fully consumed, or the file no longer matches our count
+		}
+		spillDepth.Add(-int64(before - s.spill.pending))
+		s.buf = append(s.buf, refill...)
+		queueDepth.Add(int64(len(refill)))
+	}
+	if len(s.buf) == 0 {
+		return Payload{}, false
+	}
+
+	payload := s.buf[0]
+	s.buf = s.buf[1:]
+	queueDepth.Add(-1)
+	s.notFull.Signal()
+	return payload, true
+}
+
+func (s *shard) hasSpilled() bool { return s.spill != nil && s.spill.pending > 0 }
+
+func (s *shard) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+}
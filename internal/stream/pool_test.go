@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aditi2420/fleet-tracker/internal/model"
+	"github.com/aditi2420/fleet-tracker/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingService remembers the speed of every ingested fix per vehicle;
+// tests use speed as a sequence number. gate, when set, holds every Ingest.
+type recordingService struct {
+	service.VehicleService
+	mu   sync.Mutex
+	seen map[uuid.UUID][]float64
+	gate chan struct{}
+}
+
+func (s *recordingService) Ingest(_ context.Context, p model.InputRequestPayload) error {
+	if s.gate != nil {
+		<-s.gate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen[p.VehicleID] = append(s.seen[p.VehicleID], p.Status.Speed)
+	return nil
+}
+
+func (s *recordingService) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, v := range s.seen {
+		n += len(v)
+	}
+	return n
+}
+
+func payload(id uuid.UUID, seq int) Payload {
+	return Payload{VehicleID: id, Status: model.Status{Speed: float64(seq), Timestamp: time.Now()}}
+}
+
+func TestPool_PerVehicleOrdering(t *testing.T) {
+	for _, policy := range []Policy{PolicyBlock, PolicySpill} {
+		t.Run(string(policy), func(t *testing.T) {
+			svc := &recordingService{seen: map[uuid.UUID][]float64{}}
+			pool, err := NewPool(PoolConfig{Workers: 4, QueueSize: 2, Policy: policy, SpillDir: t.TempDir()}, svc)
+			require.NoError(t, err)
+			pool.Start()
+
+			vehicles := make([]uuid.UUID, 10)
+			for i := range vehicles {
+				vehicles[i] = uuid.New()
+			}
+			const perVehicle = 50
+			for seq := 0; seq < perVehicle; seq++ {
+				for _, id := range vehicles {
+					require.NoError(t, pool.Submit(context.Background(), payload(id, seq)))
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			require.NoError(t, pool.Drain(ctx))
+
+			for _, id := range vehicles {
+				got := svc.seen[id]
+				require.Len(t, got, perVehicle)
+				for i, v := range got {
+					assert.Equal(t, float64(i), v, "vehicle %s out of order", id)
+				}
+			}
+		})
+	}
+}
+
+func TestPool_DropPolicy(t *testing.T) {
+	svc := &recordingService{seen: map[uuid.UUID][]float64{}, gate: make(chan struct{})}
+	pool, err := NewPool(PoolConfig{Workers: 1, QueueSize: 2, Policy: PolicyDrop}, svc)
+	require.NoError(t, err)
+	pool.Start()
+
+	id := uuid.New()
+	// one payload is held by the worker, two fill the queue, the rest drop
+	var accepted, rejected int
+	for seq := 0; seq < 10; seq++ {
+		err := pool.Submit(context.Background(), payload(id, seq))
+		if err == nil {
+			accepted++
+		} else {
+			assert.ErrorIs(t, err, ErrQueueFull)
+			rejected++
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, rejected, 7)
+	close(svc.gate)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, pool.Drain(ctx))
+	assert.Equal(t, accepted, svc.total())
+}
+
+func TestPool_BlockPolicyHonoursContext(t *testing.T) {
+	svc := &recordingService{seen: map[uuid.UUID][]float64{}, gate: make(chan struct{})}
+	pool, err := NewPool(PoolConfig{Workers: 1, QueueSize: 1, Policy: PolicyBlock}, svc)
+	require.NoError(t, err)
+	pool.Start()
+
+	id := uuid.New()
+	require.NoError(t, pool.Submit(context.Background(), payload(id, 0))) // taken by the worker
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, pool.Submit(context.Background(), payload(id, 1))) // fills the queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(ctx, payload(id, 2)), context.DeadlineExceeded)
+
+	close(svc.gate)
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer drainCancel()
+	require.NoError(t, pool.Drain(drainCtx))
+	assert.Equal(t, []float64{0, 1}, svc.seen[id])
+
+	assert.ErrorIs(t, pool.Submit(context.Background(), payload(id, 3)), ErrPoolClosed)
+}
+
+func TestPool_SpillSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	id := uuid.New()
+
+	// first run: never started, so everything past the queue spills to disk
+	first, err := NewPool(PoolConfig{Workers: 1, QueueSize: 2, Policy: PolicySpill, SpillDir: dir}, &recordingService{})
+	require.NoError(t, err)
+	for seq := 0; seq < 5; seq++ {
+		require.NoError(t, first.Submit(context.Background(), payload(id, seq)))
+	}
+
+	// second run picks the spilled payloads up
+	svc := &recordingService{seen: map[uuid.UUID][]float64{}}
+	second, err := NewPool(PoolConfig{Workers: 1, QueueSize: 2, Policy: PolicySpill, SpillDir: dir}, svc)
+	require.NoError(t, err)
+	second.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, second.Drain(ctx))
+	assert.Equal(t, []float64{2, 3, 4}, svc.seen[id])
+}
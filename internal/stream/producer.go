@@ -22,10 +22,14 @@ func Produce(ctx context.Context, out chan<- Payload, id uuid.UUID) {
 		case t := <-ticker.C:
 			slog.Info("Producing payload",
 				slog.String("vehicle_id", id.String()))
-			out <- Payload{ //writing into the channel:out
+			select {
+			case out <- Payload{ //writing into the channel:out
 				VehicleID: id,
 				Plate:     id.String(),
 				Status:    getMockedStatus(t),
+			}:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}
@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+// spill is an append-only JSON-lines overflow file read back in FIFO order.
+// It is only touched with the owning shard's lock held.
+type spill struct {
+	path    string
+	w       *os.File
+	r       *os.File
+	rd      *bufio.Reader
+	pending int
+}
+
+// openSpill picks up payloads left behind by a previous run.
+func openSpill(path string) (*spill, error) {
+	s := &spill{path: path}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		s.pending++
+	}
+	return s, sc.Err()
+}
+
+func (s *spill) write(p Payload) error {
+	if s.w == nil {
+		w, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		s.w = w
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	s.pending++
+	return nil
+}
+
+// read returns up to n of the oldest spilled payloads.
+func (s *spill) read(n int) ([]Payload, error) {
+	if s.r == nil {
+		r, err := os.Open(s.path)
+		if err != nil {
+			return nil, err
+		}
+		s.r, s.rd = r, bufio.NewReader(r)
+	}
+	var res []Payload
+	for len(res) < n && s.pending > 0 {
+		line, err := s.rd.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return res, err
+		}
+		s.pending--
+		var p Payload
+		if err := json.Unmarshal(line, &p); err != nil {
+			continue // torn write from a crash
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// discard closes and removes the file and forgets anything still pending.
+func (s *spill) discard() {
+	if s.w != nil {
+		s.w.Close()
+	}
+	if s.r != nil {
+		s.r.Close()
+	}
+	_ = os.Remove(s.path)
+	s.w, s.r, s.rd, s.pending = nil, nil, nil, 0
+}
@@ -0,0 +1,63 @@
+// Package tenant carries the caller's organisation through context.Context so
+// the repository layer can scope every query to it.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Default owns data written before tenants existed and is the tenant of
+// tokens without a tenant claim.
+var Default = uuid.Nil
+
+type ctxKey struct{}
+
+// WithID returns a context scoped to tenant id.
+func WithID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the tenant the context is scoped to. ok is false for
+// system contexts (workers, migrations, CLIs), which are not scoped at all.
+func FromContext(ctx context.Context) (id uuid.UUID, ok bool) {
+	id, ok = ctx.Value(ctxKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// Parse reads a tenant claim; the empty string is the default tenant.
+func Parse(s string) (uuid.UUID, error) {
+	if s == "" {
+		return Default, nil
+	}
+	return uuid.Parse(s)
+}
+
+type recorderKey struct{}
+
+// Recorder remembers the tenant a call turned out to belong to. Calls that
+// start unscoped and learn their tenant from a token (refresh, password
+// reset) report it with Record so middleware around them can use it.
+type Recorder struct {
+	id uuid.UUID
+	ok bool
+}
+
+// WithRecorder returns a context whose calls report to the returned Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// ID is the tenant last recorded; ok is false if none was.
+func (r *Recorder) ID() (id uuid.UUID, ok bool) {
+	return r.id, r.ok
+}
+
+// Record reports id to the Recorder of ctx, if it has one.
+func Record(ctx context.Context, id uuid.UUID) {
+	if r, _ := ctx.Value(recorderKey{}).(*Recorder); r != nil {
+		r.id, r.ok = id, true
+	}
+}
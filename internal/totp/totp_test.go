@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 appendix B, SHA1 rows, truncated to 6 digits.
+func TestCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(secret, Step(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "t=%d", tt.unix)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 1, 12, 0, 10, 0, time.UTC)
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+
+	step, ok := Verify(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok = Verify(secret, code, now.Add(Period))
+	assert.True(t, ok, "one step of drift")
+	_, ok = Verify(secret, code, now.Add(3*Period))
+	assert.False(t, ok, "too old")
+	_, ok = Verify(secret, "12345", now)
+	assert.False(t, ok, "wrong length")
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Fleet Tracker", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "otpauth://totp/Fleet%20Tracker:alice@example.com?")
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+}
@@ -0,0 +1,64 @@
+package vin
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"strings"
+)
+
+//go:embed wmi.csv
+var wmiCSV []byte
+
+//go:embed plants.csv
+var plantsCSV []byte
+
+type maker struct {
+	manufacturer, country string
+}
+
+type plantKey struct {
+	manufacturer string
+	code         byte
+}
+
+var (
+	wmis   = map[string]maker{}
+	plants = map[plantKey]string{}
+)
+
+func init() {
+	for _, f := range records(wmiCSV, 3) {
+		wmis[f[0]] = maker{f[1], f[2]}
+	}
+	for _, f := range records(plantsCSV, 3) {
+		plants[plantKey{f[0], f[1][0]}] = f[2]
+	}
+}
+
+// lookupWMI tries the full three-character WMI, then the two-character prefix.
+func lookupWMI(v string) (maker, bool) {
+	if m, ok := wmis[v[:3]]; ok {
+		return m, true
+	}
+	m, ok := wmis[v[:2]]
+	return m, ok
+}
+
+// records splits a small comment-aware CSV; the tables never quote fields.
+func records(b []byte, n int) [][]string {
+	var res [][]string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.SplitN(line, ",", n)
+		if len(f) != n || f[1] == "" {
+			panic("vin: malformed table line " + line)
+		}
+		res = append(res, f)
+	}
+	return res
+}
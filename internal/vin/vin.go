@@ -0,0 +1,158 @@
+// Package vin validates vehicle identification numbers (ISO 3779 / FMVSS 115)
+// and decodes manufacturer, model year and plant from an embedded table, so
+// nothing leaves the process.
+package vin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is wrapped by every validation failure.
+var ErrInvalid = errors.New("invalid vin")
+
+const Length = 17
+
+// Info is what can be read from a VIN without asking the manufacturer.
+type Info struct {
+	VIN          string `json:"vin"`
+	WMI          string `json:"wmi"`
+	Region       string `json:"region"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Country      string `json:"country,omitempty"`
+	ModelYear    int    `json:"model_year,omitempty"`
+	PlantCode    string `json:"plant_code"`
+	Plant        string `json:"plant,omitempty"`
+	Serial       string `json:"serial"`
+
+	// CheckDigitOK is false when position 9 doesn't match. That is only an
+	// error for North American VINs, where the check digit is mandatory.
+	CheckDigitOK bool `json:"check_digit_ok"`
+}
+
+// Normalize upper-cases and trims a VIN.
+func Normalize(v string) string {
+	return strings.ToUpper(strings.TrimSpace(v))
+}
+
+// Validate checks length and alphabet (no I, O or Q), plus the check digit
+// for North American VINs.
+func Validate(v string) error {
+	if len(v) != Length {
+		return fmt.Errorf("%w: must be %d characters", ErrInvalid, Length)
+	}
+	for i := 0; i < Length; i++ {
+		if _, ok := transliterate(v[i]); !ok {
+			return fmt.Errorf("%w: character %q at position %d not allowed", ErrInvalid, v[i], i+1)
+		}
+	}
+	if northAmerican(v) && CheckDigit(v) != v[8] {
+		return fmt.Errorf("%w: check digit %c, expected %c", ErrInvalid, v[8], CheckDigit(v))
+	}
+	return nil
+}
+
+// CheckDigit computes position 9 of v, which must already be 17 valid characters.
+func CheckDigit(v string) byte {
+	sum := 0
+	for i := 0; i < Length; i++ {
+		n, _ := transliterate(v[i])
+		sum += n * weights[i]
+	}
+	if r := sum % 11; r != 10 {
+		return byte('0' + r)
+	}
+	return 'X'
+}
+
+// Decode validates v and reads what the embedded tables know about it.
+func Decode(v string) (Info, error) {
+	v = Normalize(v)
+	if err := Validate(v); err != nil {
+		return Info{}, err
+	}
+	info := Info{
+		VIN:          v,
+		WMI:          v[:3],
+		Region:       region(v[0]),
+		PlantCode:    v[10:11],
+		Serial:       v[11:],
+		CheckDigitOK: CheckDigit(v) == v[8],
+	}
+	if m, ok := lookupWMI(v); ok {
+		info.Manufacturer, info.Country = m.manufacturer, m.country
+		info.Plant = plants[plantKey{m.manufacturer, v[10]}]
+	}
+	info.ModelYear = modelYear(v, time.Now().Year()+1)
+	return info, nil
+}
+
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// transliterate maps a VIN character to its check-digit value; I, O and Q
+// are not part of the alphabet.
+func transliterate(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1, true
+	case c == 'P':
+		return 7, true
+	case c == 'R':
+		return 9, true
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true
+	}
+	return 0, false
+}
+
+// northAmerican covers WMIs assigned to the US, Canada and Mexico.
+func northAmerican(v string) bool {
+	return v[0] >= '1' && v[0] <= '5'
+}
+
+func region(c byte) string {
+	switch {
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	default:
+		return "South America"
+	}
+}
+
+// yearCodes repeats every 30 years starting with A = 1980.
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// modelYear decodes position 10. North American VINs disambiguate the cycle
+// with position 7 (a letter means 2010 onwards); elsewhere the latest year
+// not after maxYear wins. 0 means the code is not a year code.
+func modelYear(v string, maxYear int) int {
+	idx := strings.IndexByte(yearCodes, v[9])
+	if idx < 0 {
+		return 0
+	}
+	if northAmerican(v) {
+		if v[6] >= 'A' && v[6] <= 'Z' {
+			return 2010 + idx
+		}
+		return 1980 + idx
+	}
+	year := 1980 + idx
+	for year+30 <= maxYear {
+		year += 30
+	}
+	return year
+}
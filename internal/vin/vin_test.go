@@ -0,0 +1,118 @@
+package vin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		wantErr bool
+	}{
+		{name: "positive - honda accord", vin: "1HGCM82633A004352"},
+		{name: "positive - check digit X", vin: "1M8GDM9AXKP042788"},
+		{name: "positive - all ones", vin: "11111111111111111"},
+		{name: "positive - european vin, check digit not enforced", vin: "WVWZZZ1JZ3W386752"},
+		{name: "negative - too short", vin: "1HGCM82633A00435", wantErr: true},
+		{name: "negative - too long", vin: "1HGCM82633A0043521", wantErr: true},
+		{name: "negative - letter I", vin: "1HGCM82633AI04352", wantErr: true},
+		{name: "negative - letter O", vin: "1HGCM82633AO04352", wantErr: true},
+		{name: "negative - letter Q", vin: "1HGCM82633AQ04352", wantErr: true},
+		{name: "negative - lower case", vin: "1hgcm82633a004352", wantErr: true},
+		{name: "negative - north american check digit", vin: "1HGCM82643A004352", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.vin)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalid)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		vin  string
+		want Info
+	}{
+		{
+			name: "honda, plant known",
+			vin:  " 1hgcm82633a004352 ",
+			want: Info{
+				VIN: "1HGCM82633A004352", WMI: "1HG", Region: "North America",
+				Manufacturer: "Honda", Country: "United States", ModelYear: 2003,
+				PlantCode: "A", Plant: "Marysville OH", Serial: "004352", CheckDigitOK: true,
+			},
+		},
+		{
+			name: "north american 1980s cycle from position 7",
+			vin:  "1M8GDM9AXKP042788",
+			want: Info{
+				VIN: "1M8GDM9AXKP042788", WMI: "1M8", Region: "North America",
+				Manufacturer: "Motor Coach Industries", Country: "United States", ModelYear: 1989,
+				PlantCode: "P", Plant: "Pembina ND", Serial: "042788", CheckDigitOK: true,
+			},
+		},
+		{
+			name: "european, bad check digit tolerated",
+			vin:  "WVWZZZ1JZ3W386752",
+			want: Info{
+				VIN: "WVWZZZ1JZ3W386752", WMI: "WVW", Region: "Europe",
+				Manufacturer: "Volkswagen", Country: "Germany", ModelYear: 2003,
+				PlantCode: "W", Plant: "Wolfsburg Germany", Serial: "386752", CheckDigitOK: false,
+			},
+		},
+		{
+			name: "two-character wmi prefix, unknown plant",
+			vin:  "JTNBB46K573012345",
+			want: Info{
+				VIN: "JTNBB46K573012345", WMI: "JTN", Region: "Asia",
+				Manufacturer: "Toyota", Country: "Japan", ModelYear: 2007,
+				PlantCode: "3", Serial: "012345", CheckDigitOK: true,
+			},
+		},
+		{
+			name: "unknown manufacturer",
+			vin:  "9BWZZZ3777T004251",
+			want: Info{
+				VIN: "9BWZZZ3777T004251", WMI: "9BW", Region: "South America",
+				ModelYear: 2007, PlantCode: "T", Serial: "004251", CheckDigitOK: false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.vin)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestModelYear(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		maxYear int
+		want    int
+	}{
+		{name: "north american, letter in position 7", vin: "5YJ3E1EA2KF317000", maxYear: 2027, want: 2019},
+		{name: "north american, digit in position 7", vin: "1HGCM82633A004352", maxYear: 2027, want: 2003},
+		{name: "rest of world picks latest cycle", vin: "WVWZZZ1JZEW386752", maxYear: 2027, want: 2014},
+		{name: "rest of world never in the future", vin: "WVWZZZ1JZYW386752", maxYear: 2027, want: 2000},
+		{name: "not a year code", vin: "WVWZZZ1JZZW386752", maxYear: 2027, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, modelYear(tt.vin, tt.maxYear))
+		})
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_positions_vehicle_time;
+DROP TABLE IF EXISTS positions;
@@ -0,0 +1,19 @@
+CREATE TABLE positions (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vehicle_id  UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    timestamp   TIMESTAMPTZ NOT NULL,
+    longitude   DOUBLE PRECISION NOT NULL,
+    latitude    DOUBLE PRECISION NOT NULL,
+    speed       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    heading     DOUBLE PRECISION,
+    altitude    DOUBLE PRECISION,
+    hdop        DOUBLE PRECISION,
+    satellites  INTEGER,
+    odometer    DOUBLE PRECISION,
+    ignition    BOOLEAN,
+    ext_voltage DOUBLE PRECISION,
+    attributes  JSONB
+);
+
+CREATE INDEX idx_positions_vehicle_time
+          ON positions (vehicle_id, timestamp DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_events_vehicle_time;
+DROP TABLE IF EXISTS vehicle_events;
+DROP TABLE IF EXISTS active_dtcs;
+DROP INDEX IF EXISTS idx_signal_vehicle_name_time;
+DROP TABLE IF EXISTS signal_samples;
@@ -0,0 +1,31 @@
+CREATE TABLE signal_samples (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    timestamp  TIMESTAMPTZ NOT NULL,
+    value      DOUBLE PRECISION NOT NULL,
+    unit       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_signal_vehicle_name_time
+          ON signal_samples (vehicle_id, name, timestamp DESC);
+
+CREATE TABLE active_dtcs (
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    code       TEXT NOT NULL,
+    first_seen TIMESTAMPTZ NOT NULL,
+    last_seen  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (vehicle_id, code)
+);
+
+CREATE TABLE vehicle_events (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    type       TEXT NOT NULL,
+    code       TEXT,
+    details    JSONB,
+    timestamp  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_events_vehicle_time
+          ON vehicle_events (vehicle_id, timestamp DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_device_credentials_vehicle;
+DROP TABLE IF EXISTS device_credentials;
@@ -0,0 +1,13 @@
+CREATE TABLE device_credentials (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key_id      TEXT UNIQUE NOT NULL,
+    vehicle_id  UUID NOT NULL,
+    kind        TEXT NOT NULL CHECK (kind IN ('api_key', 'hmac')),
+    secret_hash TEXT NOT NULL,
+    label       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_device_credentials_vehicle
+          ON device_credentials (vehicle_id);
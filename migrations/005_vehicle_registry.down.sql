@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_vehicle_tags;
+
+ALTER TABLE vehicle
+    DROP COLUMN IF EXISTS vin,
+    DROP COLUMN IF EXISTS make,
+    DROP COLUMN IF EXISTS model,
+    DROP COLUMN IF EXISTS year,
+    DROP COLUMN IF EXISTS fuel_type,
+    DROP COLUMN IF EXISTS capacity_kg,
+    DROP COLUMN IF EXISTS tank_liters,
+    DROP COLUMN IF EXISTS odometer_base_km,
+    DROP COLUMN IF EXISTS attributes,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
+
+DELETE FROM vehicle WHERE last_status IS NULL;
+ALTER TABLE vehicle ALTER COLUMN last_status SET NOT NULL;
@@ -0,0 +1,19 @@
+-- vehicles can now be registered before they ever report
+ALTER TABLE vehicle ALTER COLUMN last_status DROP NOT NULL;
+
+ALTER TABLE vehicle
+    ADD COLUMN vin              TEXT UNIQUE,
+    ADD COLUMN make             TEXT NOT NULL DEFAULT '',
+    ADD COLUMN model            TEXT NOT NULL DEFAULT '',
+    ADD COLUMN year             INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN fuel_type        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN capacity_kg      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN tank_liters      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN odometer_base_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN attributes       JSONB,
+    ADD COLUMN tags             JSONB,
+    ADD COLUMN archived_at      TIMESTAMPTZ,
+    ADD COLUMN created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at       TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_vehicle_tags ON vehicle USING GIN (tags);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_device_credentials_device;
+DELETE FROM device_credentials WHERE device_id IS NOT NULL;
+ALTER TABLE device_credentials DROP COLUMN IF EXISTS device_id;
+
+DROP TABLE IF EXISTS device_assignments;
+DROP TABLE IF EXISTS devices;
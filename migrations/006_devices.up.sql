@@ -0,0 +1,37 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE devices (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    imei       TEXT UNIQUE,
+    serial     TEXT UNIQUE,
+    model      TEXT NOT NULL DEFAULT '',
+    firmware   TEXT NOT NULL DEFAULT '',
+    iccid      TEXT NOT NULL DEFAULT '',
+    label      TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (imei IS NOT NULL OR serial IS NOT NULL)
+);
+
+-- [valid_from, valid_to) per device never overlaps; valid_to NULL = still installed
+CREATE TABLE device_assignments (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_id  UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (valid_to IS NULL OR valid_to > valid_from),
+    EXCLUDE USING gist (device_id WITH =, tstzrange(valid_from, valid_to) WITH &&)
+);
+
+CREATE INDEX idx_device_assignments_device
+          ON device_assignments (device_id, valid_from DESC);
+CREATE INDEX idx_device_assignments_vehicle
+          ON device_assignments (vehicle_id, valid_from DESC);
+
+-- credentials may follow a device instead of a vehicle
+ALTER TABLE device_credentials
+    ADD COLUMN device_id UUID REFERENCES devices(id) ON DELETE CASCADE;
+CREATE INDEX idx_device_credentials_device
+          ON device_credentials (device_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vehicle_group_members;
+DROP TABLE IF EXISTS vehicle_groups;
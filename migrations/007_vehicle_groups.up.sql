@@ -0,0 +1,21 @@
+CREATE TABLE vehicle_groups (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    parent_id  UUID REFERENCES vehicle_groups(id),
+    name       TEXT NOT NULL,
+    -- materialised path "/<root>/.../<id>/"; subtree = path LIKE '<path>%'
+    path       TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_vehicle_groups_parent ON vehicle_groups (parent_id);
+CREATE INDEX idx_vehicle_groups_path   ON vehicle_groups (path text_pattern_ops);
+
+CREATE TABLE vehicle_group_members (
+    group_id   UUID NOT NULL REFERENCES vehicle_groups(id) ON DELETE CASCADE,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, vehicle_id)
+);
+
+CREATE INDEX idx_vehicle_group_members_vehicle ON vehicle_group_members (vehicle_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_trips_driver_time;
+ALTER TABLE trips DROP COLUMN IF EXISTS driver_id;
+
+DROP TABLE IF EXISTS driver_assignments;
+DROP TABLE IF EXISTS drivers;
@@ -0,0 +1,35 @@
+CREATE TABLE drivers (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name           TEXT NOT NULL,
+    licence_number TEXT UNIQUE,
+    licence_expiry TIMESTAMPTZ,
+    phone          TEXT NOT NULL DEFAULT '',
+    email          TEXT NOT NULL DEFAULT '',
+    tag            TEXT UNIQUE, -- iButton/RFID id, upper-case hex without separators
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- shifts: [valid_from, valid_to) never overlaps per driver nor per vehicle
+CREATE TABLE driver_assignments (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id  UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to   TIMESTAMPTZ,
+    source     TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'tag')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (valid_to IS NULL OR valid_to > valid_from),
+    EXCLUDE USING gist (driver_id WITH =, tstzrange(valid_from, valid_to) WITH &&),
+    EXCLUDE USING gist (vehicle_id WITH =, tstzrange(valid_from, valid_to) WITH &&)
+);
+
+CREATE INDEX idx_driver_assignments_driver
+          ON driver_assignments (driver_id, valid_from DESC);
+CREATE INDEX idx_driver_assignments_vehicle
+          ON driver_assignments (vehicle_id, valid_from DESC);
+
+ALTER TABLE trips
+    ADD COLUMN driver_id UUID REFERENCES drivers(id) ON DELETE SET NULL;
+CREATE INDEX idx_trips_driver_time
+          ON trips (driver_id, start_time DESC);
@@ -0,0 +1,28 @@
+DROP INDEX IF EXISTS idx_drivers_tenant_tag;
+DROP INDEX IF EXISTS idx_drivers_tenant_licence;
+ALTER TABLE drivers ADD CONSTRAINT drivers_tag_key UNIQUE (tag);
+ALTER TABLE drivers ADD CONSTRAINT drivers_licence_number_key UNIQUE (licence_number);
+
+DROP INDEX IF EXISTS idx_devices_tenant_serial;
+DROP INDEX IF EXISTS idx_devices_tenant_imei;
+ALTER TABLE devices ADD CONSTRAINT devices_serial_key UNIQUE (serial);
+ALTER TABLE devices ADD CONSTRAINT devices_imei_key UNIQUE (imei);
+
+DROP INDEX IF EXISTS idx_vehicle_tenant_vin;
+DROP INDEX IF EXISTS idx_vehicle_tenant_plate;
+ALTER TABLE vehicle ADD CONSTRAINT vehicle_vin_key UNIQUE (vin);
+ALTER TABLE vehicle ADD CONSTRAINT vehicle_plate_number_key UNIQUE (plate_number);
+
+DO $$
+DECLARE t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'vehicle', 'trips', 'positions', 'signal_samples', 'active_dtcs', 'vehicle_events',
+        'device_credentials', 'devices', 'device_assignments', 'vehicle_groups',
+        'vehicle_group_members', 'drivers', 'driver_assignments'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenant_id', t);
+    END LOOP;
+END $$;
+
+DROP TABLE IF EXISTS tenants;
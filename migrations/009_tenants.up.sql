@@ -0,0 +1,40 @@
+CREATE TABLE tenants (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- existing data and tokens without a tenant claim belong to the nil-UUID tenant
+INSERT INTO tenants (id, name) VALUES ('00000000-0000-0000-0000-000000000000', 'default');
+
+DO $$
+DECLARE t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'vehicle', 'trips', 'positions', 'signal_samples', 'active_dtcs', 'vehicle_events',
+        'device_credentials', 'devices', 'device_assignments', 'vehicle_groups',
+        'vehicle_group_members', 'drivers', 'driver_assignments'
+    ] LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ADD COLUMN tenant_id UUID NOT NULL
+                 DEFAULT ''00000000-0000-0000-0000-000000000000'' REFERENCES tenants(id)', t);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id DROP DEFAULT', t);
+        EXECUTE format('CREATE INDEX idx_%s_tenant ON %I (tenant_id)', t, t);
+    END LOOP;
+END $$;
+
+-- natural keys are unique per tenant, so one customer can't probe another's
+ALTER TABLE vehicle DROP CONSTRAINT vehicle_plate_number_key;
+ALTER TABLE vehicle DROP CONSTRAINT vehicle_vin_key;
+CREATE UNIQUE INDEX idx_vehicle_tenant_plate ON vehicle (tenant_id, plate_number);
+CREATE UNIQUE INDEX idx_vehicle_tenant_vin   ON vehicle (tenant_id, vin);
+
+ALTER TABLE devices DROP CONSTRAINT devices_imei_key;
+ALTER TABLE devices DROP CONSTRAINT devices_serial_key;
+CREATE UNIQUE INDEX idx_devices_tenant_imei   ON devices (tenant_id, imei);
+CREATE UNIQUE INDEX idx_devices_tenant_serial ON devices (tenant_id, serial);
+
+ALTER TABLE drivers DROP CONSTRAINT drivers_licence_number_key;
+ALTER TABLE drivers DROP CONSTRAINT drivers_tag_key;
+CREATE UNIQUE INDEX idx_drivers_tenant_licence ON drivers (tenant_id, licence_number);
+CREATE UNIQUE INDEX idx_drivers_tenant_tag     ON drivers (tenant_id, tag);
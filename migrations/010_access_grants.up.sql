@@ -0,0 +1,16 @@
+-- restricted subjects (JWT sub) see a vehicle, or a group subtree, during [valid_from, valid_to)
+CREATE TABLE access_grants (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id  UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES tenants(id),
+    subject    TEXT NOT NULL,
+    vehicle_id UUID REFERENCES vehicle(id) ON DELETE CASCADE,
+    group_id   UUID REFERENCES vehicle_groups(id) ON DELETE CASCADE,
+    valid_from TIMESTAMPTZ NOT NULL DEFAULT now(),
+    valid_to   TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((vehicle_id IS NULL) <> (group_id IS NULL)),
+    CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX idx_access_grants_subject
+          ON access_grants (tenant_id, subject, valid_from);
@@ -0,0 +1,18 @@
+-- one row per refresh token; rotation chains tokens of a session by family
+CREATE TABLE refresh_tokens (
+    id          UUID PRIMARY KEY,
+    tenant_id   UUID NOT NULL REFERENCES tenants(id),
+    family      UUID NOT NULL,
+    subject     TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    roles       JSONB,
+    scopes      JSONB,
+    restricted  BOOLEAN NOT NULL DEFAULT false,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_refresh_tokens_family  ON refresh_tokens (family);
+CREATE INDEX idx_refresh_tokens_subject ON refresh_tokens (tenant_id, subject);
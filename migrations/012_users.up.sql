@@ -0,0 +1,32 @@
+CREATE TABLE users (
+    id              UUID PRIMARY KEY,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id),
+    email           TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    password_hash   TEXT NOT NULL,                    -- bcrypt
+    roles           JSONB,
+    scopes          JSONB,
+    restricted      BOOLEAN NOT NULL DEFAULT false,
+    totp_secret     TEXT NOT NULL DEFAULT '',
+    totp_enabled    BOOLEAN NOT NULL DEFAULT false,
+    totp_last_step  BIGINT NOT NULL DEFAULT 0,        -- last accepted code, against replays
+    failed_logins   INT NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    last_login_at   TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_users_tenant_email ON users (tenant_id, email);
+
+CREATE TABLE password_resets (
+    id          UUID PRIMARY KEY,
+    tenant_id   UUID NOT NULL REFERENCES tenants(id),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret_hash TEXT NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_password_resets_user ON password_resets (user_id);
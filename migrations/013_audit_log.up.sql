@@ -0,0 +1,32 @@
+-- append-only, hash-chained per tenant (see model.AuditEntry)
+CREATE TABLE audit_log (
+    id          UUID PRIMARY KEY,
+    tenant_id   UUID NOT NULL REFERENCES tenants(id),
+    seq         BIGINT NOT NULL,
+    at          TIMESTAMPTZ NOT NULL,
+    actor       TEXT NOT NULL DEFAULT '',
+    action      TEXT NOT NULL,
+    resource    TEXT NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    status      INT NOT NULL,
+    diff        JSONB,
+    ip          TEXT NOT NULL DEFAULT '',
+    request_id  TEXT NOT NULL DEFAULT '',
+    prev_hash   TEXT NOT NULL,
+    hash        TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_audit_seq        ON audit_log (tenant_id, seq);
+CREATE INDEX idx_audit_log_actor         ON audit_log (tenant_id, actor, at);
+CREATE INDEX idx_audit_log_resource_id   ON audit_log (tenant_id, resource_id, at);
+
+-- the hash chain detects tampering; this stops the accidental kind
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,16 @@
+-- public follow-this-vehicle links; tokens are HMAC-signed, never stored
+CREATE TABLE share_links (
+    id          UUID PRIMARY KEY,
+    tenant_id   UUID NOT NULL REFERENCES tenants(id),
+    vehicle_id  UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    label       TEXT NOT NULL DEFAULT '',
+    valid_from  TIMESTAMPTZ NOT NULL,
+    valid_to    TIMESTAMPTZ NOT NULL,
+    coarsen_m   INT NOT NULL DEFAULT 0,
+    created_by  TEXT NOT NULL DEFAULT '',
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (valid_to > valid_from)
+);
+
+CREATE INDEX idx_share_links_vehicle ON share_links (tenant_id, vehicle_id);
@@ -0,0 +1,3 @@
+-- sealed hmac keys can't be recovered in SQL; those credentials must be reissued
+ALTER TABLE device_credentials
+    DROP COLUMN IF EXISTS sealed_key;
@@ -0,0 +1,4 @@
+-- hmac signing keys are stored encrypted with DEVICE_KEY_SECRET; the server
+-- moves keys still in secret_hash over on start
+ALTER TABLE device_credentials
+    ADD COLUMN sealed_key TEXT NOT NULL DEFAULT '';